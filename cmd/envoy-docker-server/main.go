@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/Nitro/envoy-docker-shim/internal/dockerwatch"
 	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/Nitro/envoy-docker-shim/internal/envoyxds"
 	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
 	"github.com/gorilla/mux"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -21,6 +27,24 @@ import (
 type Config struct {
 	GrpcAddr string `envconfig:"LISTEN_ADDR" default:"unix:///tmp/docker-envoy.sock"`
 	ApiAddr  string `envconfig:"API_ADDR" default:":7776"`
+	XdsAddr  string `envconfig:"XDS_ADDR" default:":7777"`
+	StateDir string `envconfig:"STATE_DIR" default:"/var/lib/envoy-docker-shim"`
+	CertDir  string `envconfig:"CERT_DIR"`
+
+	IngressAddr string `envconfig:"INGRESS_ADDR"`
+	SNIAddr     string `envconfig:"SNI_ADDR"`
+
+	DockerUrl         string        `envconfig:"DOCKER_URL"`
+	ReconcileInterval time.Duration `envconfig:"RECONCILE_INTERVAL" default:"1m"`
+
+	LeaseTTL   time.Duration `envconfig:"LEASE_TTL" default:"15s"`
+	LeaseGrace time.Duration `envconfig:"LEASE_GRACE" default:"30s"`
+	DrainTime  time.Duration `envconfig:"DRAIN_TIME" default:"10s"`
+
+	ReadinessTimeout       time.Duration `envconfig:"READINESS_TIMEOUT" default:"30s"`
+	ReadinessPublishAnyway bool          `envconfig:"READINESS_PUBLISH_ANYWAY" default:"true"`
+
+	EnvoyAdminUrl string `envconfig:"ENVOY_ADMIN_URL"`
 }
 
 func handleStopSignals(addr string) {
@@ -43,6 +67,7 @@ func serveHttp(envoyApi *envoyhttp.EnvoyApi, addr string) {
 	router := mux.NewRouter()
 
 	router.PathPrefix("/v1").Handler(http.StripPrefix("/v1", envoyApi.HttpMux()))
+	router.Handle("/metrics", promhttp.Handler())
 
 	http.Handle("/", router)
 
@@ -69,6 +94,89 @@ func serveGRPC(registrar *envoyhttp.Registrar, addr string) {
 	}
 }
 
+func serveXds(registrar *envoyhttp.Registrar, certs *envoyxds.CertDir, addr string) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+
+	xds := envoyxds.NewXdsServer(registrar)
+	xds.Certs = certs
+	go xds.Run(context.Background())
+
+	s := grpc.NewServer()
+	xds.Register(s)
+
+	reflection.Register(s)
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("Can't start Envoy xDS gRPC server: %s", err)
+	}
+}
+
+// newRegistrar returns a Registrar that persists its state in stateDir and
+// has restored anything previously stored there. If stateDir is empty, the
+// state is only kept in memory.
+func newRegistrar(stateDir string) *envoyhttp.Registrar {
+	if len(stateDir) < 1 {
+		log.Warn("No state dir configured, state will be lost on restart!")
+		return envoyhttp.NewRegistrar()
+	}
+
+	store, err := envoyhttp.NewJournalStore(stateDir)
+	if err != nil {
+		log.Fatalf("Unable to open state store: %s", err)
+	}
+
+	registrar, err := envoyhttp.NewPersistentRegistrar(store)
+	if err != nil {
+		log.Fatalf("Unable to restore state: %s", err)
+	}
+
+	return registrar
+}
+
+// sharedAddr parses the address for one of the shared listeners, which
+// listens on every IPv4 address if no host is given. Returns nil if there
+// isn't one.
+func sharedAddr(addr string) *net.TCPAddr {
+	if len(addr) < 1 {
+		return nil
+	}
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		log.Fatalf("Invalid listener address '%s': %s", addr, err)
+	}
+
+	if tcpAddr.IP == nil {
+		tcpAddr.IP = net.IPv4zero
+	}
+
+	return tcpAddr
+}
+
+// newCertDir returns a CertDir that keeps up with the certificates in
+// certDir, or nil if no cert dir is configured.
+func newCertDir(certDir string) *envoyxds.CertDir {
+	if len(certDir) < 1 {
+		return nil
+	}
+
+	certs, err := envoyxds.NewCertDir(certDir)
+	if err != nil {
+		log.Fatalf("Unable to load certificates: %s", err)
+	}
+
+	go func() {
+		err := certs.Watch(context.Background())
+		if err != nil {
+			log.Errorf("Unable to watch for new certificates: %s", err)
+		}
+	}()
+
+	return certs
+}
+
 func main() {
 	log.Info("docker-envoy-shim server starting up...")
 
@@ -82,9 +190,49 @@ func main() {
 
 	go handleStopSignals(config.GrpcAddr)
 
-	registrar := envoyhttp.NewRegistrar()
+	registrar := newRegistrar(config.StateDir)
+	registrar.LeaseTTL = config.LeaseTTL
+	registrar.LeaseGrace = config.LeaseGrace
+	registrar.DrainTime = config.DrainTime
+	registrar.ReadinessTimeout = config.ReadinessTimeout
+	registrar.ReadinessPublishAnyway = config.ReadinessPublishAnyway
+	registrar.IngressAddr = sharedAddr(config.IngressAddr)
+	registrar.SNIAddr = sharedAddr(config.SNIAddr)
+	if envoyhttp.Overlaps(registrar.IngressAddr, registrar.SNIAddr) {
+		log.Fatalf("The ingress and TLS passthrough can't both listen on %s", registrar.SNIAddr)
+	}
+	for _, err := range registrar.SharedConflicts() {
+		log.Errorf("Restored entry is in the way of a shared listener, %s", err)
+	}
+	go registrar.RunLeaseExpiry(context.Background(), 1*time.Second)
+	prometheus.MustRegister(registrar.UDPStats)
+
+	// Make sure we match what's running before we tell Envoy anything
+	dockerClient, err := dockerwatch.NewDockerClient(config.DockerUrl)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reconciler := dockerwatch.NewReconciler(registrar, dockerClient)
+	reconciler.Interval = config.ReconcileInterval
+	err = reconciler.Reconcile()
+	if err != nil {
+		log.Errorf("Unable to reconcile with Docker on startup: %s", err)
+	}
+	go reconciler.Run(context.Background())
+
+	watcher := dockerwatch.NewEventWatcher(registrar, dockerClient)
+	go func() {
+		err := watcher.Run(context.Background())
+		if err != nil {
+			log.Errorf("Unable to watch Docker events: %s", err)
+		}
+	}()
 	api := envoyhttp.NewEnvoyApi(registrar)
+	if len(config.EnvoyAdminUrl) > 0 {
+		api.Admin = envoyhttp.NewEnvoyAdmin(config.EnvoyAdminUrl)
+	}
 	go serveHttp(api, config.ApiAddr)
+	go serveXds(registrar, newCertDir(config.CertDir), config.XdsAddr)
 
 	serveGRPC(registrar, config.GrpcAddr)
 }
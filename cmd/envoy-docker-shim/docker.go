@@ -2,25 +2,38 @@ package main
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
 const (
 	DockerTimeout = 200 * time.Millisecond
+	// How often we look again for the container if no events arrive
+	LookupPollInterval = 500 * time.Millisecond
 )
 
 type DockerSettings struct {
 	ServiceName     string
 	EnvironmentName string
 	ProxyMode       string
+	ContainerID     string
+	ContainerName   string
+	Image           string
+	Labels          map[string]string
+	ServicePort     int               // Shared with the other replicas, 0 is none
+	UDPLimits       *UDPLimits        // For proxying UDP in-process, nil keeps the defaults
+	Tuning          map[string]string // The Envoy* labels
 }
 
 type DiscoveryClient interface {
-	ContainerFieldsForPort(port int) (*DockerSettings, error)
+	ContainerFieldsFor(host, backend net.Addr) (*DockerSettings, error)
+	WaitForContainerFields(ctx context.Context, host, backend net.Addr) (*DockerSettings, error)
 }
 
 type DockerClient struct{}
@@ -40,10 +53,10 @@ func (d *DockerClient) getClient(socketUrl string) (client *docker.Client, err e
 	return client, nil
 }
 
-// ContainerForPort connects to Docker, lists all the containers
-// and find the one with the port that matches the one we're
-// looking for.
-func (d *DockerClient) ContainerForPort(socketUrl string, port int) (*docker.APIContainers, error) {
+// ContainerForAddrs connects to Docker, lists all the containers and
+// finds the one that owns the backend address we were given and publishes
+// it on the host address.
+func (d *DockerClient) ContainerForAddrs(socketUrl string, host, backend net.Addr) (*docker.APIContainers, error) {
 	var err error
 
 	client, err := d.getClient(socketUrl)
@@ -64,32 +77,130 @@ func (d *DockerClient) ContainerForPort(socketUrl string, port int) (*docker.API
 		return nil, fmt.Errorf("Can't list containers: %s", err)
 	}
 
-	port64 := int64(port)
+	return FindContainer(containers, host, backend)
+}
+
+// FindContainer picks the container that has the backend IP on one of its
+// networks and publishes the backend port on the host IP and port. A stopped
+// container that still claims the host port won't have the backend IP, so
+// it doesn't match. More than one match is an error because we can't know
+// which labels to use.
+func FindContainer(containers []docker.APIContainers, host, backend net.Addr) (*docker.APIContainers, error) {
+	var found []*docker.APIContainers
 
-	var foundContainer *docker.APIContainers
-OUTER:
-	for _, container := range containers {
-		for _, p := range container.Ports {
-			if p.PublicPort == port64 {
-				foundContainer = &container
-				break OUTER
-			}
+	_, backendIP, _ := splitAddr(backend)
+
+	for i := range containers {
+		container := &containers[i]
+		if hasIP(container, backendIP) && publishes(container, host, backend) {
+			found = append(found, container)
 		}
 	}
 
-	if foundContainer != nil {
-		return foundContainer, nil
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("No container found with %s/%s published on %s",
+			backend.Network(), backend, host,
+		)
+	case 1:
+		return found[0], nil
+	}
+
+	var ids []string
+	for _, container := range found {
+		ids = append(ids, container.ID)
+	}
+
+	return nil, fmt.Errorf("Ambiguous match for %s/%s published on %s, found containers: %s",
+		backend.Network(), backend, host, strings.Join(ids, ", "),
+	)
+}
+
+// splitAddr returns the protocol, IP, and port of a TCP or UDP address
+func splitAddr(addr net.Addr) (string, net.IP, int) {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return "udp", a.IP, a.Port
+	case *net.TCPAddr:
+		return "tcp", a.IP, a.Port
+	}
+
+	return addr.Network(), nil, 0
+}
+
+// hasIP tells us if the container has the IP, in either family, on any of
+// its networks
+func hasIP(container *docker.APIContainers, ip net.IP) bool {
+	for _, network := range container.Networks.Networks {
+		if ip.Equal(net.ParseIP(network.IPAddress)) || ip.Equal(net.ParseIP(network.GlobalIPv6Address)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publishes tells us if the container maps the backend port to the host
+// port on the host IP, for the right protocol. An unspecified host IP only
+// matches an unspecified IP in Docker.
+func publishes(container *docker.APIContainers, host, backend net.Addr) bool {
+	proto, hostIP, hostPort := splitAddr(host)
+	_, _, backendPort := splitAddr(backend)
+
+	for _, p := range container.Ports {
+		if p.Type != proto || p.PrivatePort != int64(backendPort) || p.PublicPort != int64(hostPort) {
+			continue
+		}
+
+		if sameHostIP(hostIP, net.ParseIP(p.IP)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameHostIP(a, b net.IP) bool {
+	if a == nil || a.IsUnspecified() {
+		return b == nil || b.IsUnspecified()
 	}
 
-	return nil, fmt.Errorf("Unable to find container with port %d", port)
+	return a.Equal(b)
 }
 
-// ContainerFieldsForPort returns a selection of metadata lifted from
-// Docker labels if present.
-func (d *DockerClient) ContainerFieldsForPort(port int) (*DockerSettings, error) {
-	container, err := d.ContainerForPort("", port)
+// containerName returns the name Docker shows for the container, without
+// the leading slash.
+func containerName(container *docker.APIContainers) string {
+	if len(container.Names) < 1 {
+		return ""
+	}
+
+	return strings.TrimPrefix(container.Names[0], "/")
+}
+
+// servicePort returns the port from the container's ServicePort label, or
+// 0 if there isn't a valid one.
+func servicePort(container *docker.APIContainers) int {
+	value, ok := container.Labels[ServicePortLabel]
+	if !ok {
+		return 0
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		log.Warnf("Ignoring invalid %s label '%s' on container %s", ServicePortLabel, value, container.ID)
+		return 0
+	}
+
+	return port
+}
+
+// ContainerFieldsFor returns a selection of metadata lifted from Docker
+// labels if present.
+func (d *DockerClient) ContainerFieldsFor(host, backend net.Addr) (*DockerSettings, error) {
+	container, err := d.ContainerForAddrs("", host, backend)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to find container for %d! (%s)", port, err)
+		return nil, fmt.Errorf("Unable to find container for %s! (%s)", backend, err)
 	}
 
 	proxyMode := container.Labels[ProxyModeLabel]
@@ -97,9 +208,62 @@ func (d *DockerClient) ContainerFieldsForPort(port int) (*DockerSettings, error)
 		proxyMode = "http"
 	}
 
+	limits := UDPLimitsFromEnv().WithLabels(container.Labels)
+
+	tuning := make(map[string]string)
+	for name, value := range container.Labels {
+		if strings.HasPrefix(name, TuningLabelPrefix) {
+			tuning[name] = value
+		}
+	}
+
 	return &DockerSettings{
 		EnvironmentName: container.Labels[EnvironmentNameLabel],
 		ServiceName:     container.Labels[ServiceNameLabel],
 		ProxyMode:       strings.ToLower(proxyMode),
+		ContainerID:     container.ID,
+		ContainerName:   containerName(container),
+		Image:           container.Image,
+		Labels:          container.Labels,
+		ServicePort:     servicePort(container),
+		UDPLimits:       &limits,
+		Tuning:          tuning,
 	}, nil
 }
+
+// WaitForContainerFields looks up the fields for the container with the
+// addresses passed in, looking again each time Docker reports a container event until
+// it is found or the context is done. It always looks at least once.
+func (d *DockerClient) WaitForContainerFields(ctx context.Context, host, backend net.Addr) (*DockerSettings, error) {
+	// Subscribe before the first lookup so we can't miss the container
+	// showing up in between.
+	events := make(chan *docker.APIEvents, 10)
+	client, err := d.getClient("")
+	if err == nil {
+		err = client.AddEventListener(events)
+		if err == nil {
+			defer client.RemoveEventListener(events)
+		}
+	}
+	if err != nil {
+		log.Warnf("Unable to watch Docker events, polling instead: %s", err)
+	}
+
+	ticker := time.NewTicker(LookupPollInterval)
+	defer ticker.Stop()
+
+	for {
+		settings, err := d.ContainerFieldsFor(host, backend)
+		if err == nil {
+			return settings, nil
+		}
+
+		select {
+		case event := <-events:
+			log.Debugf("Docker event %s %s, looking again", event.Type, event.Action)
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
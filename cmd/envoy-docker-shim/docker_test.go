@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/fsouza/go-dockerclient"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func containerWith(id, ip string, ports ...docker.APIPort) docker.APIContainers {
+	return docker.APIContainers{
+		ID:    id,
+		Ports: ports,
+		Networks: docker.NetworkList{
+			Networks: map[string]docker.ContainerNetwork{
+				"bridge": {IPAddress: ip},
+			},
+		},
+	}
+}
+
+func Test_FindContainer(t *testing.T) {
+	Convey("FindContainer()", t, func() {
+		host := &net.TCPAddr{IP: net.ParseIP("0.0.0.0"), Port: 8080}
+		backend := &net.TCPAddr{IP: net.ParseIP("172.17.0.2"), Port: 80}
+
+		published := docker.APIPort{PrivatePort: 80, PublicPort: 8080, Type: "tcp", IP: "0.0.0.0"}
+
+		Convey("finds the container with the backend IP and port", func() {
+			containers := []docker.APIContainers{
+				containerWith("deadbeef", "172.17.0.3", published),
+				containerWith("abba", "172.17.0.2", published),
+			}
+
+			container, err := FindContainer(containers, host, backend)
+			So(err, ShouldBeNil)
+			So(container.ID, ShouldEqual, "abba")
+		})
+
+		Convey("ignores a stopped container still claiming the port", func() {
+			containers := []docker.APIContainers{
+				containerWith("deadbeef", "", published),
+				containerWith("abba", "172.17.0.2", published),
+			}
+
+			container, err := FindContainer(containers, host, backend)
+			So(err, ShouldBeNil)
+			So(container.ID, ShouldEqual, "abba")
+		})
+
+		Convey("matches on the host IP", func() {
+			onOther := docker.APIPort{PrivatePort: 80, PublicPort: 8080, Type: "tcp", IP: "10.0.0.1"}
+			onHost := docker.APIPort{PrivatePort: 80, PublicPort: 8080, Type: "tcp", IP: "10.0.0.2"}
+			containers := []docker.APIContainers{
+				containerWith("deadbeef", "172.17.0.2", onOther),
+				containerWith("abba", "172.17.0.2", onHost),
+			}
+
+			container, err := FindContainer(containers,
+				&net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 8080}, backend,
+			)
+			So(err, ShouldBeNil)
+			So(container.ID, ShouldEqual, "abba")
+
+			_, err = FindContainer(containers, host, backend)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("finds the container on IPv6", func() {
+			container := containerWith("abba", "172.17.0.2",
+				docker.APIPort{PrivatePort: 80, PublicPort: 8080, Type: "tcp", IP: "::"},
+			)
+			bridge := container.Networks.Networks["bridge"]
+			bridge.GlobalIPv6Address = "fd00::2"
+			container.Networks.Networks["bridge"] = bridge
+
+			found, err := FindContainer([]docker.APIContainers{container},
+				&net.TCPAddr{IP: net.ParseIP("::"), Port: 8080},
+				&net.TCPAddr{IP: net.ParseIP("fd00::2"), Port: 80},
+			)
+			So(err, ShouldBeNil)
+			So(found.ID, ShouldEqual, "abba")
+		})
+
+		Convey("ignores UDP ports", func() {
+			udp := published
+			udp.Type = "udp"
+			containers := []docker.APIContainers{containerWith("abba", "172.17.0.2", udp)}
+
+			_, err := FindContainer(containers, host, backend)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "No container found")
+		})
+
+		Convey("returns an error when the match is ambiguous", func() {
+			containers := []docker.APIContainers{
+				containerWith("deadbeef", "172.17.0.2", published),
+				containerWith("abba", "172.17.0.2", published),
+			}
+
+			_, err := FindContainer(containers, host, backend)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Ambiguous")
+			So(err.Error(), ShouldContainSubstring, "deadbeef, abba")
+		})
+	})
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -16,33 +17,56 @@ const (
 	ServiceNameLabel     = "ServiceName"
 	EnvironmentNameLabel = "EnvironmentName"
 	ProxyModeLabel       = "ProxyMode"
+
+	// Replicas that set this share a listener on the port, and a cluster
+	ServicePortLabel = "ServicePort"
+
+	// The proxy mode that asks for UDP to be proxied by Envoy
+	UDPEnvoyProxyMode = "udp-envoy"
+
+	// Labels starting with this tune Envoy for the service. We pass them all
+	// on and leave it to the server to validate them.
+	TuningLabelPrefix = "Envoy"
 )
 
 // An EnvoyProxy is a proxy instance that using a shim service to configure
 // and maintain an instance of Lyft's Envoy proxy on the host in place
 // of a normal docker-proxy instance.
 type EnvoyProxy struct {
-	ServerAddr   string
-	frontendAddr *net.TCPAddr
-	backendAddr  *net.TCPAddr
-	Discoverer   DiscoveryClient
-	Reload       bool // Are we waiting around or just reloading the settings?
-	Retries      []int
-	GRPCTimeout  time.Duration
+	ServerAddr    string
+	frontendAddr  net.Addr // TCP, or UDP when proxying UDP through Envoy
+	backendAddr   net.Addr
+	Discoverer    DiscoveryClient
+	Reload        bool // Are we waiting around or just reloading the settings?
+	Retries       []int
+	GRPCTimeout   time.Duration
+	LeaseRetry    time.Duration // How long to wait before re-establishing a lease
+	LookupTimeout time.Duration // How long to wait for Docker to show us the container
+
+	settingsLock sync.Mutex
+	settings     *DockerSettings // Cached so we can re-lease without Docker
+	ctx          context.Context
+	cancel       context.CancelFunc
+	done         chan struct{} // Closed once Close has deregistered
+	doneOnce     sync.Once
 }
 
 // NewEnvoyProxy returns a correctly configured EnvoyProxy.
 func NewEnvoyProxy(frontendAddr, backendAddr net.Addr, svrAddr string) (*EnvoyProxy, error) {
-	front := frontendAddr.(*net.TCPAddr)
-	back := backendAddr.(*net.TCPAddr)
+	ctx, cancel := context.WithCancel(context.Background())
 
 	return &EnvoyProxy{
-		frontendAddr: front,
-		backendAddr:  back,
-		ServerAddr:   svrAddr,
-		Discoverer:   &DockerClient{},
-		Retries:      []int{100, 500, 1000, 1500},
-		GRPCTimeout:  3 * time.Second,
+		frontendAddr:  frontendAddr,
+		backendAddr:   backendAddr,
+		ServerAddr:    svrAddr,
+		Discoverer:    &DockerClient{},
+		Retries:       []int{100, 500, 1000, 1500},
+		GRPCTimeout:   3 * time.Second,
+		LeaseRetry:    1 * time.Second,
+		LookupTimeout: 30 * time.Second,
+		ctx:           ctx,
+		cancel:        cancel,
+		done:          make(chan struct{}),
 	}, nil
 }
 
@@ -74,12 +98,10 @@ func (p *EnvoyProxy) WithClient(fn func(c shimrpc.RegistrarClient) error) error
 // the requested action. It then calls the GRPC server using the client
 // returned from WithClient.
 func (p *EnvoyProxy) DoAction(action shimrpc.RegistrarRequest_Action) error {
-	settings, err := p.Discoverer.ContainerFieldsForPort(p.frontendAddr.Port)
+	req, err := p.request(action)
 	if err != nil {
 		return err
 	}
-	req := p.RequestWithSettings(settings)
-	req.Action = action
 
 	return p.WithClient(func(c shimrpc.RegistrarClient) error {
 		resp, err := c.Register(context.Background(), req)
@@ -90,6 +112,135 @@ func (p *EnvoyProxy) DoAction(action shimrpc.RegistrarRequest_Action) error {
 	})
 }
 
+// request looks up our settings in Docker, the first time it is called, and
+// returns a request for the action passed in. Docker may not show us the
+// container right away, so we wait up to LookupTimeout for it. Once the
+// proxy is closed we only look once.
+func (p *EnvoyProxy) request(action shimrpc.RegistrarRequest_Action) (*shimrpc.RegistrarRequest, error) {
+	settings := p.cachedSettings()
+	if settings == nil {
+		ctx, cancel := context.WithTimeout(p.ctx, p.LookupTimeout)
+		defer cancel()
+
+		var err error
+		settings, err = p.Discoverer.WaitForContainerFields(ctx, p.frontendAddr, p.backendAddr)
+		if err != nil {
+			return nil, err
+		}
+		log.Debugf("Found container %s (%s) running %s", settings.ContainerName, settings.ContainerID, settings.Image)
+
+		p.settingsLock.Lock()
+		p.settings = settings
+		p.settingsLock.Unlock()
+	}
+
+	req := p.RequestWithSettings(settings)
+	req.Action = action
+
+	return req, nil
+}
+
+// cachedSettings returns the settings we found in Docker, or nil if we
+// haven't found them yet. They are looked up on the lease goroutine, so
+// everyone else has to come through here.
+func (p *EnvoyProxy) cachedSettings() *DockerSettings {
+	p.settingsLock.Lock()
+	defer p.settingsLock.Unlock()
+
+	return p.settings
+}
+
+// holdLease keeps a lease on our entry with the server until the proxy is
+// closed, re-establishing it each time the stream breaks. This is how we
+// get re-registered when the server restarts.
+func (p *EnvoyProxy) holdLease() {
+	for {
+		err := p.lease()
+
+		select {
+		case <-p.ctx.Done():
+			return
+		default:
+		}
+
+		if p.cachedSettings() == nil {
+			log.Errorf("No container found for %s after %s, retrying: %s",
+				p.backendAddr, p.LookupTimeout, err,
+			)
+		} else {
+			log.Warnf("Lost lease, retrying in %s: %s", p.LeaseRetry, err)
+		}
+
+		select {
+		case <-time.After(p.LeaseRetry):
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// lease registers over a lease stream and then sends heartbeats to renew
+// the lease until the stream breaks or the proxy is closed.
+func (p *EnvoyProxy) lease() error {
+	req, err := p.request(shimrpc.RegistrarRequest_REGISTER)
+	if err != nil {
+		return err
+	}
+
+	return p.WithClient(func(c shimrpc.RegistrarClient) error {
+		stream, err := c.Lease(p.ctx)
+		if err != nil {
+			return err
+		}
+
+		err = stream.Send(req)
+		if err != nil {
+			return err
+		}
+
+		// The first reply tells us how often we need to renew
+		reply, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		log.Debugf("Holding lease with TTL of %ds", reply.TtlSeconds)
+
+		interval := time.Duration(reply.TtlSeconds) * time.Second / 3
+		if interval <= 0 {
+			interval = 1 * time.Second
+		}
+		heartbeat := time.NewTicker(interval)
+		defer heartbeat.Stop()
+
+		// Watch for the stream breaking while we wait to send
+		broken := make(chan error, 1)
+		go func() {
+			for {
+				_, err := stream.Recv()
+				if err != nil {
+					broken <- err
+					return
+				}
+			}
+		}()
+
+		for {
+			select {
+			case <-heartbeat.C:
+				err := stream.Send(req)
+				if err != nil {
+					return err
+				}
+			case err := <-broken:
+				return err
+			case <-p.ctx.Done():
+				stream.CloseSend()
+				return nil
+			}
+		}
+	})
+}
+
 // withRetries is a decorator to retry with fixed durations
 func (p *EnvoyProxy) withRetries(fn func() error) error {
 	var err error
@@ -105,15 +256,18 @@ func (p *EnvoyProxy) withRetries(fn func() error) error {
 	return err
 }
 
-// Run makes a call to the state server to register this endpoint.
+// Run makes a call to the state server to register this endpoint. Unless
+// we are reloading, it then holds a lease on the registration until Close
+// is called, and returns once Close has deregistered it. Otherwise we'd
+// exit first, and the server would only see the lease stream break.
 func (p *EnvoyProxy) Run() {
-		log.SetLevel(log.DebugLevel)
+	log.SetLevel(log.DebugLevel)
 	log.Debugf("Starting up:\nFrontend: %s\nBackend: %s", p.frontendAddr, p.backendAddr)
 
-	// Have to give Docker a quick breather to see the container.
-	// XXX maybe watch events or poll the API instead?
 	if !p.Reload {
-		time.Sleep(1 * time.Second)
+		p.holdLease()
+		<-p.done
+		return
 	}
 
 	err := p.withRetries(func() error {
@@ -124,24 +278,27 @@ func (p *EnvoyProxy) Run() {
 		return err2
 	})
 
-	if p.Reload {
-		if err != nil {
-			// We have to panic here because we currently can't return an
-			// error from this function. It is assumed to be the main function
-			// for this particular proxy.
-			panic("Could not call Envoy: " + err.Error())
-		}
-	} else {
-		if err != nil {
-			log.Errorf("Could not call Envoy: %s", err)
-		}
-		select {}
+	if err != nil {
+		// We have to panic here because we currently can't return an
+		// error from this function. It is assumed to be the main function
+		// for this particular proxy.
+		panic("Could not call Envoy: " + err.Error())
 	}
 }
 
 // Close makes a call to the state server to shut down this endpoint.
 func (p *EnvoyProxy) Close() {
 	log.Debug("Shutting down!")
+	defer p.doneOnce.Do(func() { close(p.done) })
+
+	// Stop renewing the lease, we're about to deregister anyway
+	p.cancel()
+
+	// We never found the container, so we never registered it
+	if p.cachedSettings() == nil {
+		log.Warn("Never registered with the server, not deregistering")
+		return
+	}
 
 	err := p.withRetries(func() error {
 		return p.DoAction(shimrpc.RegistrarRequest_DEREGISTER)
@@ -155,14 +312,24 @@ func (p *EnvoyProxy) Close() {
 // RequestWithSettings returns a properly formatted shimrpc Request
 // using the DockerSettings passed in.
 func (p *EnvoyProxy) RequestWithSettings(settings *DockerSettings) *shimrpc.RegistrarRequest {
+	proto, frontendIP, frontendPort := splitAddr(p.frontendAddr)
+	_, backendIP, backendPort := splitAddr(p.backendAddr)
+
 	return &shimrpc.RegistrarRequest{
-		FrontendAddr:    p.frontendAddr.IP.String(),
-		FrontendPort:    int32(p.frontendAddr.Port),
-		BackendAddr:     p.backendAddr.IP.String(),
-		BackendPort:     int32(p.backendAddr.Port),
+		FrontendAddr:    frontendIP.String(),
+		FrontendPort:    int32(frontendPort),
+		BackendAddr:     backendIP.String(),
+		BackendPort:     int32(backendPort),
 		ServiceName:     settings.ServiceName,
 		EnvironmentName: settings.EnvironmentName,
 		ProxyMode:       settings.ProxyMode,
+		Protocol:        proto,
+		Tuning:          settings.Tuning,
+		ContainerId:     settings.ContainerID,
+		ContainerName:   settings.ContainerName,
+		Image:           settings.Image,
+		Labels:          settings.Labels,
+		ServicePort:     int32(settings.ServicePort),
 	}
 }
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net"
@@ -30,18 +31,28 @@ var (
 
 type mockDiscoveryClient struct{}
 
-func (c *mockDiscoveryClient) ContainerFieldsForPort(port int) (*DockerSettings, error) {
-	if port == 80 {
+func (c *mockDiscoveryClient) ContainerFieldsFor(host, backend net.Addr) (*DockerSettings, error) {
+	if _, _, port := splitAddr(host); port == 80 {
 		return &DockerSettings{
 			ServiceName:     "kjartan",
 			EnvironmentName: "dev",
 			ProxyMode:       "http",
+			ContainerID:     "deadbeef0001",
+			ContainerName:   "kjartan-1",
+			Image:           "nginx:1.19",
+			Labels:          map[string]string{"ServiceName": "kjartan"},
+			ServicePort:     8080,
+			Tuning:          map[string]string{"EnvoyRouteTimeout": "5s"},
 		}, nil
 	}
 
 	return nil, errors.New("intentional mock Error!")
 }
 
+func (c *mockDiscoveryClient) WaitForContainerFields(ctx context.Context, host, backend net.Addr) (*DockerSettings, error) {
+	return c.ContainerFieldsFor(host, backend)
+}
+
 func Test_NewEnvoyProxy(t *testing.T) {
 	Convey("NewEnvoyProxy()", t, func() {
 		Convey("properly configures an EnvoyProxy", func() {
@@ -128,6 +139,117 @@ func Test_Run(t *testing.T) {
 	})
 }
 
+// hiddenDiscoveryClient never finds the container
+type hiddenDiscoveryClient struct {
+	mockDiscoveryClient
+}
+
+func (c *hiddenDiscoveryClient) WaitForContainerFields(ctx context.Context, host, backend net.Addr) (*DockerSettings, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func Test_request(t *testing.T) {
+	Convey("request()", t, func() {
+		proxy, _ := NewEnvoyProxy(&fAddr, &bAddr, "/var/run/docker-envoy.sock")
+		proxy.Discoverer = &mockDiscoveryClient{}
+
+		Convey("builds a request from the container's settings", func() {
+			req, err := proxy.request(shimrpc.RegistrarRequest_REGISTER)
+
+			So(err, ShouldBeNil)
+			So(req.ServiceName, ShouldEqual, "kjartan")
+			So(req.Tuning, ShouldResemble, map[string]string{"EnvoyRouteTimeout": "5s"})
+			So(req.ContainerId, ShouldEqual, "deadbeef0001")
+			So(req.ServicePort, ShouldEqual, 8080)
+			So(req.ContainerName, ShouldEqual, "kjartan-1")
+			So(req.Image, ShouldEqual, "nginx:1.19")
+			So(req.Labels, ShouldResemble, map[string]string{"ServiceName": "kjartan"})
+			So(req.Action, ShouldEqual, shimrpc.RegistrarRequest_REGISTER)
+			So(proxy.settings, ShouldNotBeNil)
+		})
+
+		Convey("gives up when the container doesn't show up in time", func() {
+			proxy.Discoverer = &hiddenDiscoveryClient{}
+			proxy.LookupTimeout = 10 * time.Millisecond
+
+			start := time.Now()
+			_, err := proxy.request(shimrpc.RegistrarRequest_REGISTER)
+
+			So(err == context.DeadlineExceeded, ShouldBeTrue)
+			So(time.Since(start), ShouldBeLessThan, 1*time.Second)
+		})
+
+		Convey("doesn't wait once the proxy is closed", func() {
+			proxy.Discoverer = &hiddenDiscoveryClient{}
+			proxy.cancel()
+
+			_, err := proxy.request(shimrpc.RegistrarRequest_DEREGISTER)
+			So(err, ShouldEqual, context.Canceled)
+		})
+	})
+}
+
+func Test_holdLease(t *testing.T) {
+	Convey("holdLease()", t, func() {
+		socketPath := filepath.Join(os.TempDir(), "docker-envoy-lease.sock")
+		proxy, _ := NewEnvoyProxy(&fAddr, &bAddr, socketPath)
+		proxy.Discoverer = &mockDiscoveryClient{}
+		proxy.GRPCTimeout = 20 * time.Millisecond
+		proxy.LeaseRetry = 10 * time.Millisecond
+		registrar := envoyhttp.NewRegistrar()
+
+		s := serveGRPC(registrar, socketPath)
+
+		done := make(chan struct{})
+		go func() {
+			proxy.holdLease()
+			close(done)
+		}()
+
+		Reset(func() {
+			proxy.cancel()
+			s.Stop()
+			os.Remove(socketPath)
+		})
+
+		Convey("registers with the Registrar", func() {
+			So(waitForEntry(registrar, "kjartan-dev-80"), ShouldBeTrue)
+		})
+
+		Convey("re-registers when the server restarts", func() {
+			So(waitForEntry(registrar, "kjartan-dev-80"), ShouldBeTrue)
+
+			s.Stop()
+			os.Remove(socketPath)
+			registrar = envoyhttp.NewRegistrar()
+			s = serveGRPC(registrar, socketPath)
+
+			So(waitForEntry(registrar, "kjartan-dev-80"), ShouldBeTrue)
+		})
+
+		Convey("returns when the proxy is closed", func() {
+			proxy.cancel()
+
+			select {
+			case <-done:
+			case <-time.After(1 * time.Second):
+				So("holdLease() to return", ShouldBeEmpty)
+			}
+		})
+	})
+}
+
+func waitForEntry(registrar *envoyhttp.Registrar, name string) bool {
+	for i := 0; i < 100; i++ {
+		if registrar.GetEntry(name) != nil {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
 func Test_Close(t *testing.T) {
 	Convey("Close()", t, func() {
 		socketPath := filepath.Join(os.TempDir(), "docker-envoy.sock")
@@ -150,6 +272,32 @@ func Test_Close(t *testing.T) {
 		Convey("deregisters with the Registrar when things are working", func() {
 			So(proxy.Close, ShouldNotPanic)
 		})
+
+		Convey("deregisters before a leasing proxy's Run returns", func() {
+			registrar.RemoveEntry("kjartan-dev-80") // The first proxy's
+			registrar.DrainTime = 1 * time.Minute
+			leasing, _ := NewEnvoyProxy(&fAddr, &bAddr, socketPath)
+			leasing.Discoverer = &mockDiscoveryClient{}
+			leasing.Retries = []int{1, 1}
+
+			ran := make(chan struct{})
+			go func() {
+				leasing.Run()
+				close(ran)
+			}()
+			So(waitForEntry(registrar, "kjartan-dev-80"), ShouldBeTrue)
+
+			go leasing.Close()
+			<-ran
+			So(registrar.GetEntry("kjartan-dev-80").IsDraining(), ShouldBeTrue)
+		})
+
+		Convey("doesn't look for a container it never registered", func() {
+			proxy, _ := NewEnvoyProxy(&fAddr, &bAddr, socketPath)
+			proxy.Discoverer = &hiddenDiscoveryClient{}
+
+			So(proxy.Close, ShouldNotPanic)
+		})
 	})
 }
 
@@ -158,6 +306,9 @@ func serveGRPC(registrar *envoyhttp.Registrar, addr string) *grpc.Server {
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
+	// A stopped server may close its listener late, so don't let that remove
+	// the next test's socket. The tests clean up after themselves.
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
 
 	s := grpc.NewServer()
 	shimrpc.RegisterRegistrarServer(s, registrar)
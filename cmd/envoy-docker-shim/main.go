@@ -6,8 +6,10 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/ishidawataru/sctp"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -36,6 +38,9 @@ func parseCLI() (host net.Addr, container net.Addr, doReload bool) {
 	case "udp":
 		host = &net.UDPAddr{IP: net.ParseIP(*hostIP), Port: *hostPort}
 		container = &net.UDPAddr{IP: net.ParseIP(*containerIP), Port: *containerPort}
+	case "sctp":
+		host = &sctp.SCTPAddr{IPAddrs: parseSCTPAddrs(*hostIP), Port: *hostPort}
+		container = &sctp.SCTPAddr{IPAddrs: parseSCTPAddrs(*containerIP), Port: *containerPort}
 	default:
 		log.Fatalf("unsupported protocol %s", *proto)
 	}
@@ -43,6 +48,17 @@ func parseCLI() (host net.Addr, container net.Addr, doReload bool) {
 	return host, container, *reload
 }
 
+// parseSCTPAddrs splits up the comma-separated list of IPs that Docker passes
+// for SCTP multi-homing.
+func parseSCTPAddrs(ips string) []net.IPAddr {
+	var ipAddrs []net.IPAddr
+	for _, addrStr := range strings.Split(ips, ",") {
+		ipAddrs = append(ipAddrs, net.IPAddr{IP: net.ParseIP(addrStr)})
+	}
+
+	return ipAddrs
+}
+
 func handleStopSignals(p Proxy) {
 	s := make(chan os.Signal, 10)
 	signal.Notify(s, os.Interrupt, syscall.SIGTERM)
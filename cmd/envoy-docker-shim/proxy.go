@@ -6,6 +6,8 @@ package main
 
 import (
 	"net"
+
+	"github.com/ishidawataru/sctp"
 )
 
 // Proxy defines the behavior of a proxy. It forwards traffic back and forth
@@ -29,9 +31,11 @@ type Proxy interface {
 func NewProxy(frontendAddr, backendAddr net.Addr, envoySocketPath string) (Proxy, error) {
 	switch frontendAddr.(type) {
 	case *net.UDPAddr:
-		return NewUDPProxy(frontendAddr.(*net.UDPAddr), backendAddr.(*net.UDPAddr))
+		return NewUDPEnvoyProxy(frontendAddr.(*net.UDPAddr), backendAddr.(*net.UDPAddr), envoySocketPath)
 	case *net.TCPAddr:
 		return NewEnvoyProxy(frontendAddr.(*net.TCPAddr), backendAddr.(*net.TCPAddr), envoySocketPath)
+	case *sctp.SCTPAddr:
+		return NewSCTPProxy(frontendAddr.(*sctp.SCTPAddr), backendAddr.(*sctp.SCTPAddr))
 	default:
 		panic("Unsupported protocol")
 	}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/ishidawataru/sctp"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_NewProxy(t *testing.T) {
+	Convey("NewProxy()", t, func() {
+		Convey("returns an SCTPProxy for SCTP addresses", func() {
+			frontend := &sctp.SCTPAddr{IPAddrs: parseSCTPAddrs("127.0.0.1"), Port: 0}
+			backend := &sctp.SCTPAddr{IPAddrs: parseSCTPAddrs("127.0.0.1"), Port: 31337}
+
+			p, err := NewProxy(frontend, backend, "/var/run/docker-envoy.sock")
+			if err != nil {
+				// Not every kernel we test on has SCTP loaded
+				SkipSo(err, ShouldBeNil)
+				return
+			}
+			defer p.Close()
+
+			So(p, ShouldHaveSameTypeAs, &SCTPProxy{})
+			So(p.BackendAddr(), ShouldEqual, backend)
+			So(p.FrontendAddr().(*sctp.SCTPAddr).Port, ShouldNotEqual, 0)
+		})
+	})
+}
+
+func Test_parseSCTPAddrs(t *testing.T) {
+	Convey("parseSCTPAddrs()", t, func() {
+		Convey("handles multi-homed addresses", func() {
+			addrs := parseSCTPAddrs("10.0.0.1,10.0.0.2")
+
+			So(len(addrs), ShouldEqual, 2)
+			So(addrs[0].IP.Equal(net.ParseIP("10.0.0.1")), ShouldBeTrue)
+			So(addrs[1].IP.Equal(net.ParseIP("10.0.0.2")), ShouldBeTrue)
+		})
+	})
+}
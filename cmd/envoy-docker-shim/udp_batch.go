@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// UDPBatchSize is how many datagrams we read or write in one syscall
+	UDPBatchSize = 32
+	// UDPWorkers is how many goroutines forward datagrams to the backend
+	UDPWorkers = 4
+)
+
+// bufferPool holds datagram sized buffers, shared by all the flows so we
+// don't need a 64KB buffer per datagram in flight.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, UDPBufSize)
+		return &buf
+	},
+}
+
+// batchConn is the part of ipv4.PacketConn and ipv6.PacketConn that we use.
+// On Linux these are recvmmsg(2) and sendmmsg(2). Elsewhere they move a
+// single datagram.
+type batchConn interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// newBatchConn wraps a UDP socket for batching, based on its family.
+func newBatchConn(conn *net.UDPConn) batchConn {
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() != nil {
+		return ipv4.NewPacketConn(conn)
+	}
+
+	return ipv6.NewPacketConn(conn)
+}
+
+// A datagram read from a client, waiting to be forwarded
+type datagram struct {
+	buf  *[]byte // From the bufferPool
+	size int
+	from *net.UDPAddr
+}
+
+// writeBatch writes all the messages, as few syscalls as the connection
+// allows. A message that fails is skipped so one bad datagram doesn't hold
+// up the rest.
+func writeBatch(conn batchConn, ms []ipv4.Message, sent func(size int), failed func(err error)) {
+	for len(ms) > 0 {
+		n, err := conn.WriteBatch(ms, 0)
+		for i := 0; i < n; i++ {
+			sent(ms[i].N)
+		}
+		ms = ms[n:]
+
+		if err != nil {
+			failed(err)
+			ms = ms[1:]
+		}
+	}
+}
+
+// shard picks the worker for a client, so that each flow is always handled
+// by the same worker and its datagrams stay in order.
+func (k *connTrackKey) shard(workers int) int {
+	hash := (k.IPHigh*31+k.IPLow)*31 + uint64(k.Port)
+	return int(hash % uint64(workers))
+}
+
+// runBatched reads datagrams from clients a batch at a time and hands them
+// to a fixed set of workers to forward to the backend.
+func (proxy *UDPProxy) runBatched() {
+	var wg sync.WaitGroup
+
+	workers := make([]chan []datagram, proxy.Workers)
+	for i := range workers {
+		workers[i] = make(chan []datagram, 4)
+		wg.Add(1)
+		go func(work chan []datagram) {
+			proxy.forwardLoop(work)
+			wg.Done()
+		}(workers[i])
+	}
+
+	defer func() {
+		for _, work := range workers {
+			close(work)
+		}
+		wg.Wait()
+	}()
+
+	ms := make([]ipv4.Message, proxy.BatchSize)
+	bufs := make([]*[]byte, proxy.BatchSize)
+	for i := range ms {
+		bufs[i] = bufferPool.Get().(*[]byte)
+		ms[i].Buffers = [][]byte{*bufs[i]}
+	}
+
+	shards := make([][]datagram, proxy.Workers)
+	for {
+		n, err := proxy.batchListener.ReadBatch(ms, 0)
+		if err != nil {
+			if !isClosedError(err) {
+				log.Debugf("Stopping proxy on udp/%v for udp/%v (%s)", proxy.frontendAddr, proxy.backendAddr, err)
+			}
+			break
+		}
+
+		for i := 0; i < n; i++ {
+			from, ok := ms[i].Addr.(*net.UDPAddr)
+			if !ok {
+				continue
+			}
+
+			key := newConnTrackKey(from)
+			shard := key.shard(proxy.Workers)
+			shards[shard] = append(shards[shard], datagram{buf: bufs[i], size: ms[i].N, from: from})
+
+			// The worker owns that buffer now
+			bufs[i] = bufferPool.Get().(*[]byte)
+			ms[i].Buffers[0] = *bufs[i]
+		}
+
+		for i, batch := range shards {
+			if len(batch) > 0 {
+				workers[i] <- batch
+				shards[i] = nil
+			}
+		}
+	}
+
+	for _, buf := range bufs {
+		bufferPool.Put(buf)
+	}
+}
+
+// forwardLoop sends the datagrams it is handed to the backend, batching
+// up consecutive datagrams from the same client.
+func (proxy *UDPProxy) forwardLoop(work chan []datagram) {
+	ms := make([]ipv4.Message, 0, proxy.BatchSize)
+
+	for batch := range work {
+		for start := 0; start < len(batch); {
+			from := batch[start].from
+			end := start + 1
+			for end < len(batch) && batch[end].from.IP.Equal(from.IP) && batch[end].from.Port == from.Port {
+				end++
+			}
+
+			limits := proxy.Limits()
+			now := time.Now()
+
+			ms = ms[:0]
+			for _, d := range batch[start:end] {
+				if !proxy.rateLimiter.allow(from.IP, limits, now) {
+					proxy.stats.rateLimited()
+					continue
+				}
+				ms = append(ms, ipv4.Message{Buffers: [][]byte{(*d.buf)[:d.size]}})
+			}
+
+			// Rate limited clients don't get a flow
+			if len(ms) > 0 {
+				if flow := proxy.flowFor(from, true); flow != nil {
+					writeBatch(flow.batch, ms, proxy.stats.sentIn, func(err error) {
+						proxy.stats.writeFailed()
+						log.Debugf("Can't proxy a datagram to udp/%s: %s\n", proxy.backendAddr, err)
+					})
+				}
+			}
+
+			start = end
+		}
+
+		for _, d := range batch {
+			bufferPool.Put(d.buf)
+		}
+	}
+}
+
+// batchReplyLoop sends the backend's replies back to the client a batch at
+// a time, until the flow goes idle or is closed.
+func (proxy *UDPProxy) batchReplyLoop(flow *connTrackFlow) {
+	ms := make([]ipv4.Message, proxy.BatchSize)
+	bufs := make([]*[]byte, proxy.BatchSize)
+	for i := range ms {
+		bufs[i] = bufferPool.Get().(*[]byte)
+		ms[i].Buffers = [][]byte{*bufs[i]}
+	}
+
+	defer func() {
+		proxy.forget(flow)
+		flow.conn.Close()
+
+		for _, buf := range bufs {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	replies := make([]ipv4.Message, 0, proxy.BatchSize)
+	for {
+		flow.conn.SetReadDeadline(time.Now().Add(proxy.Limits().ConnTrackTimeout))
+		n, err := flow.batch.ReadBatch(ms, 0)
+		if err != nil {
+			// See replyLoop, this happens when the last write failed
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				continue
+			}
+			if err, ok := err.(net.Error); ok && err.Timeout() {
+				proxy.stats.expired()
+				log.Debugf("UDP flow from %s to udp/%s went idle", flow.client, proxy.backendAddr)
+			}
+			return
+		}
+
+		replies = replies[:0]
+		for i := 0; i < n; i++ {
+			replies = append(replies, ipv4.Message{
+				Buffers: [][]byte{(*bufs[i])[:ms[i].N]},
+				Addr:    flow.client,
+			})
+		}
+
+		failed := false
+		writeBatch(proxy.batchListener, replies, proxy.stats.sentOut, func(err error) {
+			proxy.stats.writeFailed()
+			failed = true
+		})
+		if failed {
+			return
+		}
+	}
+}
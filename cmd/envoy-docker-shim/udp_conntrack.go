@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SetLimits changes the limits for the proxy. Flows already tracked are
+// held to the new limits from their next datagram on.
+func (proxy *UDPProxy) SetLimits(limits UDPLimits) {
+	proxy.limits.Store(&limits)
+
+	proxy.connTrackLock.Lock()
+	defer proxy.connTrackLock.Unlock()
+
+	for limits.MaxFlows > 0 && proxy.lru.Len() > limits.MaxFlows {
+		proxy.evictOldest(limits.MaxFlows)
+	}
+}
+
+// Limits returns the limits the proxy is currently held to.
+func (proxy *UDPProxy) Limits() *UDPLimits {
+	return proxy.limits.Load().(*UDPLimits)
+}
+
+// flowFor returns the flow for a client, setting one up if this is the first
+// we've heard from it and evicting the least recently used flow if we're at
+// the limit. It returns nil if we can't reach the backend.
+func (proxy *UDPProxy) flowFor(from *net.UDPAddr, batched bool) *connTrackFlow {
+	key := newConnTrackKey(from)
+
+	proxy.connTrackLock.Lock()
+	defer proxy.connTrackLock.Unlock()
+
+	if flow, ok := proxy.connTrackTable[*key]; ok {
+		proxy.lru.MoveToFront(flow.elem)
+		return flow
+	}
+
+	maxFlows := proxy.Limits().MaxFlows
+	if maxFlows > 0 && proxy.lru.Len() >= maxFlows {
+		proxy.evictOldest(maxFlows)
+	}
+
+	proxyConn, err := net.DialUDP("udp", nil, proxy.backendAddr)
+	if err != nil {
+		proxy.stats.dialFailed()
+		log.Debugf("Can't proxy a datagram to udp/%s: %s\n", proxy.backendAddr, err)
+		return nil
+	}
+
+	flow := &connTrackFlow{conn: proxyConn, client: from, key: *key}
+	flow.elem = proxy.lru.PushFront(flow)
+	proxy.connTrackTable[*key] = flow
+
+	if batched {
+		flow.batch = newBatchConn(proxyConn)
+		go proxy.batchReplyLoop(flow)
+	} else {
+		go proxy.replyLoop(flow)
+	}
+
+	return flow
+}
+
+// evictOldest drops the least recently used flow to make room for another.
+// The caller must hold the connTrackLock.
+func (proxy *UDPProxy) evictOldest(maxFlows int) {
+	flow := proxy.lru.Back().Value.(*connTrackFlow)
+	proxy.untrack(flow)
+	proxy.stats.evicted()
+
+	log.Infof("Evicting UDP flow from %s to udp/%s, reached the limit of %d flows",
+		flow.client, proxy.backendAddr, maxFlows,
+	)
+
+	// Its reply loop will stop now
+	flow.conn.Close()
+}
+
+// forget stops tracking a flow that has gone idle or been closed. It may
+// already have been evicted, and even replaced by a new flow for the same
+// client, so we only remove it if it's still there.
+func (proxy *UDPProxy) forget(flow *connTrackFlow) {
+	proxy.connTrackLock.Lock()
+	defer proxy.connTrackLock.Unlock()
+
+	if proxy.connTrackTable[flow.key] == flow {
+		proxy.untrack(flow)
+	}
+}
+
+// untrack removes the flow from the table. The caller must hold the
+// connTrackLock.
+func (proxy *UDPProxy) untrack(flow *connTrackFlow) {
+	delete(proxy.connTrackTable, flow.key)
+	proxy.lru.Remove(flow.elem)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	log "github.com/sirupsen/logrus"
+)
+
+// UDPEnvoyProxy binds the UDP port and proxies it in-process, just like
+// docker-proxy, so that Docker sees the port bound right away. Once the
+// container shows up in Docker, if it asked for the udp-envoy proxy mode,
+// the port is handed over to Envoy and the entry is registered with the
+// server instead.
+type UDPEnvoyProxy struct {
+	sync.Mutex
+	udp       *UDPProxy
+	envoy     *EnvoyProxy
+	handedOff bool
+	closed    bool
+
+	StatsInterval time.Duration // How often to report stats when in-process
+}
+
+// NewUDPEnvoyProxy returns a UDPEnvoyProxy with the port already bound.
+func NewUDPEnvoyProxy(frontendAddr, backendAddr *net.UDPAddr, svrAddr string) (*UDPEnvoyProxy, error) {
+	udp, err := NewUDPProxy(frontendAddr, backendAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Register the port we actually bound, in case we were passed 0
+	bound := &net.UDPAddr{
+		IP:   frontendAddr.IP,
+		Port: udp.FrontendAddr().(*net.UDPAddr).Port,
+	}
+
+	envoy, err := NewEnvoyProxy(bound, backendAddr, svrAddr)
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+
+	return &UDPEnvoyProxy{
+		udp:           udp,
+		envoy:         envoy,
+		StatsInterval: 10 * time.Second,
+	}, nil
+}
+
+// Run proxies in-process until we know what the container wants and then
+// either carries on doing that or hands off to Envoy. It blocks until the
+// proxy is closed.
+func (p *UDPEnvoyProxy) Run() {
+	done := make(chan struct{})
+	go func() {
+		p.udp.Run()
+		close(done)
+	}()
+
+	_, err := p.envoy.request(shimrpc.RegistrarRequest_REGISTER)
+	if err != nil {
+		log.Warnf("Unable to look up the proxy mode, proxying UDP in-process: %s", err)
+		p.reportStats(&DockerSettings{}, done)
+		return
+	}
+
+	settings := p.envoy.cachedSettings()
+	if settings.ProxyMode != UDPEnvoyProxyMode {
+		if settings.UDPLimits != nil {
+			p.udp.SetLimits(*settings.UDPLimits)
+		}
+		p.reportStats(settings, done)
+		return
+	}
+
+	p.Lock()
+	if p.closed {
+		p.Unlock()
+		return
+	}
+	log.Infof("Handing udp/%s over to Envoy", p.envoy.FrontendAddr())
+	p.handedOff = true
+	p.udp.Close()
+	p.Unlock()
+
+	<-done
+	p.envoy.Run()
+}
+
+// reportStats sends the in-process proxy's stats to the server every
+// StatsInterval until done is closed, and then sends a final report.
+func (p *UDPEnvoyProxy) reportStats(settings *DockerSettings, done chan struct{}) {
+	ticker := time.NewTicker(p.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.sendStats(settings, false)
+		case <-done:
+			p.sendStats(settings, true)
+			return
+		}
+	}
+}
+
+// sendStats makes a single stats report to the server. The stats are
+// cumulative, so it's no big deal if one goes missing.
+func (p *UDPEnvoyProxy) sendStats(settings *DockerSettings, closed bool) {
+	stats := p.udp.Stats()
+	stats.Proxy = p.envoy.RequestWithSettings(settings)
+	stats.Closed = closed
+
+	err := p.envoy.WithClient(func(c shimrpc.RegistrarClient) error {
+		ctx, cancel := context.WithTimeout(context.Background(), p.envoy.GRPCTimeout)
+		defer cancel()
+
+		_, err := c.ReportUDPStats(ctx, stats)
+		return err
+	})
+
+	if err != nil {
+		log.Debugf("Unable to report UDP stats: %s", err)
+	}
+}
+
+// Close stops whichever of the proxies is handling the port.
+func (p *UDPEnvoyProxy) Close() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.closed = true
+
+	if p.handedOff {
+		p.envoy.Close()
+		return
+	}
+
+	p.envoy.cancel() // Stop waiting on Docker
+	p.udp.Close()
+}
+
+// FrontendAddr returns the UDP address on which the proxy is listening.
+func (p *UDPEnvoyProxy) FrontendAddr() net.Addr { return p.envoy.FrontendAddr() }
+
+// BackendAddr returns the proxied UDP address.
+func (p *UDPEnvoyProxy) BackendAddr() net.Addr { return p.envoy.BackendAddr() }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// modeDiscoveryClient finds every container, with the proxy mode set
+type modeDiscoveryClient struct {
+	mode   string
+	limits *UDPLimits
+}
+
+func (c *modeDiscoveryClient) ContainerFieldsFor(host, backend net.Addr) (*DockerSettings, error) {
+	return &DockerSettings{
+		ServiceName:     "kjartan",
+		EnvironmentName: "dev",
+		ProxyMode:       c.mode,
+		UDPLimits:       c.limits,
+	}, nil
+}
+
+func (c *modeDiscoveryClient) WaitForContainerFields(ctx context.Context, host, backend net.Addr) (*DockerSettings, error) {
+	return c.ContainerFieldsFor(host, backend)
+}
+
+func Test_UDPEnvoyProxy(t *testing.T) {
+	Convey("UDPEnvoyProxy", t, func() {
+		socketPath := filepath.Join(os.TempDir(), "docker-envoy-udp.sock")
+		registrar := envoyhttp.NewRegistrar()
+		s := serveGRPC(registrar, socketPath)
+
+		frontend := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 0}
+		backend := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 31337}
+
+		proxy, err := NewUDPEnvoyProxy(frontend, backend, socketPath)
+		So(err, ShouldBeNil)
+		proxy.envoy.GRPCTimeout = 20 * time.Millisecond
+		proxy.envoy.Retries = []int{1, 1}
+
+		port := proxy.FrontendAddr().(*net.UDPAddr).Port
+		name := fmt.Sprintf("kjartan-dev-%d-udp", port)
+
+		done := make(chan struct{})
+		run := func() {
+			go func() {
+				proxy.Run()
+				close(done)
+			}()
+		}
+
+		Reset(func() {
+			proxy.Close()
+			s.Stop()
+			os.Remove(socketPath)
+		})
+
+		Convey("binds the port right away", func() {
+			_, err := net.ListenUDP("udp", proxy.FrontendAddr().(*net.UDPAddr))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("reports stats to the server when in-process", func() {
+			proxy.StatsInterval = 10 * time.Millisecond
+			proxy.envoy.Discoverer = &modeDiscoveryClient{mode: "http"}
+			run()
+
+			reported := func() bool {
+				for i := 0; i < 100; i++ {
+					if testutil.CollectAndCount(registrar.UDPStats) > 0 {
+						return true
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				return false
+			}
+			So(reported(), ShouldBeTrue)
+		})
+
+		Convey("keeps proxying in-process in other modes", func() {
+			proxy.envoy.Discoverer = &modeDiscoveryClient{mode: "http"}
+			run()
+
+			So(waitForEntry(registrar, name), ShouldBeFalse)
+
+			proxy.Close()
+			select {
+			case <-done:
+			case <-time.After(1 * time.Second):
+				So("Run() to return", ShouldBeEmpty)
+			}
+		})
+
+		Convey("applies the container's UDP limits when in-process", func() {
+			limits := UDPLimits{ConnTrackTimeout: 5 * time.Second, MaxFlows: 10, RateLimit: 100}
+			proxy.envoy.Discoverer = &modeDiscoveryClient{mode: "http", limits: &limits}
+			run()
+
+			for i := 0; i < 100 && proxy.udp.Limits().MaxFlows != 10; i++ {
+				time.Sleep(10 * time.Millisecond)
+			}
+			So(*proxy.udp.Limits(), ShouldResemble, limits)
+		})
+
+		Convey("hands the port over to Envoy in udp-envoy mode", func() {
+			proxy.envoy.Discoverer = &modeDiscoveryClient{mode: "udp-envoy"}
+			run()
+
+			So(waitForEntry(registrar, name), ShouldBeTrue)
+			So(registrar.GetEntry(name).IsUDP(), ShouldBeTrue)
+
+			// Envoy has to be able to bind it now
+			conn, err := net.ListenUDP("udp", proxy.FrontendAddr().(*net.UDPAddr))
+			So(err, ShouldBeNil)
+			conn.Close()
+
+			Convey("and deregisters on close", func() {
+				proxy.Close()
+				So(registrar.GetEntry(name), ShouldBeNil)
+			})
+		})
+	})
+}
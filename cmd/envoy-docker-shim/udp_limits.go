@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// UDPMaxFlows is the default limit on the flows we track at once
+	UDPMaxFlows = 4096
+
+	// udpMinSweep is how many clients we rate limit before we start looking
+	// for ones we can forget
+	udpMinSweep = 1024
+)
+
+// UDPLimits bound the resources an in-process UDP proxy will use, so that
+// a flood from lots of (possibly spoofed) sources can't run us out of
+// sockets and goroutines.
+type UDPLimits struct {
+	ConnTrackTimeout time.Duration // How long a flow can be idle before we drop it
+	MaxFlows         int           // Flows tracked at once, the least recently used is evicted. 0 is unlimited
+	RateLimit        float64       // Datagrams per second from each client IP. 0 is unlimited
+	RateBurst        int           // Datagrams a client IP can send at once, defaults to RateLimit
+}
+
+// DefaultUDPLimits returns the limits used when nothing else is configured.
+func DefaultUDPLimits() UDPLimits {
+	return UDPLimits{
+		ConnTrackTimeout: UDPConnTrackTimeout,
+		MaxFlows:         UDPMaxFlows,
+	}
+}
+
+// A udpLimitSetting can be set from a Docker label on the container or from
+// an environment variable for the shim.
+type udpLimitSetting struct {
+	label string
+	env   string
+	set   func(l *UDPLimits, value string) error
+}
+
+var udpLimitSettings = []udpLimitSetting{
+	{"UDPConnTrackTimeout", "SHIM_UDP_CONNTRACK_TIMEOUT", func(l *UDPLimits, value string) error {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("expected a positive duration like 30s")
+		}
+		l.ConnTrackTimeout = timeout
+		return nil
+	}},
+	{"UDPMaxFlows", "SHIM_UDP_MAX_FLOWS", func(l *UDPLimits, value string) error {
+		flows, err := strconv.Atoi(value)
+		if err != nil || flows < 0 {
+			return fmt.Errorf("expected a whole number, 0 for unlimited")
+		}
+		l.MaxFlows = flows
+		return nil
+	}},
+	{"UDPRateLimit", "SHIM_UDP_RATE_LIMIT", func(l *UDPLimits, value string) error {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("expected datagrams per second, 0 for unlimited")
+		}
+		l.RateLimit = rate
+		return nil
+	}},
+	{"UDPRateBurst", "SHIM_UDP_RATE_BURST", func(l *UDPLimits, value string) error {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst < 1 {
+			return fmt.Errorf("expected a number of datagrams")
+		}
+		l.RateBurst = burst
+		return nil
+	}},
+}
+
+// UDPLimitsFromEnv returns the default limits, overridden by any that are
+// set in the shim's environment.
+func UDPLimitsFromEnv() UDPLimits {
+	return DefaultUDPLimits().with(func(s udpLimitSetting) (string, string) {
+		return s.env, os.Getenv(s.env)
+	})
+}
+
+// WithLabels returns a copy of the limits, overridden by any that are set
+// in the container's labels.
+func (l UDPLimits) WithLabels(labels map[string]string) UDPLimits {
+	return l.with(func(s udpLimitSetting) (string, string) {
+		return s.label, labels[s.label]
+	})
+}
+
+// with applies each setting that lookup finds. Invalid values are logged and
+// skipped, we'd rather keep proxying with the defaults than fail.
+func (l UDPLimits) with(lookup func(s udpLimitSetting) (name, value string)) UDPLimits {
+	for _, setting := range udpLimitSettings {
+		name, value := lookup(setting)
+		if len(value) < 1 {
+			continue
+		}
+
+		err := setting.set(&l, value)
+		if err != nil {
+			log.Warnf("Ignoring invalid %s '%s': %s", name, value, err)
+		}
+	}
+
+	return l
+}
+
+// burst is how many datagrams a client can send before the rate limit kicks in
+func (l *UDPLimits) burst() float64 {
+	if l.RateBurst > 0 {
+		return float64(l.RateBurst)
+	}
+
+	if l.RateLimit < 1 {
+		return 1
+	}
+
+	return l.RateLimit
+}
+
+// udpRateLimiter rate limits each client IP. It goes by IP rather than by
+// flow so a client can't get around it by sending from lots of ports, and
+// it is checked before we set up a flow, so datagrams it drops don't cost
+// us a socket. The zero value is ready to use.
+type udpRateLimiter struct {
+	sync.Mutex
+	buckets map[connTrackKey]*tokenBucket // The Port is always 0
+	sweepAt int                           // How many buckets before we sweep
+}
+
+// allow takes a token for a datagram from the IP if there is one.
+func (l *udpRateLimiter) allow(ip net.IP, limits *UDPLimits, now time.Time) bool {
+	if limits.RateLimit <= 0 {
+		return true
+	}
+
+	key := *newConnTrackKey(&net.UDPAddr{IP: ip.To16()})
+
+	l.Lock()
+	defer l.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.sweepAt {
+			l.sweep(limits, now)
+		}
+		bucket = &tokenBucket{}
+		l.buckets[key] = bucket
+	}
+
+	return bucket.allow(limits, now)
+}
+
+// sweep forgets the clients whose buckets have refilled, since a full bucket
+// is no different from a new one. Between sweeps we let the buckets double,
+// so a flood from new clients doesn't have us sweeping on every datagram.
+// The caller must hold the lock.
+func (l *udpRateLimiter) sweep(limits *UDPLimits, now time.Time) {
+	if l.buckets == nil {
+		l.buckets = make(map[connTrackKey]*tokenBucket)
+	}
+
+	burst := limits.burst()
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*limits.RateLimit >= burst {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.sweepAt = 2 * len(l.buckets)
+	if l.sweepAt < udpMinSweep {
+		l.sweepAt = udpMinSweep
+	}
+}
+
+// tokenBucket rate limits one client IP. The udpRateLimiter holding it does
+// the locking.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token for a datagram if there is one.
+func (b *tokenBucket) allow(limits *UDPLimits, now time.Time) bool {
+	if limits.RateLimit <= 0 {
+		return true
+	}
+
+	burst := limits.burst()
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * limits.RateLimit
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
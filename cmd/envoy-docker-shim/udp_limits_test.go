@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_UDPLimits(t *testing.T) {
+	Convey("UDPLimits", t, func() {
+		defaults := DefaultUDPLimits()
+
+		Convey("are overridden by container labels", func() {
+			limits := defaults.WithLabels(map[string]string{
+				"UDPConnTrackTimeout": "30s",
+				"UDPMaxFlows":         "100",
+				"UDPRateLimit":        "50",
+				"UDPRateBurst":        "10",
+			})
+
+			So(limits, ShouldResemble, UDPLimits{
+				ConnTrackTimeout: 30 * time.Second,
+				MaxFlows:         100,
+				RateLimit:        50,
+				RateBurst:        10,
+			})
+		})
+
+		Convey("ignore invalid labels", func() {
+			limits := defaults.WithLabels(map[string]string{
+				"UDPConnTrackTimeout": "-30s",
+				"UDPMaxFlows":         "lots",
+				"UDPRateLimit":        "-1",
+				"UDPRateBurst":        "0",
+			})
+
+			So(limits, ShouldResemble, defaults)
+		})
+
+		Convey("keep the defaults without labels", func() {
+			So(defaults.WithLabels(nil), ShouldResemble, defaults)
+			So(defaults.ConnTrackTimeout, ShouldEqual, UDPConnTrackTimeout)
+			So(defaults.MaxFlows, ShouldEqual, UDPMaxFlows)
+		})
+
+		Convey("are overridden by the environment", func() {
+			os.Setenv("SHIM_UDP_MAX_FLOWS", "0")
+			defer os.Unsetenv("SHIM_UDP_MAX_FLOWS")
+
+			limits := UDPLimitsFromEnv()
+			So(limits.MaxFlows, ShouldEqual, 0)
+			So(limits.ConnTrackTimeout, ShouldEqual, UDPConnTrackTimeout)
+		})
+	})
+}
+
+func Test_tokenBucket(t *testing.T) {
+	Convey("tokenBucket", t, func() {
+		var bucket tokenBucket
+		now := time.Now()
+
+		Convey("allows everything without a rate limit", func() {
+			limits := &UDPLimits{}
+			for i := 0; i < 1000; i++ {
+				So(bucket.allow(limits, now), ShouldBeTrue)
+			}
+		})
+
+		Convey("allows a burst and then the rate", func() {
+			limits := &UDPLimits{RateLimit: 10, RateBurst: 5}
+
+			allowed := 0
+			for i := 0; i < 20; i++ {
+				if bucket.allow(limits, now) {
+					allowed++
+				}
+			}
+			So(allowed, ShouldEqual, 5)
+
+			// 10 per second is one every 100ms
+			So(bucket.allow(limits, now.Add(50*time.Millisecond)), ShouldBeFalse)
+			So(bucket.allow(limits, now.Add(150*time.Millisecond)), ShouldBeTrue)
+			So(bucket.allow(limits, now.Add(150*time.Millisecond)), ShouldBeFalse)
+		})
+
+		Convey("bursts up to the rate by default", func() {
+			limits := &UDPLimits{RateLimit: 3}
+
+			allowed := 0
+			for i := 0; i < 20; i++ {
+				if bucket.allow(limits, now) {
+					allowed++
+				}
+			}
+			So(allowed, ShouldEqual, 3)
+		})
+	})
+}
+
+func Test_udpRateLimiter(t *testing.T) {
+	Convey("udpRateLimiter", t, func() {
+		var limiter udpRateLimiter
+		limits := &UDPLimits{RateLimit: 10, RateBurst: 1}
+		now := time.Now()
+
+		Convey("limits each IP, whatever port it sends from", func() {
+			So(limiter.allow(net.ParseIP("172.16.10.1"), limits, now), ShouldBeTrue)
+			So(limiter.allow(net.ParseIP("172.16.10.1"), limits, now), ShouldBeFalse)
+			So(limiter.allow(net.ParseIP("172.16.10.1").To4(), limits, now), ShouldBeFalse)
+			So(limiter.allow(net.ParseIP("172.16.10.2"), limits, now), ShouldBeTrue)
+		})
+
+		Convey("forgets clients once their buckets have refilled", func() {
+			for i := 0; i < udpMinSweep; i++ {
+				limiter.allow(net.IPv4(10, 0, byte(i>>8), byte(i)), limits, now)
+			}
+			So(len(limiter.buckets), ShouldEqual, udpMinSweep)
+
+			// Long enough for them all to refill
+			limiter.allow(net.ParseIP("172.16.10.1"), limits, now.Add(time.Second))
+			So(len(limiter.buckets), ShouldEqual, 1)
+		})
+	})
+}
@@ -5,10 +5,12 @@ package main
 // https://github.com/docker/libnetwork/blob/master/LICENSE
 
 import (
+	"container/list"
 	"encoding/binary"
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -16,7 +18,7 @@ import (
 )
 
 const (
-	// UDPConnTrackTimeout is the timeout used for UDP connection tracking
+	// UDPConnTrackTimeout is the default timeout used for UDP connection tracking
 	UDPConnTrackTimeout = 90 * time.Second
 	// UDPBufSize is the buffer size for the UDP proxy
 	UDPBufSize = 65507
@@ -45,17 +47,34 @@ func newConnTrackKey(addr *net.UDPAddr) *connTrackKey {
 	}
 }
 
-type connTrackMap map[connTrackKey]*net.UDPConn
+// connTrackFlow is the socket we proxy one client's datagrams through
+type connTrackFlow struct {
+	conn   *net.UDPConn
+	batch  batchConn // Only set when we are batching
+	client *net.UDPAddr
+	key    connTrackKey
+	elem   *list.Element // Where we are in the LRU list
+}
+
+type connTrackMap map[connTrackKey]*connTrackFlow
 
 // UDPProxy is proxy for which handles UDP datagrams. It implements the Proxy
 // interface to handle UDP traffic forwarding between the frontend and backend
 // addresses.
 type UDPProxy struct {
+	stats          udpStats // First, so the counters are 64-bit aligned
 	listener       *net.UDPConn
+	batchListener  batchConn
 	frontendAddr   *net.UDPAddr
 	backendAddr    *net.UDPAddr
 	connTrackTable connTrackMap
 	connTrackLock  sync.Mutex
+	lru            *list.List   // Flows, most recently used first
+	limits         atomic.Value // Holds a *UDPLimits
+	rateLimiter    udpRateLimiter
+
+	BatchSize int // Datagrams per syscall, 1 forwards them one at a time
+	Workers   int // How many goroutines forward datagrams to the backend
 }
 
 // NewUDPProxy creates a new UDPProxy.
@@ -64,25 +83,32 @@ func NewUDPProxy(frontendAddr, backendAddr *net.UDPAddr) (*UDPProxy, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &UDPProxy{
+	proxy := &UDPProxy{
 		listener:       listener,
+		batchListener:  newBatchConn(listener),
 		frontendAddr:   listener.LocalAddr().(*net.UDPAddr),
 		backendAddr:    backendAddr,
 		connTrackTable: make(connTrackMap),
-	}, nil
+		lru:            list.New(),
+		BatchSize:      UDPBatchSize,
+		Workers:        UDPWorkers,
+	}
+	limits := UDPLimitsFromEnv()
+	proxy.limits.Store(&limits)
+
+	return proxy, nil
 }
 
-func (proxy *UDPProxy) replyLoop(proxyConn *net.UDPConn, clientAddr *net.UDPAddr, clientKey *connTrackKey) {
+func (proxy *UDPProxy) replyLoop(flow *connTrackFlow) {
+	proxyConn, clientAddr := flow.conn, flow.client
 	defer func() {
-		proxy.connTrackLock.Lock()
-		delete(proxy.connTrackTable, *clientKey)
-		proxy.connTrackLock.Unlock()
+		proxy.forget(flow)
 		proxyConn.Close()
 	}()
 
 	readBuf := make([]byte, UDPBufSize)
 	for {
-		proxyConn.SetReadDeadline(time.Now().Add(UDPConnTrackTimeout))
+		proxyConn.SetReadDeadline(time.Now().Add(proxy.Limits().ConnTrackTimeout))
 	again:
 		read, err := proxyConn.Read(readBuf)
 		if err != nil {
@@ -90,24 +116,40 @@ func (proxy *UDPProxy) replyLoop(proxyConn *net.UDPConn, clientAddr *net.UDPAddr
 				// This will happen if the last write failed
 				// (e.g: nothing is actually listening on the
 				// proxied port on the container), ignore it
-				// and continue until UDPConnTrackTimeout
+				// and continue until the ConnTrackTimeout
 				// expires:
 				goto again
 			}
+			if err, ok := err.(net.Error); ok && err.Timeout() {
+				proxy.stats.expired()
+				log.Debugf("UDP flow from %s to udp/%s went idle", clientAddr, proxy.backendAddr)
+			}
 			return
 		}
 		for i := 0; i != read; {
 			written, err := proxy.listener.WriteToUDP(readBuf[i:read], clientAddr)
 			if err != nil {
+				proxy.stats.writeFailed()
 				return
 			}
 			i += written
 		}
+		proxy.stats.sentOut(read)
 	}
 }
 
 // Run starts forwarding the traffic using UDP.
 func (proxy *UDPProxy) Run() {
+	if proxy.BatchSize > 1 {
+		proxy.runBatched()
+		return
+	}
+
+	proxy.runSingle()
+}
+
+// runSingle forwards the traffic one datagram at a time.
+func (proxy *UDPProxy) runSingle() {
 	readBuf := make([]byte, UDPBufSize)
 	for {
 		read, from, err := proxy.listener.ReadFromUDP(readBuf)
@@ -121,27 +163,26 @@ func (proxy *UDPProxy) Run() {
 			break
 		}
 
-		fromKey := newConnTrackKey(from)
-		proxy.connTrackLock.Lock()
-		proxyConn, hit := proxy.connTrackTable[*fromKey]
-		if !hit {
-			proxyConn, err = net.DialUDP("udp", nil, proxy.backendAddr)
-			if err != nil {
-				log.Debugf("Can't proxy a datagram to udp/%s: %s\n", proxy.backendAddr, err)
-				proxy.connTrackLock.Unlock()
-				continue
-			}
-			proxy.connTrackTable[*fromKey] = proxyConn
-			go proxy.replyLoop(proxyConn, from, fromKey)
+		if !proxy.rateLimiter.allow(from.IP, proxy.Limits(), time.Now()) {
+			proxy.stats.rateLimited()
+			continue
+		}
+		flow := proxy.flowFor(from, false)
+		if flow == nil {
+			continue
 		}
-		proxy.connTrackLock.Unlock()
+		proxyConn := flow.conn
 		for i := 0; i != read; {
 			written, err := proxyConn.Write(readBuf[i:read])
 			if err != nil {
+				proxy.stats.writeFailed()
 				log.Debugf("Can't proxy a datagram to udp/%s: %s\n", proxy.backendAddr, err)
 				break
 			}
 			i += written
+			if i == read {
+				proxy.stats.sentIn(read)
+			}
 		}
 	}
 }
@@ -151,8 +192,8 @@ func (proxy *UDPProxy) Close() {
 	proxy.listener.Close()
 	proxy.connTrackLock.Lock()
 	defer proxy.connTrackLock.Unlock()
-	for _, conn := range proxy.connTrackTable {
-		conn.Close()
+	for _, flow := range proxy.connTrackTable {
+		flow.conn.Close()
 	}
 }
 
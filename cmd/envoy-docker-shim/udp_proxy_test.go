@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// echoServer plays the part of the container, sending every datagram
+// straight back where it came from.
+func echoServer() *net.UDPConn {
+	return echoServerOn("127.0.0.1")
+}
+
+func echoServerOn(ip string) *net.UDPConn {
+	backend, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(ip)})
+	if err != nil {
+		return nil
+	}
+	go func() {
+		buf := make([]byte, UDPBufSize)
+		for {
+			read, from, err := backend.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			backend.WriteToUDP(buf[:read], from)
+		}
+	}()
+
+	return backend
+}
+
+func newTestUDPProxy(backend *net.UDPConn, batchSize int) *UDPProxy {
+	return newTestUDPProxyOn("127.0.0.1", backend, batchSize)
+}
+
+func newTestUDPProxyOn(ip string, backend *net.UDPConn, batchSize int) *UDPProxy {
+	proxy, err := NewUDPProxy(
+		&net.UDPAddr{IP: net.ParseIP(ip)}, backend.LocalAddr().(*net.UDPAddr),
+	)
+	if err != nil {
+		panic(err)
+	}
+	proxy.BatchSize = batchSize
+	go proxy.Run()
+
+	return proxy
+}
+
+func Test_UDPProxy(t *testing.T) {
+	for _, batchSize := range []int{1, UDPBatchSize} {
+		Convey(fmt.Sprintf("UDPProxy with a batch size of %d", batchSize), t, func() {
+			backend := echoServer()
+			proxy := newTestUDPProxy(backend, batchSize)
+
+			Reset(func() {
+				proxy.Close()
+				backend.Close()
+			})
+
+			Convey("proxies each client's datagrams in order", func() {
+				var wg sync.WaitGroup
+				results := make([][]string, 4)
+
+				for c := range results {
+					wg.Add(1)
+					go func(c int) {
+						defer wg.Done()
+
+						client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+						defer client.Close()
+
+						buf := make([]byte, 1024)
+						for i := 0; i < 10; i++ {
+							client.Write([]byte(fmt.Sprintf("%d-%d", c, i)))
+							client.SetReadDeadline(time.Now().Add(1 * time.Second))
+							read, err := client.Read(buf)
+							if err != nil {
+								return
+							}
+							results[c] = append(results[c], string(buf[:read]))
+						}
+					}(c)
+				}
+				wg.Wait()
+
+				for c, got := range results {
+					So(len(got), ShouldEqual, 10)
+					So(got[0], ShouldEqual, fmt.Sprintf("%d-0", c))
+					So(got[9], ShouldEqual, fmt.Sprintf("%d-9", c))
+				}
+			})
+
+			Convey("counts datagrams and bytes in each direction", func() {
+				client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+				defer client.Close()
+
+				client.Write([]byte("beowulf"))
+				client.SetReadDeadline(time.Now().Add(1 * time.Second))
+				buf := make([]byte, 1024)
+				read, err := client.Read(buf)
+				So(err, ShouldBeNil)
+				So(read, ShouldEqual, 7)
+
+				// Each datagram is counted just after it is sent
+				stats := proxy.Stats()
+				for i := 0; i < 100 && (stats.DatagramsIn < 1 || stats.DatagramsOut < 1); i++ {
+					time.Sleep(10 * time.Millisecond)
+					stats = proxy.Stats()
+				}
+
+				So(stats.DatagramsIn, ShouldEqual, 1)
+				So(stats.BytesIn, ShouldEqual, 7)
+				So(stats.DatagramsOut, ShouldEqual, 1)
+				So(stats.BytesOut, ShouldEqual, 7)
+				So(stats.ConntrackEntries, ShouldEqual, 1)
+			})
+
+			Convey("proxies IPv6 and IPv4 clients on a dual stack socket", func() {
+				v6Backend := echoServerOn("::1")
+				if v6Backend == nil {
+					SkipSo("IPv6 is not available")
+					return
+				}
+				defer v6Backend.Close()
+
+				v6Proxy := newTestUDPProxyOn("::", v6Backend, batchSize)
+				defer v6Proxy.Close()
+				port := v6Proxy.FrontendAddr().(*net.UDPAddr).Port
+
+				for _, ip := range []string{"::1", "127.0.0.1"} {
+					client, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.ParseIP(ip), Port: port})
+					So(err, ShouldBeNil)
+					defer client.Close()
+
+					client.Write([]byte("beowulf"))
+					client.SetReadDeadline(time.Now().Add(1 * time.Second))
+					buf := make([]byte, 1024)
+					read, err := client.Read(buf)
+					So(err, ShouldBeNil)
+					So(string(buf[:read]), ShouldEqual, "beowulf")
+				}
+			})
+
+			Convey("evicts the least recently used flow at the limit", func() {
+				proxy.SetLimits(UDPLimits{ConnTrackTimeout: UDPConnTrackTimeout, MaxFlows: 2})
+
+				var clients []*net.UDPConn
+				for i := 0; i < 3; i++ {
+					client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+					defer client.Close()
+					clients = append(clients, client)
+
+					client.Write([]byte("beowulf"))
+					client.SetReadDeadline(time.Now().Add(1 * time.Second))
+					_, err := client.Read(make([]byte, 1024))
+					So(err, ShouldBeNil)
+				}
+
+				stats := proxy.Stats()
+				So(stats.ConntrackEntries, ShouldEqual, 2)
+				So(stats.ConntrackEvictions, ShouldEqual, 1)
+
+				// The first client is the one that went
+				key := newConnTrackKey(clients[0].LocalAddr().(*net.UDPAddr))
+				proxy.connTrackLock.Lock()
+				_, ok := proxy.connTrackTable[*key]
+				proxy.connTrackLock.Unlock()
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("drops datagrams over the rate limit", func() {
+				proxy.SetLimits(UDPLimits{ConnTrackTimeout: UDPConnTrackTimeout, RateLimit: 0.1, RateBurst: 2})
+
+				client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+				defer client.Close()
+
+				received := 0
+				buf := make([]byte, 1024)
+				for i := 0; i < 5; i++ {
+					client.Write([]byte("beowulf"))
+					client.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+					if _, err := client.Read(buf); err == nil {
+						received++
+					}
+				}
+
+				So(received, ShouldEqual, 2)
+				So(proxy.Stats().RateLimited, ShouldEqual, 3)
+			})
+
+			Convey("rate limits by client IP before setting up a flow", func() {
+				proxy.SetLimits(UDPLimits{ConnTrackTimeout: UDPConnTrackTimeout, RateLimit: 0.1, RateBurst: 1})
+
+				received := 0
+				buf := make([]byte, 1024)
+				for i := 0; i < 3; i++ {
+					client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+					defer client.Close()
+
+					client.Write([]byte("beowulf"))
+					client.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+					if _, err := client.Read(buf); err == nil {
+						received++
+					}
+				}
+
+				So(received, ShouldEqual, 1)
+				stats := proxy.Stats()
+				So(stats.RateLimited, ShouldEqual, 2)
+				So(stats.ConntrackEntries, ShouldEqual, 1)
+			})
+
+			Convey("drops idle flows after the timeout", func() {
+				proxy.SetLimits(UDPLimits{ConnTrackTimeout: 20 * time.Millisecond})
+
+				client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+				defer client.Close()
+
+				client.Write([]byte("beowulf"))
+				client.SetReadDeadline(time.Now().Add(1 * time.Second))
+				_, err := client.Read(make([]byte, 1024))
+				So(err, ShouldBeNil)
+
+				stats := proxy.Stats()
+				for i := 0; i < 100 && stats.ConntrackEntries > 0; i++ {
+					time.Sleep(10 * time.Millisecond)
+					stats = proxy.Stats()
+				}
+				So(stats.ConntrackEntries, ShouldEqual, 0)
+				So(stats.ConntrackExpiries, ShouldEqual, 1)
+			})
+
+			Convey("stops when closed", func() {
+				proxy.Close()
+
+				client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+				defer client.Close()
+
+				client.Write([]byte("beowulf"))
+				client.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+				_, err := client.Read(make([]byte, 1024))
+				So(err, ShouldNotBeNil)
+			})
+		})
+	}
+}
+
+// benchmarkUDPProxy has each parallel client send bursts of datagrams
+// through the proxy and then read back the echoes. Anything dropped is
+// reported as loss rather than stalling the benchmark.
+func benchmarkUDPProxy(b *testing.B, batchSize int) {
+	backend := echoServer()
+	defer backend.Close()
+	proxy := newTestUDPProxy(backend, batchSize)
+	defer proxy.Close()
+
+	const burst = 16
+	payload := make([]byte, 512)
+
+	var lock sync.Mutex
+	var sent, received int
+
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		client, _ := net.DialUDP("udp", nil, proxy.FrontendAddr().(*net.UDPAddr))
+		defer client.Close()
+
+		buf := make([]byte, 1024)
+		var mySent, myReceived, pending int
+
+		drain := func() {
+			for ; pending > 0; pending-- {
+				client.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+				if _, err := client.Read(buf); err != nil {
+					pending = 0
+					return
+				}
+				myReceived++
+			}
+		}
+
+		for pb.Next() {
+			client.Write(payload)
+			mySent++
+			pending++
+			if pending == burst {
+				drain()
+			}
+		}
+		drain()
+
+		lock.Lock()
+		sent += mySent
+		received += myReceived
+		lock.Unlock()
+	})
+
+	if sent > 0 {
+		b.ReportMetric(100*float64(sent-received)/float64(sent), "%loss")
+	}
+}
+
+func BenchmarkUDPProxy_Single(b *testing.B)  { benchmarkUDPProxy(b, 1) }
+func BenchmarkUDPProxy_Batched(b *testing.B) { benchmarkUDPProxy(b, UDPBatchSize) }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+)
+
+// udpStats are the counters kept by a UDPProxy. They are updated from all
+// the forwarding goroutines, so they are only touched atomically.
+type udpStats struct {
+	datagramsIn   uint64
+	bytesIn       uint64
+	datagramsOut  uint64
+	bytesOut      uint64
+	expiries      uint64
+	dialFailures  uint64
+	writeFailures uint64
+	evictions     uint64
+	rateDrops     uint64
+}
+
+func (s *udpStats) sentIn(size int) {
+	atomic.AddUint64(&s.datagramsIn, 1)
+	atomic.AddUint64(&s.bytesIn, uint64(size))
+}
+
+func (s *udpStats) sentOut(size int) {
+	atomic.AddUint64(&s.datagramsOut, 1)
+	atomic.AddUint64(&s.bytesOut, uint64(size))
+}
+
+func (s *udpStats) expired()     { atomic.AddUint64(&s.expiries, 1) }
+func (s *udpStats) dialFailed()  { atomic.AddUint64(&s.dialFailures, 1) }
+func (s *udpStats) writeFailed() { atomic.AddUint64(&s.writeFailures, 1) }
+func (s *udpStats) evicted()     { atomic.AddUint64(&s.evictions, 1) }
+func (s *udpStats) rateLimited() { atomic.AddUint64(&s.rateDrops, 1) }
+
+// Stats returns a snapshot of the counters for reporting to the server.
+func (proxy *UDPProxy) Stats() *shimrpc.UDPStatsRequest {
+	proxy.connTrackLock.Lock()
+	entries := len(proxy.connTrackTable)
+	proxy.connTrackLock.Unlock()
+
+	s := &proxy.stats
+	return &shimrpc.UDPStatsRequest{
+		DatagramsIn:        atomic.LoadUint64(&s.datagramsIn),
+		BytesIn:            atomic.LoadUint64(&s.bytesIn),
+		DatagramsOut:       atomic.LoadUint64(&s.datagramsOut),
+		BytesOut:           atomic.LoadUint64(&s.bytesOut),
+		ConntrackEntries:   int64(entries),
+		ConntrackExpiries:  atomic.LoadUint64(&s.expiries),
+		DialFailures:       atomic.LoadUint64(&s.dialFailures),
+		WriteFailures:      atomic.LoadUint64(&s.writeFailures),
+		ConntrackEvictions: atomic.LoadUint64(&s.evictions),
+		RateLimited:        atomic.LoadUint64(&s.rateDrops),
+	}
+}
@@ -0,0 +1,173 @@
+package dockerwatch
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// Docker sends health changes as actions like "health_status: healthy"
+const healthStatusAction = "health_status:"
+
+// EventsClient is the subset of the go-dockerclient Client that the
+// EventWatcher uses.
+type EventsClient interface {
+	AddEventListener(listener chan<- *docker.APIEvents) error
+	RemoveEventListener(listener chan *docker.APIEvents) error
+	InspectContainer(id string) (*docker.Container, error)
+}
+
+// An EventWatcher subscribes to the Docker events stream and drains the
+// Registrar entries for containers that have gone away. This catches
+// containers whose shim was killed before it could deregister. It also
+// keeps the Registrar up to date with Docker's health checks.
+type EventWatcher struct {
+	registrar *envoyhttp.Registrar
+	client    EventsClient
+}
+
+// NewEventWatcher returns a correctly configured EventWatcher.
+func NewEventWatcher(registrar *envoyhttp.Registrar, client EventsClient) *EventWatcher {
+	return &EventWatcher{
+		registrar: registrar,
+		client:    client,
+	}
+}
+
+// Run processes Docker events until the context is cancelled. The Docker
+// client reconnects to the events stream on its own if it breaks.
+func (w *EventWatcher) Run(ctx context.Context) error {
+	events := make(chan *docker.APIEvents, 100)
+	err := w.client.AddEventListener(events)
+	if err != nil {
+		return err
+	}
+	defer w.client.RemoveEventListener(events)
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			w.HandleEvent(event)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// HandleEvent drains or evicts entries, or updates their health, in
+// response to a single Docker event.
+func (w *EventWatcher) HandleEvent(event *docker.APIEvents) {
+	switch event.Type {
+	case "container":
+		switch {
+		case event.Action == "die", event.Action == "stop", event.Action == "destroy":
+			// Draining first, or clearing an unhealthy container's health
+			// would put it back in service until the drain
+			w.drain(w.registrar.EntriesForContainer(event.Actor.ID), event.Action)
+			w.registrar.SetContainerHealth(event.Actor.ID, "")
+		case event.Action == "start":
+			w.handleStart(event.Actor.ID)
+		case strings.HasPrefix(event.Action, healthStatusAction):
+			health := strings.TrimSpace(strings.TrimPrefix(event.Action, healthStatusAction))
+			w.registrar.SetContainerHealth(event.Actor.ID, health)
+		}
+
+	case "network":
+		if event.Action == "disconnect" {
+			w.handleDisconnect(event.Actor.Attributes["container"])
+		}
+	}
+}
+
+// handleStart learns whether a container has a HEALTHCHECK when it starts.
+// Docker only sends health events when the health changes, and the shim
+// may register the container before the first one.
+func (w *EventWatcher) handleStart(containerID string) {
+	container, err := w.client.InspectContainer(containerID)
+	if err != nil {
+		log.Warnf("Unable to inspect container %s: %s", shortID(containerID), err)
+		return
+	}
+
+	// Docker says "none" when there's no HEALTHCHECK, or leaves it out
+	health := container.State.Health.Status
+	if health == "none" {
+		health = ""
+	}
+
+	w.registrar.SetContainerHealth(containerID, health)
+}
+
+// handleDisconnect evicts the entries for a container that no longer point
+// at one of its addresses. A container may still be reachable on its other
+// networks.
+func (w *EventWatcher) handleDisconnect(containerID string) {
+	entries := w.registrar.EntriesForContainer(containerID)
+	if len(entries) < 1 {
+		return
+	}
+
+	container, err := w.client.InspectContainer(containerID)
+	if err != nil {
+		// The container is already gone
+		w.evict(entries, "disconnect")
+		return
+	}
+
+	addrs := make(map[string]bool)
+	if container.NetworkSettings != nil {
+		for _, network := range container.NetworkSettings.Networks {
+			addrs[network.IPAddress] = true
+			addrs[network.GlobalIPv6Address] = true
+		}
+	}
+
+	stale := make(map[string]*envoyhttp.Entry)
+	for name, entry := range entries {
+		if !addrs[entry.BackendAddr.IP.String()] {
+			stale[name] = entry
+		}
+	}
+
+	w.evict(stale, "disconnect")
+}
+
+// drain drains entries whose container has stopped, so Envoy can finish
+// the requests in flight. Entries that are already draining are left to it.
+func (w *EventWatcher) drain(entries map[string]*envoyhttp.Entry, reason string) {
+	for name, entry := range entries {
+		if entry.IsDraining() {
+			continue
+		}
+
+		log.Warnf("Draining %s: container %s had event '%s' (backend %s)",
+			name, shortID(entry.ContainerID), reason, entry.BackendAddr)
+		w.registrar.DrainEntry(name)
+		evictions.WithLabelValues(reason).Inc()
+	}
+}
+
+// evict removes entries from the Registrar, logging and counting each one.
+func (w *EventWatcher) evict(entries map[string]*envoyhttp.Entry, reason string) {
+	for name, entry := range entries {
+		log.Warnf("Evicting %s: container %s had event '%s' (backend %s)",
+			name, shortID(entry.ContainerID), reason, entry.BackendAddr)
+		w.registrar.RemoveEntry(name)
+		evictions.WithLabelValues(reason).Inc()
+	}
+}
+
+// shortID returns the abbreviated container ID that Docker shows users
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+
+	return id
+}
@@ -0,0 +1,176 @@
+package dockerwatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/fsouza/go-dockerclient"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mockEventsClient struct {
+	container *docker.Container
+}
+
+func (c *mockEventsClient) AddEventListener(listener chan<- *docker.APIEvents) error {
+	return nil
+}
+
+func (c *mockEventsClient) RemoveEventListener(listener chan *docker.APIEvents) error {
+	return nil
+}
+
+func (c *mockEventsClient) InspectContainer(id string) (*docker.Container, error) {
+	if c.container == nil {
+		return nil, errors.New("intentional mock error")
+	}
+	return c.container, nil
+}
+
+func Test_HandleEvent(t *testing.T) {
+	Convey("HandleEvent()", t, func() {
+		registrar := envoyhttp.NewRegistrar()
+		client := &mockEventsClient{}
+		watcher := NewEventWatcher(registrar, client)
+
+		// Get some entries with container IDs in place
+		NewReconciler(registrar, &mockDockerClient{
+			containers: []docker.APIContainers{container1, container2},
+		}).Reconcile()
+
+		Convey("evicts entries when a container dies", func() {
+			before := testutil.ToFloat64(evictions.WithLabelValues("die"))
+
+			watcher.HandleEvent(&docker.APIEvents{
+				Type:   "container",
+				Action: "die",
+				Actor:  docker.APIActor{ID: "deadbeef0001"},
+			})
+
+			So(registrar.GetEntry("bede-dev-12345"), ShouldBeNil)
+			So(registrar.GetEntry("chretien-dev-23451"), ShouldNotBeNil)
+			So(testutil.ToFloat64(evictions.WithLabelValues("die")), ShouldEqual, before+1)
+		})
+
+		Convey("drains entries when a container stops", func() {
+			registrar.DrainTime = 1 * time.Minute
+
+			watcher.HandleEvent(&docker.APIEvents{
+				Type:   "container",
+				Action: "stop",
+				Actor:  docker.APIActor{ID: "deadbeef0001"},
+			})
+
+			So(registrar.GetEntry("bede-dev-12345").IsDraining(), ShouldBeTrue)
+
+			Convey("and the reconciler leaves them to it", func() {
+				NewReconciler(registrar, &mockDockerClient{
+					containers: []docker.APIContainers{container2},
+				}).Reconcile()
+
+				So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+			})
+		})
+
+		Convey("ignores other container events", func() {
+			watcher.HandleEvent(&docker.APIEvents{
+				Type:   "container",
+				Action: "start",
+				Actor:  docker.APIActor{ID: "deadbeef0001"},
+			})
+
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+		})
+
+		Convey("tracks the containers' Docker health", func() {
+			watcher.HandleEvent(&docker.APIEvents{
+				Type:   "container",
+				Action: "health_status: unhealthy",
+				Actor:  docker.APIActor{ID: "deadbeef0001"},
+			})
+
+			So(registrar.GetEntry("bede-dev-12345").IsHealthy(), ShouldBeFalse)
+			So(registrar.GetEntry("chretien-dev-23451").IsHealthy(), ShouldBeTrue)
+
+			watcher.HandleEvent(&docker.APIEvents{
+				Type:   "container",
+				Action: "health_status: healthy",
+				Actor:  docker.APIActor{ID: "deadbeef0001"},
+			})
+
+			So(registrar.GetEntry("bede-dev-12345").DockerHealth, ShouldEqual, envoyhttp.DockerHealthHealthy)
+		})
+
+		Convey("inspects the container's health when it starts", func() {
+			client.container = &docker.Container{
+				State: docker.State{Health: docker.Health{Status: "starting"}},
+			}
+			watcher.HandleEvent(&docker.APIEvents{
+				Type:   "container",
+				Action: "start",
+				Actor:  docker.APIActor{ID: "deadbeef0002"},
+			})
+
+			So(registrar.ContainerHealth("deadbeef0002"), ShouldEqual, envoyhttp.DockerHealthStarting)
+			So(registrar.GetEntry("chretien-dev-23451").IsHealthy(), ShouldBeFalse)
+		})
+
+		Convey("on network disconnect", func() {
+			event := &docker.APIEvents{
+				Type:   "network",
+				Action: "disconnect",
+				Actor: docker.APIActor{
+					ID:         "somenetwork",
+					Attributes: map[string]string{"container": "deadbeef0002"},
+				},
+			}
+
+			Convey("evicts entries no longer on one of the container's networks", func() {
+				client.container = &docker.Container{
+					NetworkSettings: &docker.NetworkSettings{
+						Networks: map[string]docker.ContainerNetwork{
+							"zzz": {IPAddress: "172.18.0.9"},
+						},
+					},
+				}
+				watcher.HandleEvent(event)
+
+				So(registrar.GetEntry("chretien-dev-23451"), ShouldBeNil)
+			})
+
+			Convey("keeps entries still on one of the container's networks", func() {
+				client.container = &docker.Container{
+					NetworkSettings: &docker.NetworkSettings{
+						Networks: map[string]docker.ContainerNetwork{
+							"backend": {IPAddress: "172.16.10.2"},
+						},
+					},
+				}
+				watcher.HandleEvent(event)
+
+				So(registrar.GetEntry("chretien-dev-23451"), ShouldNotBeNil)
+			})
+
+			Convey("evicts entries when the container is gone", func() {
+				watcher.HandleEvent(event)
+
+				So(registrar.GetEntry("chretien-dev-23451"), ShouldBeNil)
+			})
+		})
+	})
+}
+
+func Test_EventWatcherRun(t *testing.T) {
+	Convey("Run() returns when the context is cancelled", t, func() {
+		watcher := NewEventWatcher(envoyhttp.NewRegistrar(), &mockEventsClient{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		So(watcher.Run(ctx), ShouldBeNil)
+	})
+}
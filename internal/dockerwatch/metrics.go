@@ -0,0 +1,22 @@
+package dockerwatch
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// evictions counts the entries we removed because their container went
+	// away, labeled with what told us it was gone.
+	evictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "envoy_docker_shim",
+			Name:      "evictions_total",
+			Help:      "Registrar entries evicted because their container went away.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(evictions)
+}
@@ -0,0 +1,377 @@
+package dockerwatch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Labels looked up in Docker to identify the service and environment names
+	// as well as the proxy mode. These match the ones the shim uses.
+	ServiceNameLabel     = "ServiceName"
+	EnvironmentNameLabel = "EnvironmentName"
+	ProxyModeLabel       = "ProxyMode"
+	ServicePortLabel     = "ServicePort"
+
+	// The proxy mode that asks for UDP to be proxied by Envoy
+	UDPEnvoyProxyMode = "udp-envoy"
+
+	// DockerTimeout is how long we'll wait on any one call to Docker
+	DockerTimeout = 5 * time.Second
+
+	// The network that Docker publishes ports from by default
+	DefaultNetwork = "bridge"
+)
+
+// DockerClient is the subset of the go-dockerclient Client that we use. It
+// allows us to mock out Docker in tests.
+type DockerClient interface {
+	ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+}
+
+// NewDockerClient connects to Docker at the URL passed in, or using the
+// environment if the URL is empty.
+func NewDockerClient(socketUrl string) (*docker.Client, error) {
+	var client *docker.Client
+	var err error
+
+	if len(socketUrl) > 0 {
+		client, err = docker.NewClient(socketUrl)
+	} else {
+		client, err = docker.NewClientFromEnv()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't connect to Docker: %s", err)
+	}
+
+	return client, nil
+}
+
+// A Reconciler makes the Registrar match the containers that are actually
+// running in Docker. It adds entries for published ports the Registrar
+// doesn't know about and evicts entries whose container has gone away.
+type Reconciler struct {
+	registrar *envoyhttp.Registrar
+	client    DockerClient
+	Interval  time.Duration
+}
+
+// NewReconciler returns a correctly configured Reconciler.
+func NewReconciler(registrar *envoyhttp.Registrar, client DockerClient) *Reconciler {
+	return &Reconciler{
+		registrar: registrar,
+		client:    client,
+		Interval:  1 * time.Minute,
+	}
+}
+
+// Run reconciles every Interval until the context is cancelled. Call
+// Reconcile first if you need to be in sync before Run starts.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		err := r.Reconcile()
+		if err != nil {
+			log.Errorf("Unable to reconcile with Docker: %s", err)
+		}
+	}
+}
+
+// Reconcile lists the running containers and brings the Registrar in line
+// with them.
+func (r *Reconciler) Reconcile() error {
+	// Only entries that existed before we asked Docker are candidates for
+	// eviction. Anything registered by a shim while we wait is newer than
+	// what Docker told us.
+	known := make(map[string]*envoyhttp.Entry)
+	r.registrar.EachEntry(func(name string, entry *envoyhttp.Entry) error {
+		known[envoyhttp.ListenerKey(entry)] = entry
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), DockerTimeout)
+	defer cancel()
+
+	containers, err := r.client.ListContainers(docker.ListContainersOptions{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("Can't list containers: %s", err)
+	}
+
+	running := make(map[string]bool)
+	ids := make(map[string]bool)
+	found := make(map[string]*envoyhttp.Entry)
+	addrs := make(map[string][]net.IP) // The container's IPs, by ListenerKey
+	var keys []string
+	for i := range containers {
+		// Before we add any entries, so they get the right health
+		ids[containers[i].ID] = true
+		r.registrar.SetContainerHealth(containers[i].ID, statusHealth(containers[i].Status))
+
+		for _, entry := range EntriesFromContainer(&containers[i]) {
+			for _, ip := range containerIPs(&containers[i]) {
+				running[(&net.TCPAddr{IP: ip, Port: entry.BackendAddr.Port}).String()] = true
+			}
+
+			// Docker may publish the same port more than once, e.g. on
+			// 0.0.0.0 and ::, but these share a single entry.
+			key := envoyhttp.ListenerKey(entry)
+			if existing, ok := found[key]; ok {
+				if merged := envoyhttp.MergeDualStack(existing, entry); merged != nil {
+					found[key] = merged
+				}
+				continue
+			}
+			found[key] = entry
+			addrs[key] = containerIPs(&containers[i])
+			keys = append(keys, key)
+		}
+	}
+
+	r.registrar.PruneContainerHealth(ids)
+
+	// Evict first, so the listeners of containers that have been replaced are
+	// free for their replacements
+	for _, entry := range known {
+		// Draining entries are removed when they're done
+		if entry.IsDraining() {
+			continue
+		}
+
+		var reason string
+		switch {
+		case entry.HasContainer() && !ids[entry.ContainerID]:
+			reason = fmt.Sprintf("%s is not running", entry.ContainerString())
+		case !running[entry.BackendAddr.String()]:
+			reason = fmt.Sprintf("no running container for %s", entry.BackendAddr)
+		default:
+			continue
+		}
+
+		// A shim may have replaced or drained the entry since we looked, and
+		// what Docker told us says nothing about the new one.
+		evicted := r.registrar.RemoveEntryIf(entry.Name, func(current *envoyhttp.Entry) bool {
+			return !current.IsDraining() &&
+				current.ContainerID == entry.ContainerID &&
+				current.BackendAddr.String() == entry.BackendAddr.String()
+		})
+		if !evicted {
+			continue
+		}
+
+		log.Warnf("Reconciling: evicted %s, %s", entry.Name, reason)
+		evictions.WithLabelValues("reconcile").Inc()
+	}
+
+	// These go through the same checks as the shims' registrations, so we
+	// never take a listener from another container or undo a merge.
+	for _, key := range keys {
+		entry := found[key]
+		existing, ok := known[key]
+
+		// The shim knows which of the container's networks it forwards to,
+		// where we only guess, so we keep its backend if it's this container.
+		if ok && existing.BackendAddr.Port == entry.BackendAddr.Port && hasIP(addrs[key], existing.BackendAddr.IP) {
+			kept := *entry
+			kept.BackendAddr = existing.BackendAddr
+			entry = &kept
+		}
+
+		if ok && (existing.IsDraining() || existing.SameAs(entry)) {
+			continue
+		}
+
+		log.Infof("Reconciling: adding %s from %s", key, entry.ContainerString())
+		_, err := r.registrar.RegisterEntry(entry)
+		if err != nil {
+			log.Warnf("Reconciling: not adding %s: %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+// containerIP guesses the IP address that published ports are forwarded to,
+// for containers the shim hasn't told us about. That's the default bridge if
+// the container is on it, otherwise the first network (by name) that has an
+// address.
+func containerIP(container *docker.APIContainers) net.IP {
+	networks := container.Networks.Networks
+	if network, ok := networks[DefaultNetwork]; ok && len(network.IPAddress) > 0 {
+		return net.ParseIP(network.IPAddress)
+	}
+
+	var names []string
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if len(networks[name].IPAddress) > 0 {
+			return net.ParseIP(networks[name].IPAddress)
+		}
+	}
+
+	return nil
+}
+
+// containerIPs returns all the addresses the container has, in both
+// families, so we can tell if an entry points at it.
+func containerIPs(container *docker.APIContainers) []net.IP {
+	var ips []net.IP
+	for _, network := range container.Networks.Networks {
+		for _, addr := range []string{network.IPAddress, network.GlobalIPv6Address} {
+			if ip := net.ParseIP(addr); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+	}
+
+	return ips
+}
+
+// hasIP tells us if the IP is one of the ones passed in.
+func hasIP(ips []net.IP, ip net.IP) bool {
+	for _, candidate := range ips {
+		if candidate.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containerName returns the name Docker shows for the container, without
+// the leading slash.
+func containerName(container *docker.APIContainers) string {
+	if len(container.Names) < 1 {
+		return ""
+	}
+
+	return strings.TrimPrefix(container.Names[0], "/")
+}
+
+// statusHealth picks the health out of the status that Docker lists a
+// container with, like "Up 5 minutes (healthy)". It's empty if the
+// container has no HEALTHCHECK.
+func statusHealth(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(health: starting)"):
+		return envoyhttp.DockerHealthStarting
+	case strings.HasSuffix(status, "(healthy)"):
+		return envoyhttp.DockerHealthHealthy
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return envoyhttp.DockerHealthUnhealthy
+	}
+
+	return ""
+}
+
+// servicePort returns the port from the container's ServicePort label, or
+// 0 if there isn't a valid one. The shim ignores invalid ones too.
+func servicePort(container *docker.APIContainers) int {
+	value, ok := container.Labels[ServicePortLabel]
+	if !ok {
+		return 0
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0
+	}
+
+	return port
+}
+
+// EntriesFromContainer returns a Registrar entry for each TCP port that
+// the container publishes on the host. UDP ports are included when the
+// container asks for Envoy to proxy them. Containers with invalid tuning,
+// VirtualHosts, or ServerNames labels are skipped.
+func EntriesFromContainer(container *docker.APIContainers) []*envoyhttp.Entry {
+	backendIP := containerIP(container)
+	if backendIP == nil {
+		return nil
+	}
+
+	proxyMode := container.Labels[ProxyModeLabel]
+	if len(proxyMode) < 1 {
+		proxyMode = "http"
+	}
+	proxyMode = strings.ToLower(proxyMode)
+
+	// The shim can't register the container either, so there is nothing
+	// to reconcile
+	tuning, err := envoyhttp.ParseTuning(container.Labels)
+	if err != nil {
+		log.Errorf("Ignoring container %s: %s", container.ID, err)
+		return nil
+	}
+
+	_, err = envoyhttp.ParseVirtualHosts(container.Labels[envoyhttp.VirtualHostsLabel])
+	if err != nil {
+		log.Errorf("Ignoring container %s: %s", container.ID, err)
+		return nil
+	}
+
+	_, err = envoyhttp.ParseServerNames(container.Labels[envoyhttp.ServerNamesLabel])
+	if err != nil {
+		log.Errorf("Ignoring container %s: %s", container.ID, err)
+		return nil
+	}
+
+	sharedPort := servicePort(container)
+
+	var entries []*envoyhttp.Entry
+	for _, port := range container.Ports {
+		if port.PublicPort == 0 {
+			continue
+		}
+
+		switch {
+		case port.Type == envoyhttp.ProtocolTCP:
+		case port.Type == envoyhttp.ProtocolUDP && proxyMode == UDPEnvoyProxyMode:
+		default:
+			continue
+		}
+
+		frontendIP := net.ParseIP(port.IP)
+		if frontendIP == nil {
+			frontendIP = net.IPv4zero
+		}
+
+		entries = append(entries, &envoyhttp.Entry{
+			FrontendAddr:    &net.TCPAddr{IP: frontendIP, Port: int(port.PublicPort)},
+			BackendAddr:     &net.TCPAddr{IP: backendIP, Port: int(port.PrivatePort)},
+			ServiceName:     container.Labels[ServiceNameLabel],
+			EnvironmentName: container.Labels[EnvironmentNameLabel],
+			ProxyMode:       proxyMode,
+			Protocol:        port.Type,
+			ContainerID:     container.ID,
+			ContainerName:   containerName(container),
+			Image:           container.Image,
+			Labels:          container.Labels,
+			Tuning:          tuning,
+			ServicePort:     sharedPort,
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,358 @@
+package dockerwatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	"github.com/fsouza/go-dockerclient"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mockDockerClient struct {
+	containers []docker.APIContainers
+	err        error
+	listing    func() // Called while Docker is being asked, when set
+}
+
+func (c *mockDockerClient) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	if c.listing != nil {
+		c.listing()
+	}
+	return c.containers, c.err
+}
+
+var (
+	container1 = docker.APIContainers{
+		ID:    "deadbeef0001",
+		Names: []string{"/bede-1"},
+		Image: "nginx:1.19",
+		Ports: []docker.APIPort{
+			{PrivatePort: 80, PublicPort: 12345, Type: "tcp", IP: "0.0.0.0"},
+			{PrivatePort: 80, PublicPort: 12345, Type: "tcp", IP: "::"},
+			{PrivatePort: 53, PublicPort: 53, Type: "udp", IP: "0.0.0.0"},
+			{PrivatePort: 8080, Type: "tcp"}, // Not published
+		},
+		Labels: map[string]string{
+			ServiceNameLabel:     "bede",
+			EnvironmentNameLabel: "dev",
+			ProxyModeLabel:       "TCP",
+		},
+		Networks: docker.NetworkList{
+			Networks: map[string]docker.ContainerNetwork{
+				"bridge": {IPAddress: "172.16.10.1", GlobalIPv6Address: "fd00::1"},
+			},
+		},
+	}
+
+	container2 = docker.APIContainers{
+		ID: "deadbeef0002",
+		Ports: []docker.APIPort{
+			{PrivatePort: 8080, PublicPort: 23451, Type: "tcp", IP: "0.0.0.0"},
+		},
+		Labels: map[string]string{
+			ServiceNameLabel:     "chretien",
+			EnvironmentNameLabel: "dev",
+		},
+		Networks: docker.NetworkList{
+			Networks: map[string]docker.ContainerNetwork{
+				"zzz":     {IPAddress: "172.18.0.9"},
+				"backend": {IPAddress: "172.16.10.2"},
+			},
+		},
+	}
+)
+
+func Test_EntriesFromContainer(t *testing.T) {
+	Convey("EntriesFromContainer()", t, func() {
+		Convey("returns an entry for each published TCP port", func() {
+			entries := EntriesFromContainer(&container1)
+
+			So(len(entries), ShouldEqual, 2)
+			So(entries[0].FrontendAddr.String(), ShouldEqual, "0.0.0.0:12345")
+			So(entries[0].BackendAddr.String(), ShouldEqual, "172.16.10.1:80")
+			So(entries[0].ContainerID, ShouldEqual, "deadbeef0001")
+			So(entries[0].ContainerName, ShouldEqual, "bede-1")
+			So(entries[0].Image, ShouldEqual, "nginx:1.19")
+			So(entries[0].Labels[ServiceNameLabel], ShouldEqual, "bede")
+		})
+
+		Convey("takes the settings from the labels", func() {
+			entry := EntriesFromContainer(&container1)[0]
+
+			So(entry.ServiceName, ShouldEqual, "bede")
+			So(entry.EnvironmentName, ShouldEqual, "dev")
+			So(entry.ProxyMode, ShouldEqual, "tcp")
+		})
+
+		Convey("includes published UDP ports in udp-envoy mode", func() {
+			container := container1
+			container.Labels = map[string]string{ProxyModeLabel: "udp-envoy"}
+
+			entries := EntriesFromContainer(&container)
+
+			So(len(entries), ShouldEqual, 3)
+			So(entries[2].FrontendAddr.Port, ShouldEqual, 53)
+			So(entries[2].Protocol, ShouldEqual, "udp")
+			So(entries[2].IsUDP(), ShouldBeTrue)
+		})
+
+		Convey("defaults to HTTP mode", func() {
+			So(EntriesFromContainer(&container2)[0].ProxyMode, ShouldEqual, "http")
+		})
+
+		Convey("picks a network consistently when not on the bridge", func() {
+			entry := EntriesFromContainer(&container2)[0]
+			So(entry.BackendAddr.IP.String(), ShouldEqual, "172.16.10.2")
+		})
+
+		Convey("takes the tuning from the labels", func() {
+			container := container2
+			container.Labels = map[string]string{"EnvoyLBPolicy": "least_request"}
+
+			So(EntriesFromContainer(&container)[0].Tuning.LBPolicy, ShouldEqual, "least_request")
+		})
+
+		Convey("takes the ServicePort from the labels", func() {
+			container := container2
+			container.Labels = map[string]string{ServicePortLabel: "8080"}
+			So(EntriesFromContainer(&container)[0].ServicePort, ShouldEqual, 8080)
+
+			container.Labels = map[string]string{ServicePortLabel: "eighty"}
+			So(EntriesFromContainer(&container)[0].ServicePort, ShouldEqual, 0)
+		})
+
+		Convey("skips the container when the tuning is invalid", func() {
+			container := container2
+			container.Labels = map[string]string{"EnvoyMaxRequests": "lots"}
+
+			So(EntriesFromContainer(&container), ShouldBeEmpty)
+		})
+
+		Convey("skips the container when the virtual hosts or server names are invalid", func() {
+			container := container2
+			container.Labels = map[string]string{"VirtualHosts": "chretien.example.com/"}
+			So(EntriesFromContainer(&container), ShouldBeEmpty)
+
+			container.Labels = map[string]string{"ServerNames": "chretien.example.com:443"}
+			So(EntriesFromContainer(&container), ShouldBeEmpty)
+		})
+
+		Convey("returns nothing when the container has no address", func() {
+			So(EntriesFromContainer(&docker.APIContainers{}), ShouldBeEmpty)
+		})
+	})
+}
+
+func Test_Reconcile(t *testing.T) {
+	Convey("Reconcile()", t, func() {
+		registrar := envoyhttp.NewRegistrar()
+		client := &mockDockerClient{
+			containers: []docker.APIContainers{container1, container2},
+		}
+		reconciler := NewReconciler(registrar, client)
+
+		Convey("adds entries for running containers", func() {
+			So(reconciler.Reconcile(), ShouldBeNil)
+
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+			So(registrar.GetEntry("chretien-dev-23451"), ShouldNotBeNil)
+		})
+
+		Convey("merges a port published on both 0.0.0.0 and :: into one entry", func() {
+			So(reconciler.Reconcile(), ShouldBeNil)
+
+			entry := registrar.GetEntry("bede-dev-12345")
+			So(entry.DualStack, ShouldBeTrue)
+			So(entry.FrontendAddr.String(), ShouldEqual, "[::]:12345")
+			So(entry.BackendAddr.String(), ShouldEqual, "172.16.10.1:80")
+
+			So(registrar.GetEntry("chretien-dev-23451").DualStack, ShouldBeFalse)
+		})
+
+		Convey("keeps entries pointing at the container's IPv6 address", func() {
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "2001:db8::1",
+				FrontendPort:    8443,
+				BackendAddr:     "fd00::1",
+				BackendPort:     80,
+				EnvironmentName: "dev",
+				ServiceName:     "hakluyt",
+				ProxyMode:       "tcp",
+			})
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("hakluyt-dev-8443"), ShouldNotBeNil)
+		})
+
+		Convey("evicts entries without a running container", func() {
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    23555,
+				BackendAddr:     "172.16.10.3",
+				BackendPort:     9000,
+				EnvironmentName: "dev",
+				ServiceName:     "hakluyt",
+				ProxyMode:       "http",
+			})
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("hakluyt-dev-23555"), ShouldBeNil)
+		})
+
+		Convey("doesn't evict an entry that was replaced while listing containers", func() {
+			request := &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    23555,
+				BackendAddr:     "172.16.10.3",
+				BackendPort:     9000,
+				EnvironmentName: "dev",
+				ServiceName:     "hakluyt",
+				ProxyMode:       "http",
+				ContainerId:     "deadbeef0003",
+			}
+			registrar.Register(context.Background(), request)
+
+			// The container is replaced while we wait on Docker
+			client.listing = func() {
+				gone := *request
+				gone.Action = shimrpc.RegistrarRequest_DEREGISTER
+				registrar.Register(context.Background(), &gone)
+
+				replacement := *request
+				replacement.BackendAddr = "172.16.10.4"
+				replacement.ContainerId = "deadbeef0004"
+				registrar.Register(context.Background(), &replacement)
+			}
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("hakluyt-dev-23555"), ShouldNotBeNil)
+			So(registrar.GetEntry("hakluyt-dev-23555").ContainerID, ShouldEqual, "deadbeef0004")
+		})
+
+		Convey("keeps entries registered by the shim and learns their container", func() {
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    23451,
+				BackendAddr:     "172.16.10.2",
+				BackendPort:     8080,
+				EnvironmentName: "dev",
+				ServiceName:     "chretien",
+				ProxyMode:       "http",
+			})
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("chretien-dev-23451").ContainerID, ShouldEqual, "deadbeef0002")
+		})
+
+		Convey("replaces the entry of a container that has gone away", func() {
+			// Docker gave the new container the old one's IP
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    12345,
+				BackendAddr:     "172.16.10.1",
+				BackendPort:     80,
+				EnvironmentName: "dev",
+				ServiceName:     "bede",
+				ProxyMode:       "tcp",
+				ContainerId:     "deadbeef0009",
+			})
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345").ContainerID, ShouldEqual, "deadbeef0001")
+		})
+
+		Convey("doesn't take a listener from another running container", func() {
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    12345,
+				BackendAddr:     "172.16.10.2",
+				BackendPort:     8080,
+				EnvironmentName: "dev",
+				ServiceName:     "chretien",
+				ProxyMode:       "http",
+				ContainerId:     "deadbeef0002",
+			})
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetListener("tcp/*:12345").ContainerID, ShouldEqual, "deadbeef0002")
+		})
+
+		Convey("leaves draining entries to finish draining", func() {
+			reconciler.Reconcile()
+			registrar.DrainTime = time.Minute
+			registrar.DrainEntry("bede-dev-12345")
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345").IsDraining(), ShouldBeTrue)
+		})
+
+		Convey("keeps the shim's backend on a container with several networks", func() {
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    23451,
+				BackendAddr:     "172.18.0.9",
+				BackendPort:     8080,
+				EnvironmentName: "dev",
+				ServiceName:     "chretien",
+				ProxyMode:       "http",
+				ContainerId:     "deadbeef0002",
+				Labels:          container2.Labels,
+			})
+			changes := registrar.Listen()
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("chretien-dev-23451").BackendAddr.String(), ShouldEqual, "172.18.0.9:8080")
+			So(len(changes), ShouldEqual, 1) // Only bede was added
+		})
+
+		Convey("leaves the Registrar alone when Docker fails", func() {
+			reconciler.Reconcile()
+			client.err = errors.New("intentional mock error")
+			client.containers = nil
+
+			So(reconciler.Reconcile(), ShouldNotBeNil)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+		})
+
+		Convey("learns the containers' Docker health", func() {
+			healthy := container2
+			healthy.Status = "Up 5 minutes (healthy)"
+			starting := container1
+			starting.Status = "Up 2 seconds (health: starting)"
+			client.containers = []docker.APIContainers{starting, healthy}
+
+			So(reconciler.Reconcile(), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345").DockerHealth, ShouldEqual, envoyhttp.DockerHealthStarting)
+			So(registrar.GetEntry("chretien-dev-23451").DockerHealth, ShouldEqual, envoyhttp.DockerHealthHealthy)
+
+			Convey("and forgets it when they're gone", func() {
+				client.containers = []docker.APIContainers{healthy}
+
+				So(reconciler.Reconcile(), ShouldBeNil)
+				So(registrar.ContainerHealth("deadbeef0001"), ShouldBeEmpty)
+				So(registrar.ContainerHealth("deadbeef0002"), ShouldEqual, envoyhttp.DockerHealthHealthy)
+			})
+		})
+
+		Convey("does not churn the Registrar when nothing changed", func() {
+			reconciler.Reconcile()
+			changes := registrar.Listen()
+			reconciler.Reconcile()
+
+			So(len(changes), ShouldEqual, 0)
+		})
+	})
+}
+
+func Test_statusHealth(t *testing.T) {
+	Convey("statusHealth()", t, func() {
+		So(statusHealth("Up 2 seconds (health: starting)"), ShouldEqual, envoyhttp.DockerHealthStarting)
+		So(statusHealth("Up 5 minutes (healthy)"), ShouldEqual, envoyhttp.DockerHealthHealthy)
+		So(statusHealth("Up 5 minutes (unhealthy)"), ShouldEqual, envoyhttp.DockerHealthUnhealthy)
+		So(statusHealth("Up 5 minutes"), ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,108 @@
+package envoyhttp
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Containers whose image has a Docker HEALTHCHECK only get traffic while
+// Docker says they are healthy. Docker tells the dockerwatch package, which
+// tells us. We don't persist any of this, because the startup reconciliation
+// learns it again from Docker before we serve anything.
+
+const (
+	// Docker's health states. Containers without a HEALTHCHECK have none.
+	DockerHealthStarting  = "starting"
+	DockerHealthHealthy   = "healthy"
+	DockerHealthUnhealthy = "unhealthy"
+)
+
+// HasDockerHealth tells us if Docker health checks the entry's container.
+func (e *Entry) HasDockerHealth() bool {
+	return len(e.DockerHealth) > 0
+}
+
+// IsHealthy tells us if Envoy should send traffic to the entry's backend.
+// That's unless Docker has health checks for the container which haven't
+// passed yet, or have since failed.
+func (e *Entry) IsHealthy() bool {
+	return !e.HasDockerHealth() || e.DockerHealth == DockerHealthHealthy
+}
+
+// groupHealth returns the Docker health for a ServiceGroup's shared
+// cluster. It's as healthy as its healthiest replica.
+func groupHealth(members []*Entry) string {
+	var health string
+	for _, member := range members {
+		if member.DockerHealth == DockerHealthHealthy {
+			return DockerHealthHealthy
+		}
+
+		if len(health) < 1 {
+			health = member.DockerHealth
+		}
+	}
+
+	return health
+}
+
+// ContainerHealth returns the Docker health of the container with the ID
+// passed in, or an empty string if it has none or we don't know.
+func (r *Registrar) ContainerHealth(containerID string) string {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.containerHealth[containerID]
+}
+
+// SetContainerHealth records the Docker health of a container and updates
+// its entries. An empty health forgets the container.
+func (r *Registrar) SetContainerHealth(containerID, health string) {
+	if len(containerID) < 1 {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.containerHealth[containerID] == health {
+		return
+	}
+
+	if len(health) > 0 {
+		r.containerHealth[containerID] = health
+	} else {
+		delete(r.containerHealth, containerID)
+	}
+
+	var changed bool
+	for key, entry := range r.entries {
+		if entry.ContainerID != containerID || entry.DockerHealth == health {
+			continue
+		}
+
+		log.Infof("Docker health of %s is now '%s'", entry.Name, health)
+
+		// Entries are shared with whoever looked them up, so we replace them
+		updated := *entry
+		updated.DockerHealth = health
+		r.entries[key] = &updated
+		changed = true
+	}
+
+	if changed {
+		r.notifyListeners()
+	}
+}
+
+// PruneContainerHealth forgets the Docker health of all the containers but
+// the ones passed in. Their entries are left alone.
+func (r *Registrar) PruneContainerHealth(keep map[string]bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	for containerID := range r.containerHealth {
+		if !keep[containerID] {
+			delete(r.containerHealth, containerID)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package envoyhttp
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_SetContainerHealth(t *testing.T) {
+	Convey("SetContainerHealth()", t, func() {
+		registrar := NewRegistrar()
+		registrar.Register(context.Background(), req1)
+		registrar.Register(context.Background(), req2)
+
+		Convey("leaves entries without a HEALTHCHECK healthy", func() {
+			entry := registrar.GetEntry("chretien-dev-23451")
+
+			So(entry.HasDockerHealth(), ShouldBeFalse)
+			So(entry.IsHealthy(), ShouldBeTrue)
+		})
+
+		Convey("updates the container's entries and tells the listeners", func() {
+			changes := registrar.Listen()
+			before := registrar.GetEntry("chretien-dev-23451")
+
+			registrar.SetContainerHealth("deadbeef0002", DockerHealthStarting)
+
+			entry := registrar.GetEntry("chretien-dev-23451")
+			So(entry.DockerHealth, ShouldEqual, DockerHealthStarting)
+			So(entry.IsHealthy(), ShouldBeFalse)
+			So(before.DockerHealth, ShouldBeEmpty)
+			So(len(changes), ShouldEqual, 1)
+
+			registrar.SetContainerHealth("deadbeef0002", DockerHealthHealthy)
+			So(registrar.GetEntry("chretien-dev-23451").IsHealthy(), ShouldBeTrue)
+		})
+
+		Convey("doesn't tell the listeners when nothing changed", func() {
+			registrar.SetContainerHealth("deadbeef0002", DockerHealthHealthy)
+			changes := registrar.Listen()
+			registrar.SetContainerHealth("deadbeef0002", DockerHealthHealthy)
+
+			So(len(changes), ShouldEqual, 0)
+		})
+
+		Convey("applies to containers that register later", func() {
+			registrar.SetContainerHealth("deadbeef0002", "")
+			registrar.RemoveEntry("chretien-dev-23451")
+			registrar.SetContainerHealth("deadbeef0002", DockerHealthUnhealthy)
+			registrar.Register(context.Background(), req2)
+
+			So(registrar.GetEntry("chretien-dev-23451").IsHealthy(), ShouldBeFalse)
+		})
+
+		Convey("leaves unhealthy hosts out of SDS", func() {
+			registrar.SetContainerHealth("deadbeef0002", DockerHealthUnhealthy)
+
+			api := NewEnvoyApi(registrar)
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/registration/chretien-dev-23451", nil)
+			api.registrationHandler(recorder, req, map[string]string{"service": "chretien-dev-23451"})
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `"hosts":[]`)
+		})
+	})
+}
+
+func Test_groupHealth(t *testing.T) {
+	Convey("groupHealth() is as healthy as the healthiest replica", t, func() {
+		So(groupHealth([]*Entry{{}, {}}), ShouldBeEmpty)
+		So(groupHealth([]*Entry{{}, {DockerHealth: DockerHealthStarting}}), ShouldEqual, DockerHealthStarting)
+		So(groupHealth([]*Entry{
+			{DockerHealth: DockerHealthUnhealthy},
+			{DockerHealth: DockerHealthHealthy},
+		}), ShouldEqual, DockerHealthHealthy)
+	})
+}
@@ -0,0 +1,81 @@
+package envoyhttp
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// When a container goes away we don't remove its entry straight away.
+// Instead it drains for DrainTime: Envoy keeps the listener, so in-flight
+// requests can finish, but the endpoint is marked DRAINING so it gets no
+// new ones. Then the entry is removed, and Envoy drains the listener itself.
+
+// IsDraining tells us if the entry is on its way out. Anything can replace
+// it on its listener.
+func (e *Entry) IsDraining() bool {
+	return e.Draining
+}
+
+// DrainEntry takes the named entry out of service and removes it once it has
+// drained. Without a DrainTime it is removed straight away. Draining it again
+// doesn't extend the drain.
+func (r *Registrar) DrainEntry(name string) {
+	if r.DrainTime <= 0 {
+		r.RemoveEntry(name)
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	key, ok := r.services[name]
+	if !ok || r.entries[key].Draining {
+		return
+	}
+
+	log.Infof("Draining %s for %s", name, r.DrainTime)
+
+	// Entries are shared with whoever looked them up, so we replace it
+	draining := *r.entries[key]
+	draining.Draining = true
+	r.entries[key] = &draining
+
+	// It's going regardless of the shim now
+	delete(r.leases, name)
+	r.drains[name] = time.Now().Add(r.DrainTime)
+
+	// And regardless of a restart, which would lose track of the drain
+	if r.store != nil {
+		if err := r.store.Delete(name); err != nil {
+			log.Errorf("Unable to persist removal of %s: %s", name, err)
+		}
+	}
+
+	r.notifyListeners()
+}
+
+// ExpireDrains removes all the entries that finished draining before the
+// time passed in.
+func (r *Registrar) ExpireDrains(now time.Time) {
+	var drained []string
+
+	r.RLock()
+	for name, until := range r.drains {
+		if now.After(until) {
+			drained = append(drained, name)
+		}
+	}
+	r.RUnlock()
+
+	for _, name := range drained {
+		// Unless it was registered again in the meantime
+		removed := r.RemoveEntryIf(name, func(entry *Entry) bool {
+			until, ok := r.drains[name]
+			return entry.IsDraining() && ok && now.After(until)
+		})
+		if removed {
+			log.Infof("Finished draining %s", name)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package envoyhttp
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_DrainEntry(t *testing.T) {
+	Convey("Draining entries", t, func() {
+		registrar := NewRegistrar()
+		registrar.DrainTime = 10 * time.Second
+		registrar.Register(context.Background(), req1)
+		registrar.Register(context.Background(), req2)
+
+		deregister := func(req *shimrpc.RegistrarRequest) {
+			deregistration := *req
+			deregistration.Action = shimrpc.RegistrarRequest_DEREGISTER
+			registrar.Register(context.Background(), &deregistration)
+		}
+
+		Convey("keeps the entry until it has drained", func() {
+			changes := registrar.Listen()
+			deregister(req2)
+
+			entry := registrar.GetEntry("chretien-dev-23451")
+			So(entry, ShouldNotBeNil)
+			So(entry.IsDraining(), ShouldBeTrue)
+			So(len(changes), ShouldEqual, 1)
+
+			registrar.ExpireDrains(time.Now().Add(5 * time.Second))
+			So(registrar.GetEntry("chretien-dev-23451"), ShouldNotBeNil)
+
+			registrar.ExpireDrains(time.Now().Add(11 * time.Second))
+			So(registrar.GetEntry("chretien-dev-23451"), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+		})
+
+		Convey("doesn't extend the drain", func() {
+			deregister(req2)
+			registrar.DrainEntry("chretien-dev-23451")
+
+			So(len(registrar.drains), ShouldEqual, 1)
+			So(registrar.drains["chretien-dev-23451"], ShouldHappenBefore, time.Now().Add(10*time.Second))
+		})
+
+		Convey("removes the entry straight away without a DrainTime", func() {
+			registrar.DrainTime = 0
+			deregister(req2)
+
+			So(registrar.GetEntry("chretien-dev-23451"), ShouldBeNil)
+		})
+
+		Convey("leaves the host out of SDS", func() {
+			deregister(req2)
+
+			api := NewEnvoyApi(registrar)
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/registration/chretien-dev-23451", nil)
+			api.registrationHandler(recorder, req, map[string]string{"service": "chretien-dev-23451"})
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `"hosts":[]`)
+		})
+
+		Convey("lets another container take over the listener", func() {
+			deregister(req2)
+
+			replacement := *req2
+			replacement.ContainerId = "deadbeef0003"
+			_, err := registrar.Register(context.Background(), &replacement)
+			So(err, ShouldBeNil)
+
+			entry := registrar.GetEntry("chretien-dev-23451")
+			So(entry.IsDraining(), ShouldBeFalse)
+			So(entry.ContainerID, ShouldEqual, "deadbeef0003")
+
+			registrar.ExpireDrains(time.Now().Add(11 * time.Second))
+			So(registrar.GetEntry("chretien-dev-23451"), ShouldNotBeNil)
+		})
+
+		Convey("lets the same container register again", func() {
+			deregister(req2)
+			registrar.Register(context.Background(), req2)
+
+			So(registrar.GetEntry("chretien-dev-23451").IsDraining(), ShouldBeFalse)
+		})
+	})
+}
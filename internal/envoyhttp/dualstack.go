@@ -0,0 +1,147 @@
+package envoyhttp
+
+import (
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// When Docker has IPv6 enabled, publishing a port without a host IP makes
+// two registrations: one on 0.0.0.0 and one on ::. These have the same
+// ListenerKey, so rather than one replacing the other, we merge them into a
+// single DualStack entry that listens on :: and accepts IPv4 as well.
+
+// isWildcardV4 tells us if the IP is 0.0.0.0
+func isWildcardV4(ip net.IP) bool {
+	return ip.To4() != nil && ip.IsUnspecified()
+}
+
+// isWildcardV6 tells us if the IP is ::
+func isWildcardV6(ip net.IP) bool {
+	return ip.To4() == nil && ip.IsUnspecified()
+}
+
+// MergeDualStack returns the DualStack entry that covers both of the
+// entries passed in, when they are the IPv4 and IPv6 wildcard registrations
+// of the same port. Otherwise it returns nil.
+func MergeDualStack(existing, entry *Entry) *Entry {
+	if existing == nil || ListenerKey(existing) != ListenerKey(entry) {
+		return nil
+	}
+
+	oldIP, newIP := existing.FrontendAddr.IP, entry.FrontendAddr.IP
+	switch {
+	case existing.DualStack && newIP.IsUnspecified():
+	case isWildcardV4(oldIP) && isWildcardV6(newIP):
+	case isWildcardV6(oldIP) && isWildcardV4(newIP):
+	default:
+		return nil
+	}
+
+	merged := *entry
+	merged.FrontendAddr = &net.TCPAddr{IP: net.IPv6unspecified, Port: entry.FrontendAddr.Port}
+	merged.DualStack = true
+
+	// Envoy connects to the container on its own, so the backend family
+	// doesn't need to match. Containers always have IPv4 but may not have
+	// IPv6, so we prefer that.
+	if existing.BackendAddr.IP.To4() != nil && entry.BackendAddr.IP.To4() == nil {
+		merged.BackendAddr = existing.BackendAddr
+	}
+
+	return &merged
+}
+
+// splitDualStack returns what is left of a DualStack entry when the
+// registration passed in goes away. It returns nil if nothing is left.
+func splitDualStack(existing, entry *Entry) *Entry {
+	if !existing.DualStack {
+		return nil
+	}
+
+	remaining := *existing
+	remaining.DualStack = false
+
+	switch {
+	case isWildcardV4(entry.FrontendAddr.IP):
+		remaining.FrontendAddr = &net.TCPAddr{IP: net.IPv6unspecified, Port: existing.FrontendAddr.Port}
+	case isWildcardV6(entry.FrontendAddr.IP):
+		remaining.FrontendAddr = &net.TCPAddr{IP: net.IPv4zero, Port: existing.FrontendAddr.Port}
+	default:
+		return nil
+	}
+
+	return &remaining
+}
+
+// RegisterEntry adds an entry registered by a shim, merging it with the
+// other half of a dual stack registration if there is one. The registrar
+// is only touched if something changed. Returns the entry's name, or an
+// error if another container already has the listener, or it is reserved
+// for a shared listener. The lookup and the change happen under one lock, so
+// two containers racing for a listener can't both get it.
+func (r *Registrar) RegisterEntry(entry *Entry) (string, error) {
+	key := ListenerKey(entry)
+
+	if err := r.checkShared(entry); err != nil {
+		return "", err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	existing := r.entries[key]
+
+	// A draining entry is on its way out, so this replaces it whatever it is
+	if existing != nil && existing.IsDraining() {
+		existing = nil
+	}
+
+	if existing != nil && existing.HasContainer() && entry.HasContainer() &&
+		existing.ContainerID != entry.ContainerID {
+
+		return "", fmt.Errorf("Listener %s is already registered as %s for %s",
+			key, existing.Name, existing.ContainerString(),
+		)
+	}
+
+	// Older shims don't tell us the container, so keep the one we learned
+	// from Docker rather than churning the entry on every heartbeat.
+	if existing != nil && existing.HasContainer() && !entry.HasContainer() {
+		adopted := *entry
+		adopted.ContainerID = existing.ContainerID
+		adopted.ContainerName = existing.ContainerName
+		adopted.Image = existing.Image
+		adopted.Labels = existing.Labels
+		entry = &adopted
+	}
+
+	if merged := MergeDualStack(existing, entry); merged != nil {
+		entry = merged
+	}
+
+	if existing != nil && existing.SameAs(entry) {
+		return existing.Name, nil
+	}
+
+	return r.addEntryLocked(entry), nil
+}
+
+// DeregisterEntry drains an entry deregistered by a shim. If it was half
+// of a dual stack registration, the other half is kept.
+func (r *Registrar) DeregisterEntry(entry *Entry) {
+	existing := r.findEntry(entry)
+	if existing == nil {
+		log.Infof("Nothing to deregister on %s for %s", ListenerKey(entry), entry.ContainerString())
+		return
+	}
+
+	if remaining := splitDualStack(existing, entry); remaining != nil {
+		log.Infof("Deregistering %s from %s, keeping %s", existing.Name, entry.FrontendAddr, remaining.FrontendAddr)
+		r.AddEntry(remaining)
+		return
+	}
+
+	r.DrainEntry(existing.Name)
+}
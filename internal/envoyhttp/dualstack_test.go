@@ -0,0 +1,110 @@
+package envoyhttp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_DualStack(t *testing.T) {
+	Convey("Dual stack registrations", t, func() {
+		registrar := NewRegistrar()
+		name := "bede-dev-12345"
+
+		request := func(frontend, backend string, action shimrpc.RegistrarRequest_Action) *shimrpc.RegistrarRequest {
+			return &shimrpc.RegistrarRequest{
+				FrontendAddr:    frontend,
+				FrontendPort:    12345,
+				BackendAddr:     backend,
+				BackendPort:     80,
+				EnvironmentName: "dev",
+				ServiceName:     "bede",
+				ProxyMode:       "tcp",
+				Action:          action,
+			}
+		}
+
+		v4 := request("0.0.0.0", "172.16.10.1", shimrpc.RegistrarRequest_REGISTER)
+		v6 := request("::", "fd00::1", shimrpc.RegistrarRequest_REGISTER)
+
+		Convey("are merged into one entry on ::", func() {
+			registrar.Register(context.Background(), v4)
+			registrar.Register(context.Background(), v6)
+
+			entry := registrar.GetEntry(name)
+			So(entry, ShouldNotBeNil)
+			So(entry.DualStack, ShouldBeTrue)
+			So(entry.FrontendAddr.String(), ShouldEqual, "[::]:12345")
+
+			Convey("preferring the IPv4 backend", func() {
+				So(entry.BackendAddr.String(), ShouldEqual, "172.16.10.1:80")
+			})
+
+			Convey("and keep the other half on deregistering one", func() {
+				v4.Action = shimrpc.RegistrarRequest_DEREGISTER
+				registrar.Register(context.Background(), v4)
+
+				entry := registrar.GetEntry(name)
+				So(entry, ShouldNotBeNil)
+				So(entry.DualStack, ShouldBeFalse)
+				So(entry.FrontendAddr.String(), ShouldEqual, "[::]:12345")
+
+				v6.Action = shimrpc.RegistrarRequest_DEREGISTER
+				registrar.Register(context.Background(), v6)
+				So(registrar.GetEntry(name), ShouldBeNil)
+			})
+		})
+
+		Convey("are merged in either order", func() {
+			registrar.Register(context.Background(), v6)
+			registrar.Register(context.Background(), v4)
+
+			entry := registrar.GetEntry(name)
+			So(entry.DualStack, ShouldBeTrue)
+			So(entry.BackendAddr.String(), ShouldEqual, "172.16.10.1:80")
+
+			v6.Action = shimrpc.RegistrarRequest_DEREGISTER
+			registrar.Register(context.Background(), v6)
+			So(registrar.GetEntry(name).FrontendAddr.String(), ShouldEqual, "0.0.0.0:12345")
+		})
+
+		Convey("are not merged for specific addresses", func() {
+			registrar.Register(context.Background(), v4)
+			registrar.Register(context.Background(), request("2001:db8::1", "fd00::1", shimrpc.RegistrarRequest_REGISTER))
+
+			entry := registrar.GetEntry(name)
+			So(entry.DualStack, ShouldBeFalse)
+			So(entry.FrontendAddr.String(), ShouldEqual, "0.0.0.0:12345")
+
+			specific := registrar.GetEntry(name + "-2001_db8__1")
+			So(specific, ShouldNotBeNil)
+			So(specific.DualStack, ShouldBeFalse)
+			So(specific.FrontendAddr.String(), ShouldEqual, "[2001:db8::1]:12345")
+		})
+
+		Convey("don't churn the registrar on re-registration", func() {
+			registrar.Register(context.Background(), v4)
+			registrar.Register(context.Background(), v6)
+
+			listener := registrar.Listen()
+			registrar.Register(context.Background(), v4)
+			registrar.Register(context.Background(), v6)
+
+			So(len(listener), ShouldEqual, 0)
+		})
+
+		Convey("are rejected with invalid addresses", func() {
+			_, err := registrar.Register(context.Background(), request("beowulf", "172.16.10.1", shimrpc.RegistrarRequest_REGISTER))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Invalid frontend address")
+
+			_, err = registrar.Register(context.Background(), request("0.0.0.0", "", shimrpc.RegistrarRequest_REGISTER))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Invalid backend address")
+
+			So(registrar.GetEntry(name), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,145 @@
+package envoyhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvoyAdminTimeout is how long we'll wait for Envoy's admin API
+	EnvoyAdminTimeout = 2 * time.Second
+)
+
+// An EnvoyAdmin asks Envoy's admin API what it thinks of the hosts we gave
+// it. This needs an Envoy that serves /clusters as JSON, which the v1 era
+// releases don't.
+type EnvoyAdmin struct {
+	URL    string
+	client *http.Client
+}
+
+// NewEnvoyAdmin returns an EnvoyAdmin for the admin API at the URL passed in,
+// e.g. http://127.0.0.1:9901
+func NewEnvoyAdmin(url string) *EnvoyAdmin {
+	return &EnvoyAdmin{
+		URL:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: EnvoyAdminTimeout},
+	}
+}
+
+// HostHealth is Envoy's view of one of a cluster's hosts
+type HostHealth struct {
+	Address                 string `json:"address"`
+	Healthy                 bool   `json:"healthy"`
+	FailedActiveHealthCheck bool   `json:"failed_active_health_check,omitempty"`
+	FailedOutlierCheck      bool   `json:"failed_outlier_check,omitempty"`
+	EdsHealthStatus         string `json:"eds_health_status,omitempty"`
+}
+
+// The parts of the admin API's /clusters?format=json that we use
+type adminClusters struct {
+	ClusterStatuses []struct {
+		Name         string `json:"name"`
+		HostStatuses []struct {
+			Address struct {
+				SocketAddress struct {
+					Address   string `json:"address"`
+					PortValue int    `json:"port_value"`
+				} `json:"socket_address"`
+			} `json:"address"`
+			HealthStatus struct {
+				FailedActiveHealthCheck bool   `json:"failed_active_health_check"`
+				FailedOutlierCheck      bool   `json:"failed_outlier_check"`
+				EdsHealthStatus         string `json:"eds_health_status"`
+			} `json:"health_status"`
+		} `json:"host_statuses"`
+	} `json:"cluster_statuses"`
+}
+
+// ClusterHealth returns the health of the hosts in each of Envoy's
+// clusters, by cluster name.
+func (a *EnvoyAdmin) ClusterHealth() (map[string][]*HostHealth, error) {
+	resp, err := a.client.Get(a.URL + "/clusters?format=json")
+	if err != nil {
+		return nil, fmt.Errorf("Can't reach the Envoy admin API: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Envoy admin API returned %s", resp.Status)
+	}
+
+	var clusters adminClusters
+	err = json.NewDecoder(resp.Body).Decode(&clusters)
+	if err != nil {
+		return nil, fmt.Errorf("Can't decode the Envoy admin API's clusters: %s", err)
+	}
+
+	result := make(map[string][]*HostHealth)
+	for _, cluster := range clusters.ClusterStatuses {
+		for _, host := range cluster.HostStatuses {
+			status := host.HealthStatus
+			addr := host.Address.SocketAddress
+
+			result[cluster.Name] = append(result[cluster.Name], &HostHealth{
+				Address:                 net.JoinHostPort(addr.Address, strconv.Itoa(addr.PortValue)),
+				FailedActiveHealthCheck: status.FailedActiveHealthCheck,
+				FailedOutlierCheck:      status.FailedOutlierCheck,
+				EdsHealthStatus:         status.EdsHealthStatus,
+				Healthy: !status.FailedActiveHealthCheck && !status.FailedOutlierCheck &&
+					(len(status.EdsHealthStatus) < 1 || status.EdsHealthStatus == "HEALTHY"),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// ServiceHealth is the health of the hosts behind one of our clusters
+type ServiceHealth struct {
+	Name        string        `json:"name"`
+	HealthCheck string        `json:"health_check,omitempty"` // The type, empty if Envoy isn't checking
+	Hosts       []*HostHealth `json:"hosts"`                  // Empty if Envoy doesn't know the cluster yet
+}
+
+// ServiceHealth returns the health of every cluster we serve, including the
+// shared ones for ServiceGroups, sorted by name.
+func (r *Registrar) ServiceHealth(admin *EnvoyAdmin) ([]*ServiceHealth, error) {
+	clusters, err := admin.ClusterHealth()
+	if err != nil {
+		return nil, err
+	}
+
+	health := func(entry *Entry) *ServiceHealth {
+		hosts := clusters[entry.ClusterName()]
+		if hosts == nil {
+			hosts = []*HostHealth{}
+		}
+
+		return &ServiceHealth{
+			Name:        entry.ClusterName(),
+			HealthCheck: entry.Tuning.HealthCheck.Type,
+			Hosts:       hosts,
+		}
+	}
+
+	var result []*ServiceHealth
+	r.EachEntry(func(name string, entry *Entry) error {
+		result = append(result, health(entry))
+		return nil
+	})
+
+	for _, group := range r.ServiceGroups() {
+		result = append(result, health(group.Entry))
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
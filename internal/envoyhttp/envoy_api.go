@@ -14,6 +14,7 @@ import (
 
 type EnvoyApi struct {
 	registrar *Registrar
+	Admin     *EnvoyAdmin // Where to ask Envoy about health, nil if we can't
 }
 
 func NewEnvoyApi(registrar *Registrar) *EnvoyApi {
@@ -43,16 +44,26 @@ func (s *EnvoyApi) registrationHandler(response http.ResponseWriter, req *http.R
 		return
 	}
 
-	entry := s.registrar.GetEntry(name)
+	var members []*Entry
+	if entry := s.registrar.GetEntry(name); entry != nil {
+		members = []*Entry{entry}
+	} else if group := s.registrar.GetServiceGroup(name); group != nil {
+		members = group.Members
+	}
 
-	if entry == nil {
+	if len(members) < 1 {
 		log.Debugf("Envoy Service '%s' has no instances!", name)
 		sendJsonError(response, 404, fmt.Sprintf("no instances of '%s' found", name))
 		return
 	}
 
-	instances := []*EnvoyService{
-		s.EnvoyServiceFromEntry(entry),
+	// The v1 API can't mark hosts unhealthy or draining, so we leave them
+	// out, along with the ones that aren't ready yet
+	instances := []*EnvoyService{}
+	for _, member := range members {
+		if member.IsHealthy() && member.IsReady() && !member.IsDraining() {
+			instances = append(instances, s.EnvoyServiceFromEntry(member))
+		}
 	}
 
 	result := SDSResult{
@@ -120,6 +131,50 @@ func (s *EnvoyApi) listenersHandler(response http.ResponseWriter, req *http.Requ
 	response.Write(jsonBytes)
 }
 
+// healthHandler reports Envoy's view of the health of the hosts behind each
+// of our clusters, or just the one named. This is our own API, not Envoy's.
+func (s *EnvoyApi) healthHandler(response http.ResponseWriter, req *http.Request, params map[string]string) {
+	defer req.Body.Close()
+
+	response.Header().Set("Content-Type", "application/json")
+
+	if s.Admin == nil {
+		sendJsonError(response, 503, "No Envoy admin URL is configured")
+		return
+	}
+
+	services, err := s.registrar.ServiceHealth(s.Admin)
+	if err != nil {
+		log.Errorf("Unable to get health from Envoy: %s", err)
+		sendJsonError(response, 502, err.Error())
+		return
+	}
+
+	var result interface{} = services
+	if name, ok := params["service"]; ok {
+		result = nil
+		for _, service := range services {
+			if service.Name == name {
+				result = service
+			}
+		}
+
+		if result == nil {
+			sendJsonError(response, 404, fmt.Sprintf("no instances of '%s' found", name))
+			return
+		}
+	}
+
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		log.Errorf("Error marshaling state in healthHandler: %s", err.Error())
+		sendJsonError(response, 500, "Internal server error")
+		return
+	}
+
+	response.Write(jsonBytes)
+}
+
 // EnvoyServiceFromRequest converts a Registrar request to an Envoy
 // API service for reporting to the proxy.
 func (s *EnvoyApi) EnvoyServiceFromEntry(entry *Entry) *EnvoyService {
@@ -132,10 +187,74 @@ func (s *EnvoyApi) EnvoyServiceFromEntry(entry *Entry) *EnvoyService {
 		LastCheckIn:     time.Now().UTC().String(),
 		Port:            int64(entry.BackendAddr.Port),
 		Revision:        "1",
-		Service:         SvcName(entry),
+		Service:         entry.ClusterName(),
 		ServiceRepoName: "docker service",
-		Tags:            map[string]string{},
+		Tags:            containerTags(entry),
+	}
+}
+
+// containerTags describes the container behind an entry, when we know it.
+func containerTags(entry *Entry) map[string]string {
+	tags := map[string]string{}
+	if !entry.HasContainer() {
+		return tags
+	}
+
+	tags["container_id"] = entry.ContainerID
+	tags["container_name"] = entry.ContainerName
+	tags["image"] = entry.Image
+
+	return tags
+}
+
+// EnvoyClusterFromEntry returns the Envoy API cluster definition for an
+// entry, or for the shared cluster of a ServiceGroup.
+func (s *EnvoyApi) EnvoyClusterFromEntry(entry *Entry) *EnvoyCluster {
+	cluster := &EnvoyCluster{
+		Name:             entry.ClusterName(),
+		Type:             "sds", // use SDS endpoint for the hosts
+		ConnectTimeoutMs: int64(entry.Tuning.ConnectTimeoutOrDefault() / time.Millisecond),
+		LBType:           entry.Tuning.LBPolicyOrDefault(),
+		ServiceName:      entry.ClusterName(),
+	}
+
+	if entry.Tuning.HasCircuitBreakers() {
+		cluster.CircuitBreakers = &EnvoyCircuitBreakers{
+			Default: &EnvoyCircuitBreakerThresholds{
+				MaxConnections: int64(entry.Tuning.MaxConnections),
+				MaxRequests:    int64(entry.Tuning.MaxRequests),
+			},
+		}
+	}
+
+	cluster.HealthCheck = envoyHealthCheck(&entry.Tuning.HealthCheck)
+
+	return cluster
+}
+
+// envoyHealthCheck returns the active health check for a cluster, or nil if
+// there isn't one. The v1 API only passes HTTP checks on a 200.
+func envoyHealthCheck(hc *HealthCheck) *EnvoyHealthCheck {
+	if !hc.Enabled() {
+		return nil
+	}
+
+	check := &EnvoyHealthCheck{
+		Type:               hc.Type,
+		TimeoutMs:          int64(hc.TimeoutOrDefault() / time.Millisecond),
+		IntervalMs:         int64(hc.IntervalOrDefault() / time.Millisecond),
+		UnhealthyThreshold: int64(hc.UnhealthyThresholdOrDefault()),
+		HealthyThreshold:   int64(hc.HealthyThresholdOrDefault()),
+	}
+
+	if hc.Type == HealthCheckHTTP {
+		check.Path = hc.PathOrDefault()
+	} else {
+		check.Send = &[]string{}
+		check.Receive = &[]string{}
 	}
+
+	return check
 }
 
 // EnvoyClustersFromRegistrar genenerates a set of Envoy API cluster
@@ -144,17 +263,22 @@ func (s *EnvoyApi) EnvoyClustersFromRegistrar() []*EnvoyCluster {
 	var clusters []*EnvoyCluster
 
 	s.registrar.EachEntry(func(name string, entry *Entry) error {
-		clusters = append(clusters, &EnvoyCluster{
-			Name:             SvcName(entry),
-			Type:             "sds", // use SDS endpoint for the hosts
-			ConnectTimeoutMs: 500,
-			LBType:           "round_robin",
-			ServiceName:      SvcName(entry),
-		})
+		// The v1 API can't proxy UDP
+		if entry.IsUDP() {
+			return nil
+		}
+
+		clusters = append(clusters, s.EnvoyClusterFromEntry(entry))
 
 		return nil
 	})
 
+	for _, group := range s.registrar.ServiceGroups() {
+		if !group.Entry.IsUDP() {
+			clusters = append(clusters, s.EnvoyClusterFromEntry(group.Entry))
+		}
+	}
+
 	if clusters == nil {
 		clusters = []*EnvoyCluster{}
 	}
@@ -165,49 +289,18 @@ func (s *EnvoyApi) EnvoyClustersFromRegistrar() []*EnvoyCluster {
 // EnvoyListenerFromEntry takes a Registrar request service and formats it into
 // the API format for an Envoy proxy listener (LDS API v1)
 func (s *EnvoyApi) EnvoyListenerFromEntry(entry *Entry) *EnvoyListener {
-	apiName := SvcName(entry)
+	apiName := entry.ClusterName()
 
 	listener := &EnvoyListener{
 		Name:    apiName,
-		Address: fmt.Sprintf("tcp://%s:%d", entry.FrontendAddr.IP, entry.FrontendAddr.Port),
+		Address: "tcp://" + entry.FrontendAddr.String(), // Brackets IPv6 addresses
 	}
 
 	if entry.ProxyMode == "http" {
 		listener.Filters = []*EnvoyFilter{
-			{
-				Name: "envoy.http_connection_manager",
-				Config: &EnvoyFilterConfig{
-					CodecType:  "auto",
-					StatPrefix: "ingress_http",
-					Filters: []*EnvoyFilter{
-						{
-							Name:   "router",
-							Config: &EnvoyFilterConfig{},
-						},
-					},
-					RouteConfig: &EnvoyRouteConfig{
-						VirtualHosts: []*EnvoyHTTPVirtualHost{
-							{
-								Name:    SvcName(entry),
-								Domains: []string{"*"},
-								Routes: []*EnvoyRoute{
-									{
-										TimeoutMs: 0, // No timeout!
-										Prefix:    "/",
-										Cluster:   apiName,
-										Decorator: &EnvoyRouteDecorator{
-											Operation: entry.ServiceName,
-										},
-									},
-								},
-							},
-						},
-					},
-					Tracing: &EnvoyTracingConfig{
-						OperationName: "egress",
-					},
-				},
-			},
+			envoyHTTPFilter(&entry.Tuning, []*EnvoyHTTPVirtualHost{
+				envoyVirtualHost(entry, []string{"*"}),
+			}),
 		}
 	} else { // == "tcp"
 		listener.Filters = []*EnvoyFilter{
@@ -218,7 +311,7 @@ func (s *EnvoyApi) EnvoyListenerFromEntry(entry *Entry) *EnvoyListener {
 					RouteConfig: &EnvoyRouteConfig{
 						Routes: []*EnvoyTCPRoute{
 							{
-								Cluster: SvcName(entry),
+								Cluster: entry.ClusterName(),
 							},
 						},
 					},
@@ -230,16 +323,105 @@ func (s *EnvoyApi) EnvoyListenerFromEntry(entry *Entry) *EnvoyListener {
 	return listener
 }
 
+// EnvoyIngressListener formats the shared ingress listener, with a virtual
+// host for each of the services on it (LDS API v1)
+func (s *EnvoyApi) EnvoyIngressListener(ingress *Ingress) *EnvoyListener {
+	hosts := []*EnvoyHTTPVirtualHost{}
+	for _, host := range ingress.Hosts {
+		hosts = append(hosts, envoyVirtualHost(host.Entry, host.Domains))
+	}
+
+	return &EnvoyListener{
+		Name:    ingress.Entry.Name,
+		Address: "tcp://" + ingress.Entry.FrontendAddr.String(),
+		Filters: []*EnvoyFilter{envoyHTTPFilter(&ingress.Entry.Tuning, hosts)},
+	}
+}
+
+// envoyHTTPFilter returns an HTTP connection manager serving the virtual
+// hosts passed in.
+func envoyHTTPFilter(tuning *Tuning, hosts []*EnvoyHTTPVirtualHost) *EnvoyFilter {
+	return &EnvoyFilter{
+		Name: "envoy.http_connection_manager",
+		Config: &EnvoyFilterConfig{
+			CodecType:  "auto",
+			StatPrefix: "ingress_http",
+			Filters: []*EnvoyFilter{
+				{
+					Name:   "router",
+					Config: &EnvoyFilterConfig{},
+				},
+			},
+			RouteConfig: &EnvoyRouteConfig{
+				VirtualHosts: hosts,
+			},
+			Tracing: &EnvoyTracingConfig{
+				OperationName: "egress",
+			},
+			// Whole seconds, rounding up so we never time out early
+			IdleTimeoutS: int64((tuning.IdleTimeout + time.Second - 1) / time.Second),
+		},
+	}
+}
+
+// envoyVirtualHost returns a virtual host that routes the domains to the
+// entry's cluster.
+func envoyVirtualHost(entry *Entry, domains []string) *EnvoyHTTPVirtualHost {
+	route := &EnvoyRoute{
+		TimeoutMs: int(entry.Tuning.RouteTimeout / time.Millisecond), // 0 is no timeout!
+		Prefix:    "/",
+		Cluster:   entry.ClusterName(),
+		Decorator: &EnvoyRouteDecorator{
+			Operation: entry.ServiceName,
+		},
+	}
+
+	if len(entry.Tuning.RetryOn) > 0 {
+		route.RetryPolicy = &EnvoyRetryPolicy{
+			RetryOn:    entry.Tuning.RetryOn,
+			NumRetries: entry.Tuning.NumRetries,
+		}
+	}
+
+	return &EnvoyHTTPVirtualHost{
+		Name:    entry.ClusterName(),
+		Domains: domains,
+		Routes:  []*EnvoyRoute{route},
+	}
+}
+
 // EnvoyListenersFromRegistrar creates a set of Enovy API listener
-// definitions from all the ports in the Registrar.
+// definitions from all the ports in the Registrar. There is no TLS
+// passthrough, the v1 API can't match connections on their SNI.
 func (s *EnvoyApi) EnvoyListenersFromRegistrar() []*EnvoyListener {
 	var listeners []*EnvoyListener
 
 	s.registrar.EachEntry(func(name string, entry *Entry) error {
+		// The v1 API can't proxy UDP
+		if entry.IsUDP() {
+			return nil
+		}
+
+		// Nor can we give it certificates. Better no listener than plaintext.
+		if entry.Tuning.HasTLS() {
+			log.Debugf("Not serving %s over the v1 API, it needs TLS", entry.Name)
+			return nil
+		}
+
 		listeners = append(listeners, s.EnvoyListenerFromEntry(entry))
 		return nil
 	})
 
+	for _, group := range s.registrar.ServiceGroups() {
+		if !group.Entry.IsUDP() && !group.Entry.Tuning.HasTLS() {
+			listeners = append(listeners, s.EnvoyListenerFromEntry(group.Entry))
+		}
+	}
+
+	if ingress := s.registrar.Ingress(); ingress != nil {
+		listeners = append(listeners, s.EnvoyIngressListener(ingress))
+	}
+
 	if listeners == nil {
 		listeners = []*EnvoyListener{}
 	}
@@ -283,6 +465,8 @@ func (s *EnvoyApi) HttpMux() http.Handler {
 	router.HandleFunc("/clusters", wrap(s.clustersHandler)).Methods("GET")
 	router.HandleFunc("/listeners/{service_cluster}/{service_node}", wrap(s.listenersHandler)).Methods("GET")
 	router.HandleFunc("/listeners", wrap(s.listenersHandler)).Methods("GET")
+	router.HandleFunc("/health/{service}", wrap(s.healthHandler)).Methods("GET")
+	router.HandleFunc("/health", wrap(s.healthHandler)).Methods("GET")
 	router.HandleFunc("/{path}", s.optionsHandler).Methods("OPTIONS")
 
 	return router
@@ -22,9 +22,33 @@ type EnvoyCluster struct {
 	ConnectTimeoutMs int64  `json:"connect_timeout_ms"`
 	LBType           string `json:"lb_type"`
 	ServiceName      string `json:"service_name"`
+	CircuitBreakers  *EnvoyCircuitBreakers `json:"circuit_breakers,omitempty"`
+	HealthCheck      *EnvoyHealthCheck `json:"health_check,omitempty"`
 	// Many optional fields omitted
 }
 
+// https://www.envoyproxy.io/docs/envoy/v1.7.0/api-v1/cluster_manager/cluster_hc
+type EnvoyHealthCheck struct {
+	Type               string    `json:"type"`
+	TimeoutMs          int64     `json:"timeout_ms"`
+	IntervalMs         int64     `json:"interval_ms"`
+	UnhealthyThreshold int64     `json:"unhealthy_threshold"`
+	HealthyThreshold   int64     `json:"healthy_threshold"`
+	Path               string    `json:"path,omitempty"` // HTTP only
+	Send               *[]string `json:"send,omitempty"` // TCP only, empty just connects
+	Receive            *[]string `json:"receive,omitempty"`
+}
+
+// https://www.envoyproxy.io/docs/envoy/v1.7.0/api-v1/cluster_manager/cluster_circuit_breakers
+type EnvoyCircuitBreakers struct {
+	Default *EnvoyCircuitBreakerThresholds `json:"default"`
+}
+
+type EnvoyCircuitBreakerThresholds struct {
+	MaxConnections int64 `json:"max_connections,omitempty"`
+	MaxRequests    int64 `json:"max_requests,omitempty"`
+}
+
 // https://www.envoyproxy.io/docs/envoy/latest/api-v1/listeners/listeners.html
 type EnvoyListener struct {
 	Name    string         `json:"name"`
@@ -45,6 +69,7 @@ type EnvoyFilterConfig struct {
 	RouteConfig *EnvoyRouteConfig   `json:"route_config,omitempty"`
 	Filters     []*EnvoyFilter      `json:"filters,omitempty"`
 	Tracing     *EnvoyTracingConfig `json:"tracing,omitempty"`
+	IdleTimeoutS int64 `json:"idle_timeout_s,omitempty"`
 }
 
 type EnvoyHTTPVirtualHost struct {
@@ -64,6 +89,12 @@ type EnvoyRoute struct {
 	HostRewrite string               `json:"host_rewrite"`
 	Cluster     string               `json:"cluster"`
 	Decorator   *EnvoyRouteDecorator `json:"decorator,omitempty"`
+	RetryPolicy *EnvoyRetryPolicy `json:"retry_policy,omitempty"`
+}
+
+type EnvoyRetryPolicy struct {
+	RetryOn    string `json:"retry_on"`
+	NumRetries int    `json:"num_retries,omitempty"`
 }
 
 type EnvoyRouteDecorator struct {
@@ -1,7 +1,5 @@
-// DO NOT EDIT!
-// Code generated by ffjson <https://github.com/pquerna/ffjson>
+// Code generated by ffjson <https://github.com/pquerna/ffjson>. DO NOT EDIT.
 // source: envoy_api_objects.go
-// DO NOT EDIT!
 
 package envoyhttp
 
@@ -11,20 +9,23 @@ import (
 	fflib "github.com/pquerna/ffjson/fflib/v1"
 )
 
-func (mj *CDSResult) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *CDSResult) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *CDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *CDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -33,9 +34,9 @@ func (mj *CDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{"clusters":`)
-	if mj.Clusters != nil {
+	if j.Clusters != nil {
 		buf.WriteString(`[`)
-		for i, v := range mj.Clusters {
+		for i, v := range j.Clusters {
 			if i != 0 {
 				buf.WriteString(`,`)
 			}
@@ -44,12 +45,13 @@ func (mj *CDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 
 				if v == nil {
 					buf.WriteString("null")
-					return nil
-				}
+				} else {
+
+					err = v.MarshalJSONBuf(buf)
+					if err != nil {
+						return err
+					}
 
-				err = v.MarshalJSONBuf(buf)
-				if err != nil {
-					return err
 				}
 
 			}
@@ -63,22 +65,24 @@ func (mj *CDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_CDSResultbase = iota
-	ffj_t_CDSResultno_such_key
+	ffjtCDSResultbase = iota
+	ffjtCDSResultnosuchkey
 
-	ffj_t_CDSResult_Clusters
+	ffjtCDSResultClusters
 )
 
-var ffj_key_CDSResult_Clusters = []byte("clusters")
+var ffjKeyCDSResultClusters = []byte("clusters")
 
-func (uj *CDSResult) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *CDSResult) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *CDSResult) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_CDSResultbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *CDSResult) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtCDSResultbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -124,7 +128,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_CDSResultno_such_key
+				currentKey = ffjtCDSResultnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -132,21 +136,21 @@ mainparse:
 
 				case 'c':
 
-					if bytes.Equal(ffj_key_CDSResult_Clusters, kn) {
-						currentKey = ffj_t_CDSResult_Clusters
+					if bytes.Equal(ffjKeyCDSResultClusters, kn) {
+						currentKey = ffjtCDSResultClusters
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_CDSResult_Clusters, kn) {
-					currentKey = ffj_t_CDSResult_Clusters
+				if fflib.EqualFoldRight(ffjKeyCDSResultClusters, kn) {
+					currentKey = ffjtCDSResultClusters
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_CDSResultno_such_key
+				currentKey = ffjtCDSResultnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -163,10 +167,10 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_CDSResult_Clusters:
+				case ffjtCDSResultClusters:
 					goto handle_Clusters
 
-				case ffj_t_CDSResultno_such_key:
+				case ffjtCDSResultnosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -182,7 +186,7 @@ mainparse:
 
 handle_Clusters:
 
-	/* handler: uj.Clusters type=[]*envoyhttp.EnvoyCluster kind=slice quoted=false*/
+	/* handler: j.Clusters type=[]*envoyhttp.EnvoyCluster kind=slice quoted=false*/
 
 	{
 
@@ -193,16 +197,16 @@ handle_Clusters:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Clusters = nil
+			j.Clusters = nil
 		} else {
 
-			uj.Clusters = []*EnvoyCluster{}
+			j.Clusters = []*EnvoyCluster{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__Clusters *EnvoyCluster
+				var tmpJClusters *EnvoyCluster
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -223,29 +227,28 @@ handle_Clusters:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__Clusters type=*envoyhttp.EnvoyCluster kind=ptr quoted=false*/
+				/* handler: tmpJClusters type=*envoyhttp.EnvoyCluster kind=ptr quoted=false*/
 
 				{
 					if tok == fflib.FFTok_null {
 
-						tmp_uj__Clusters = nil
+						tmpJClusters = nil
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+					} else {
 
-					if tmp_uj__Clusters == nil {
-						tmp_uj__Clusters = new(EnvoyCluster)
-					}
+						if tmpJClusters == nil {
+							tmpJClusters = new(EnvoyCluster)
+						}
 
-					err = tmp_uj__Clusters.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
+						err = tmpJClusters.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
 					}
 					state = fflib.FFParse_after_value
 				}
 
-				uj.Clusters = append(uj.Clusters, tmp_uj__Clusters)
+				j.Clusters = append(j.Clusters, tmpJClusters)
 
 				wantVal = false
 			}
@@ -273,20 +276,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyCluster) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyCircuitBreakerThresholds) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyCluster) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyCircuitBreakerThresholds) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -294,53 +300,45 @@ func (mj *EnvoyCluster) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	var obj []byte
 	_ = obj
 	_ = err
-	buf.WriteString(`{"name":`)
-	fflib.WriteJsonString(buf, string(mj.Name))
-	buf.WriteString(`,"type":`)
-	fflib.WriteJsonString(buf, string(mj.Type))
-	buf.WriteString(`,"connect_timeout_ms":`)
-	fflib.FormatBits2(buf, uint64(mj.ConnectTimeoutMs), 10, mj.ConnectTimeoutMs < 0)
-	buf.WriteString(`,"lb_type":`)
-	fflib.WriteJsonString(buf, string(mj.LBType))
-	buf.WriteString(`,"service_name":`)
-	fflib.WriteJsonString(buf, string(mj.ServiceName))
+	buf.WriteString(`{ `)
+	if j.MaxConnections != 0 {
+		buf.WriteString(`"max_connections":`)
+		fflib.FormatBits2(buf, uint64(j.MaxConnections), 10, j.MaxConnections < 0)
+		buf.WriteByte(',')
+	}
+	if j.MaxRequests != 0 {
+		buf.WriteString(`"max_requests":`)
+		fflib.FormatBits2(buf, uint64(j.MaxRequests), 10, j.MaxRequests < 0)
+		buf.WriteByte(',')
+	}
+	buf.Rewind(1)
 	buf.WriteByte('}')
 	return nil
 }
 
 const (
-	ffj_t_EnvoyClusterbase = iota
-	ffj_t_EnvoyClusterno_such_key
-
-	ffj_t_EnvoyCluster_Name
+	ffjtEnvoyCircuitBreakerThresholdsbase = iota
+	ffjtEnvoyCircuitBreakerThresholdsnosuchkey
 
-	ffj_t_EnvoyCluster_Type
+	ffjtEnvoyCircuitBreakerThresholdsMaxConnections
 
-	ffj_t_EnvoyCluster_ConnectTimeoutMs
-
-	ffj_t_EnvoyCluster_LBType
-
-	ffj_t_EnvoyCluster_ServiceName
+	ffjtEnvoyCircuitBreakerThresholdsMaxRequests
 )
 
-var ffj_key_EnvoyCluster_Name = []byte("name")
-
-var ffj_key_EnvoyCluster_Type = []byte("type")
-
-var ffj_key_EnvoyCluster_ConnectTimeoutMs = []byte("connect_timeout_ms")
+var ffjKeyEnvoyCircuitBreakerThresholdsMaxConnections = []byte("max_connections")
 
-var ffj_key_EnvoyCluster_LBType = []byte("lb_type")
+var ffjKeyEnvoyCircuitBreakerThresholdsMaxRequests = []byte("max_requests")
 
-var ffj_key_EnvoyCluster_ServiceName = []byte("service_name")
-
-func (uj *EnvoyCluster) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyCircuitBreakerThresholds) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyCluster) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyClusterbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyCircuitBreakerThresholds) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyCircuitBreakerThresholdsbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -386,85 +384,40 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyClusterno_such_key
+				currentKey = ffjtEnvoyCircuitBreakerThresholdsnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
 				switch kn[0] {
 
-				case 'c':
-
-					if bytes.Equal(ffj_key_EnvoyCluster_ConnectTimeoutMs, kn) {
-						currentKey = ffj_t_EnvoyCluster_ConnectTimeoutMs
-						state = fflib.FFParse_want_colon
-						goto mainparse
-					}
-
-				case 'l':
-
-					if bytes.Equal(ffj_key_EnvoyCluster_LBType, kn) {
-						currentKey = ffj_t_EnvoyCluster_LBType
-						state = fflib.FFParse_want_colon
-						goto mainparse
-					}
-
-				case 'n':
-
-					if bytes.Equal(ffj_key_EnvoyCluster_Name, kn) {
-						currentKey = ffj_t_EnvoyCluster_Name
-						state = fflib.FFParse_want_colon
-						goto mainparse
-					}
-
-				case 's':
+				case 'm':
 
-					if bytes.Equal(ffj_key_EnvoyCluster_ServiceName, kn) {
-						currentKey = ffj_t_EnvoyCluster_ServiceName
+					if bytes.Equal(ffjKeyEnvoyCircuitBreakerThresholdsMaxConnections, kn) {
+						currentKey = ffjtEnvoyCircuitBreakerThresholdsMaxConnections
 						state = fflib.FFParse_want_colon
 						goto mainparse
-					}
-
-				case 't':
 
-					if bytes.Equal(ffj_key_EnvoyCluster_Type, kn) {
-						currentKey = ffj_t_EnvoyCluster_Type
+					} else if bytes.Equal(ffjKeyEnvoyCircuitBreakerThresholdsMaxRequests, kn) {
+						currentKey = ffjtEnvoyCircuitBreakerThresholdsMaxRequests
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyCluster_ServiceName, kn) {
-					currentKey = ffj_t_EnvoyCluster_ServiceName
-					state = fflib.FFParse_want_colon
-					goto mainparse
-				}
-
-				if fflib.AsciiEqualFold(ffj_key_EnvoyCluster_LBType, kn) {
-					currentKey = ffj_t_EnvoyCluster_LBType
-					state = fflib.FFParse_want_colon
-					goto mainparse
-				}
-
-				if fflib.EqualFoldRight(ffj_key_EnvoyCluster_ConnectTimeoutMs, kn) {
-					currentKey = ffj_t_EnvoyCluster_ConnectTimeoutMs
-					state = fflib.FFParse_want_colon
-					goto mainparse
-				}
-
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyCluster_Type, kn) {
-					currentKey = ffj_t_EnvoyCluster_Type
+				if fflib.EqualFoldRight(ffjKeyEnvoyCircuitBreakerThresholdsMaxRequests, kn) {
+					currentKey = ffjtEnvoyCircuitBreakerThresholdsMaxRequests
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyCluster_Name, kn) {
-					currentKey = ffj_t_EnvoyCluster_Name
+				if fflib.EqualFoldRight(ffjKeyEnvoyCircuitBreakerThresholdsMaxConnections, kn) {
+					currentKey = ffjtEnvoyCircuitBreakerThresholdsMaxConnections
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyClusterno_such_key
+				currentKey = ffjtEnvoyCircuitBreakerThresholdsnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -481,22 +434,13 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyCluster_Name:
-					goto handle_Name
-
-				case ffj_t_EnvoyCluster_Type:
-					goto handle_Type
-
-				case ffj_t_EnvoyCluster_ConnectTimeoutMs:
-					goto handle_ConnectTimeoutMs
-
-				case ffj_t_EnvoyCluster_LBType:
-					goto handle_LBType
+				case ffjtEnvoyCircuitBreakerThresholdsMaxConnections:
+					goto handle_MaxConnections
 
-				case ffj_t_EnvoyCluster_ServiceName:
-					goto handle_ServiceName
+				case ffjtEnvoyCircuitBreakerThresholdsMaxRequests:
+					goto handle_MaxRequests
 
-				case ffj_t_EnvoyClusterno_such_key:
+				case ffjtEnvoyCircuitBreakerThresholdsnosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -510,61 +454,9 @@ mainparse:
 		}
 	}
 
-handle_Name:
-
-	/* handler: uj.Name type=string kind=string quoted=false*/
-
-	{
-
-		{
-			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
-			}
-		}
-
-		if tok == fflib.FFTok_null {
-
-		} else {
-
-			outBuf := fs.Output.Bytes()
-
-			uj.Name = string(string(outBuf))
-
-		}
-	}
-
-	state = fflib.FFParse_after_value
-	goto mainparse
-
-handle_Type:
-
-	/* handler: uj.Type type=string kind=string quoted=false*/
-
-	{
-
-		{
-			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
-			}
-		}
-
-		if tok == fflib.FFTok_null {
-
-		} else {
-
-			outBuf := fs.Output.Bytes()
-
-			uj.Type = string(string(outBuf))
-
-		}
-	}
-
-	state = fflib.FFParse_after_value
-	goto mainparse
-
-handle_ConnectTimeoutMs:
+handle_MaxConnections:
 
-	/* handler: uj.ConnectTimeoutMs type=int64 kind=int64 quoted=false*/
+	/* handler: j.MaxConnections type=int64 kind=int64 quoted=false*/
 
 	{
 		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
@@ -584,7 +476,7 @@ handle_ConnectTimeoutMs:
 				return fs.WrapErr(err)
 			}
 
-			uj.ConnectTimeoutMs = int64(tval)
+			j.MaxConnections = int64(tval)
 
 		}
 	}
@@ -592,51 +484,29 @@ handle_ConnectTimeoutMs:
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_LBType:
+handle_MaxRequests:
 
-	/* handler: uj.LBType type=string kind=string quoted=false*/
+	/* handler: j.MaxRequests type=int64 kind=int64 quoted=false*/
 
 	{
-
-		{
-			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
-			}
-		}
-
-		if tok == fflib.FFTok_null {
-
-		} else {
-
-			outBuf := fs.Output.Bytes()
-
-			uj.LBType = string(string(outBuf))
-
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
 		}
 	}
 
-	state = fflib.FFParse_after_value
-	goto mainparse
-
-handle_ServiceName:
-
-	/* handler: uj.ServiceName type=string kind=string quoted=false*/
-
 	{
 
-		{
-			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
-			}
-		}
-
 		if tok == fflib.FFTok_null {
 
 		} else {
 
-			outBuf := fs.Output.Bytes()
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
 
-			uj.ServiceName = string(string(outBuf))
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.MaxRequests = int64(tval)
 
 		}
 	}
@@ -662,20 +532,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyFilter) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyCircuitBreakers) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyFilter) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyCircuitBreakers) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -683,47 +556,43 @@ func (mj *EnvoyFilter) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	var obj []byte
 	_ = obj
 	_ = err
-	buf.WriteString(`{"name":`)
-	fflib.WriteJsonString(buf, string(mj.Name))
-	if mj.Config != nil {
-		buf.WriteString(`,"config":`)
+	if j.Default != nil {
+		buf.WriteString(`{"default":`)
 
 		{
 
-			err = mj.Config.MarshalJSONBuf(buf)
+			err = j.Default.MarshalJSONBuf(buf)
 			if err != nil {
 				return err
 			}
 
 		}
 	} else {
-		buf.WriteString(`,"config":null`)
+		buf.WriteString(`{"default":null`)
 	}
 	buf.WriteByte('}')
 	return nil
 }
 
 const (
-	ffj_t_EnvoyFilterbase = iota
-	ffj_t_EnvoyFilterno_such_key
+	ffjtEnvoyCircuitBreakersbase = iota
+	ffjtEnvoyCircuitBreakersnosuchkey
 
-	ffj_t_EnvoyFilter_Name
-
-	ffj_t_EnvoyFilter_Config
+	ffjtEnvoyCircuitBreakersDefault
 )
 
-var ffj_key_EnvoyFilter_Name = []byte("name")
-
-var ffj_key_EnvoyFilter_Config = []byte("config")
+var ffjKeyEnvoyCircuitBreakersDefault = []byte("default")
 
-func (uj *EnvoyFilter) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyCircuitBreakers) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyFilter) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyFilterbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyCircuitBreakers) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyCircuitBreakersbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -769,43 +638,29 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyFilterno_such_key
+				currentKey = ffjtEnvoyCircuitBreakersnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
 				switch kn[0] {
 
-				case 'c':
-
-					if bytes.Equal(ffj_key_EnvoyFilter_Config, kn) {
-						currentKey = ffj_t_EnvoyFilter_Config
-						state = fflib.FFParse_want_colon
-						goto mainparse
-					}
-
-				case 'n':
+				case 'd':
 
-					if bytes.Equal(ffj_key_EnvoyFilter_Name, kn) {
-						currentKey = ffj_t_EnvoyFilter_Name
+					if bytes.Equal(ffjKeyEnvoyCircuitBreakersDefault, kn) {
+						currentKey = ffjtEnvoyCircuitBreakersDefault
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyFilter_Config, kn) {
-					currentKey = ffj_t_EnvoyFilter_Config
-					state = fflib.FFParse_want_colon
-					goto mainparse
-				}
-
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyFilter_Name, kn) {
-					currentKey = ffj_t_EnvoyFilter_Name
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyCircuitBreakersDefault, kn) {
+					currentKey = ffjtEnvoyCircuitBreakersDefault
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyFilterno_such_key
+				currentKey = ffjtEnvoyCircuitBreakersnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -822,13 +677,10 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyFilter_Name:
-					goto handle_Name
-
-				case ffj_t_EnvoyFilter_Config:
-					goto handle_Config
+				case ffjtEnvoyCircuitBreakersDefault:
+					goto handle_Default
 
-				case ffj_t_EnvoyFilterno_such_key:
+				case ffjtEnvoyCircuitBreakersnosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -842,52 +694,25 @@ mainparse:
 		}
 	}
 
-handle_Name:
-
-	/* handler: uj.Name type=string kind=string quoted=false*/
-
-	{
-
-		{
-			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
-			}
-		}
-
-		if tok == fflib.FFTok_null {
-
-		} else {
-
-			outBuf := fs.Output.Bytes()
-
-			uj.Name = string(string(outBuf))
-
-		}
-	}
-
-	state = fflib.FFParse_after_value
-	goto mainparse
-
-handle_Config:
+handle_Default:
 
-	/* handler: uj.Config type=envoyhttp.EnvoyFilterConfig kind=struct quoted=false*/
+	/* handler: j.Default type=envoyhttp.EnvoyCircuitBreakerThresholds kind=struct quoted=false*/
 
 	{
 		if tok == fflib.FFTok_null {
 
-			uj.Config = nil
+			j.Default = nil
 
-			state = fflib.FFParse_after_value
-			goto mainparse
-		}
+		} else {
 
-		if uj.Config == nil {
-			uj.Config = new(EnvoyFilterConfig)
-		}
+			if j.Default == nil {
+				j.Default = new(EnvoyCircuitBreakerThresholds)
+			}
 
-		err = uj.Config.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-		if err != nil {
-			return err
+			err = j.Default.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
 		}
 		state = fflib.FFParse_after_value
 	}
@@ -913,20 +738,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyFilterConfig) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyCluster) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyFilterConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyCluster) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -934,24 +762,24 @@ func (mj *EnvoyFilterConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	var obj []byte
 	_ = obj
 	_ = err
-	buf.WriteString(`{ `)
-	if len(mj.CodecType) != 0 {
-		buf.WriteString(`"codec_type":`)
-		fflib.WriteJsonString(buf, string(mj.CodecType))
-		buf.WriteByte(',')
-	}
-	if len(mj.StatPrefix) != 0 {
-		buf.WriteString(`"stat_prefix":`)
-		fflib.WriteJsonString(buf, string(mj.StatPrefix))
-		buf.WriteByte(',')
-	}
-	if mj.RouteConfig != nil {
+	buf.WriteString(`{ "name":`)
+	fflib.WriteJsonString(buf, string(j.Name))
+	buf.WriteString(`,"type":`)
+	fflib.WriteJsonString(buf, string(j.Type))
+	buf.WriteString(`,"connect_timeout_ms":`)
+	fflib.FormatBits2(buf, uint64(j.ConnectTimeoutMs), 10, j.ConnectTimeoutMs < 0)
+	buf.WriteString(`,"lb_type":`)
+	fflib.WriteJsonString(buf, string(j.LBType))
+	buf.WriteString(`,"service_name":`)
+	fflib.WriteJsonString(buf, string(j.ServiceName))
+	buf.WriteByte(',')
+	if j.CircuitBreakers != nil {
 		if true {
-			buf.WriteString(`"route_config":`)
+			buf.WriteString(`"circuit_breakers":`)
 
 			{
 
-				err = mj.RouteConfig.MarshalJSONBuf(buf)
+				err = j.CircuitBreakers.MarshalJSONBuf(buf)
 				if err != nil {
 					return err
 				}
@@ -960,48 +788,19 @@ func (mj *EnvoyFilterConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 			buf.WriteByte(',')
 		}
 	}
-	if len(mj.Filters) != 0 {
-		buf.WriteString(`"filters":`)
-		if mj.Filters != nil {
-			buf.WriteString(`[`)
-			for i, v := range mj.Filters {
-				if i != 0 {
-					buf.WriteString(`,`)
-				}
-
-				{
-
-					if v == nil {
-						buf.WriteString("null")
-						return nil
-					}
+	if j.HealthCheck != nil {
+		if true {
+			buf.WriteString(`"health_check":`)
 
-					err = v.MarshalJSONBuf(buf)
-					if err != nil {
-						return err
-					}
+			{
 
+				err = j.HealthCheck.MarshalJSONBuf(buf)
+				if err != nil {
+					return err
 				}
+
 			}
-			buf.WriteString(`]`)
-		} else {
-			buf.WriteString(`null`)
-		}
-		buf.WriteByte(',')
-	}
-	if mj.Tracing != nil {
-		if true {
-			buf.WriteString(`"tracing":`)
-
-			{
-
-				err = mj.Tracing.MarshalJSONBuf(buf)
-				if err != nil {
-					return err
-				}
-
-			}
-			buf.WriteByte(',')
+			buf.WriteByte(',')
 		}
 	}
 	buf.Rewind(1)
@@ -1010,38 +809,48 @@ func (mj *EnvoyFilterConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_EnvoyFilterConfigbase = iota
-	ffj_t_EnvoyFilterConfigno_such_key
+	ffjtEnvoyClusterbase = iota
+	ffjtEnvoyClusternosuchkey
+
+	ffjtEnvoyClusterName
+
+	ffjtEnvoyClusterType
 
-	ffj_t_EnvoyFilterConfig_CodecType
+	ffjtEnvoyClusterConnectTimeoutMs
 
-	ffj_t_EnvoyFilterConfig_StatPrefix
+	ffjtEnvoyClusterLBType
 
-	ffj_t_EnvoyFilterConfig_RouteConfig
+	ffjtEnvoyClusterServiceName
 
-	ffj_t_EnvoyFilterConfig_Filters
+	ffjtEnvoyClusterCircuitBreakers
 
-	ffj_t_EnvoyFilterConfig_Tracing
+	ffjtEnvoyClusterHealthCheck
 )
 
-var ffj_key_EnvoyFilterConfig_CodecType = []byte("codec_type")
+var ffjKeyEnvoyClusterName = []byte("name")
 
-var ffj_key_EnvoyFilterConfig_StatPrefix = []byte("stat_prefix")
+var ffjKeyEnvoyClusterType = []byte("type")
 
-var ffj_key_EnvoyFilterConfig_RouteConfig = []byte("route_config")
+var ffjKeyEnvoyClusterConnectTimeoutMs = []byte("connect_timeout_ms")
 
-var ffj_key_EnvoyFilterConfig_Filters = []byte("filters")
+var ffjKeyEnvoyClusterLBType = []byte("lb_type")
 
-var ffj_key_EnvoyFilterConfig_Tracing = []byte("tracing")
+var ffjKeyEnvoyClusterServiceName = []byte("service_name")
 
-func (uj *EnvoyFilterConfig) UnmarshalJSON(input []byte) error {
+var ffjKeyEnvoyClusterCircuitBreakers = []byte("circuit_breakers")
+
+var ffjKeyEnvoyClusterHealthCheck = []byte("health_check")
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyCluster) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyFilterConfig) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyFilterConfigbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyCluster) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyClusterbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -1087,7 +896,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyFilterConfigno_such_key
+				currentKey = ffjtEnvoyClusternosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -1095,77 +904,102 @@ mainparse:
 
 				case 'c':
 
-					if bytes.Equal(ffj_key_EnvoyFilterConfig_CodecType, kn) {
-						currentKey = ffj_t_EnvoyFilterConfig_CodecType
+					if bytes.Equal(ffjKeyEnvoyClusterConnectTimeoutMs, kn) {
+						currentKey = ffjtEnvoyClusterConnectTimeoutMs
+						state = fflib.FFParse_want_colon
+						goto mainparse
+
+					} else if bytes.Equal(ffjKeyEnvoyClusterCircuitBreakers, kn) {
+						currentKey = ffjtEnvoyClusterCircuitBreakers
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
-				case 'f':
+				case 'h':
 
-					if bytes.Equal(ffj_key_EnvoyFilterConfig_Filters, kn) {
-						currentKey = ffj_t_EnvoyFilterConfig_Filters
+					if bytes.Equal(ffjKeyEnvoyClusterHealthCheck, kn) {
+						currentKey = ffjtEnvoyClusterHealthCheck
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
-				case 'r':
+				case 'l':
+
+					if bytes.Equal(ffjKeyEnvoyClusterLBType, kn) {
+						currentKey = ffjtEnvoyClusterLBType
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'n':
 
-					if bytes.Equal(ffj_key_EnvoyFilterConfig_RouteConfig, kn) {
-						currentKey = ffj_t_EnvoyFilterConfig_RouteConfig
+					if bytes.Equal(ffjKeyEnvoyClusterName, kn) {
+						currentKey = ffjtEnvoyClusterName
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 's':
 
-					if bytes.Equal(ffj_key_EnvoyFilterConfig_StatPrefix, kn) {
-						currentKey = ffj_t_EnvoyFilterConfig_StatPrefix
+					if bytes.Equal(ffjKeyEnvoyClusterServiceName, kn) {
+						currentKey = ffjtEnvoyClusterServiceName
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 't':
 
-					if bytes.Equal(ffj_key_EnvoyFilterConfig_Tracing, kn) {
-						currentKey = ffj_t_EnvoyFilterConfig_Tracing
+					if bytes.Equal(ffjKeyEnvoyClusterType, kn) {
+						currentKey = ffjtEnvoyClusterType
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyFilterConfig_Tracing, kn) {
-					currentKey = ffj_t_EnvoyFilterConfig_Tracing
+				if fflib.EqualFoldRight(ffjKeyEnvoyClusterHealthCheck, kn) {
+					currentKey = ffjtEnvoyClusterHealthCheck
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyFilterConfig_Filters, kn) {
-					currentKey = ffj_t_EnvoyFilterConfig_Filters
+				if fflib.EqualFoldRight(ffjKeyEnvoyClusterCircuitBreakers, kn) {
+					currentKey = ffjtEnvoyClusterCircuitBreakers
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.AsciiEqualFold(ffj_key_EnvoyFilterConfig_RouteConfig, kn) {
-					currentKey = ffj_t_EnvoyFilterConfig_RouteConfig
+				if fflib.EqualFoldRight(ffjKeyEnvoyClusterServiceName, kn) {
+					currentKey = ffjtEnvoyClusterServiceName
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyFilterConfig_StatPrefix, kn) {
-					currentKey = ffj_t_EnvoyFilterConfig_StatPrefix
+				if fflib.AsciiEqualFold(ffjKeyEnvoyClusterLBType, kn) {
+					currentKey = ffjtEnvoyClusterLBType
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.AsciiEqualFold(ffj_key_EnvoyFilterConfig_CodecType, kn) {
-					currentKey = ffj_t_EnvoyFilterConfig_CodecType
+				if fflib.EqualFoldRight(ffjKeyEnvoyClusterConnectTimeoutMs, kn) {
+					currentKey = ffjtEnvoyClusterConnectTimeoutMs
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyFilterConfigno_such_key
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyClusterType, kn) {
+					currentKey = ffjtEnvoyClusterType
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyClusterName, kn) {
+					currentKey = ffjtEnvoyClusterName
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				currentKey = ffjtEnvoyClusternosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -1182,22 +1016,28 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyFilterConfig_CodecType:
-					goto handle_CodecType
+				case ffjtEnvoyClusterName:
+					goto handle_Name
 
-				case ffj_t_EnvoyFilterConfig_StatPrefix:
-					goto handle_StatPrefix
+				case ffjtEnvoyClusterType:
+					goto handle_Type
 
-				case ffj_t_EnvoyFilterConfig_RouteConfig:
-					goto handle_RouteConfig
+				case ffjtEnvoyClusterConnectTimeoutMs:
+					goto handle_ConnectTimeoutMs
 
-				case ffj_t_EnvoyFilterConfig_Filters:
-					goto handle_Filters
+				case ffjtEnvoyClusterLBType:
+					goto handle_LBType
 
-				case ffj_t_EnvoyFilterConfig_Tracing:
-					goto handle_Tracing
+				case ffjtEnvoyClusterServiceName:
+					goto handle_ServiceName
+
+				case ffjtEnvoyClusterCircuitBreakers:
+					goto handle_CircuitBreakers
+
+				case ffjtEnvoyClusterHealthCheck:
+					goto handle_HealthCheck
 
-				case ffj_t_EnvoyFilterConfigno_such_key:
+				case ffjtEnvoyClusternosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -1211,9 +1051,9 @@ mainparse:
 		}
 	}
 
-handle_CodecType:
+handle_Name:
 
-	/* handler: uj.CodecType type=string kind=string quoted=false*/
+	/* handler: j.Name type=string kind=string quoted=false*/
 
 	{
 
@@ -1229,7 +1069,7 @@ handle_CodecType:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.CodecType = string(string(outBuf))
+			j.Name = string(string(outBuf))
 
 		}
 	}
@@ -1237,9 +1077,9 @@ handle_CodecType:
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_StatPrefix:
+handle_Type:
 
-	/* handler: uj.StatPrefix type=string kind=string quoted=false*/
+	/* handler: j.Type type=string kind=string quoted=false*/
 
 	{
 
@@ -1255,7 +1095,7 @@ handle_StatPrefix:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.StatPrefix = string(string(outBuf))
+			j.Type = string(string(outBuf))
 
 		}
 	}
@@ -1263,128 +1103,133 @@ handle_StatPrefix:
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_RouteConfig:
+handle_ConnectTimeoutMs:
 
-	/* handler: uj.RouteConfig type=envoyhttp.EnvoyRouteConfig kind=struct quoted=false*/
+	/* handler: j.ConnectTimeoutMs type=int64 kind=int64 quoted=false*/
+
+	{
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
+		}
+	}
 
 	{
+
 		if tok == fflib.FFTok_null {
 
-			uj.RouteConfig = nil
+		} else {
 
-			state = fflib.FFParse_after_value
-			goto mainparse
-		}
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
 
-		if uj.RouteConfig == nil {
-			uj.RouteConfig = new(EnvoyRouteConfig)
-		}
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.ConnectTimeoutMs = int64(tval)
 
-		err = uj.RouteConfig.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-		if err != nil {
-			return err
 		}
-		state = fflib.FFParse_after_value
 	}
 
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_Filters:
+handle_LBType:
 
-	/* handler: uj.Filters type=[]*envoyhttp.EnvoyFilter kind=slice quoted=false*/
+	/* handler: j.LBType type=string kind=string quoted=false*/
 
 	{
 
 		{
-			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
 			}
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Filters = nil
+
 		} else {
 
-			uj.Filters = []*EnvoyFilter{}
+			outBuf := fs.Output.Bytes()
 
-			wantVal := true
+			j.LBType = string(string(outBuf))
 
-			for {
+		}
+	}
 
-				var tmp_uj__Filters *EnvoyFilter
+	state = fflib.FFParse_after_value
+	goto mainparse
 
-				tok = fs.Scan()
-				if tok == fflib.FFTok_error {
-					goto tokerror
-				}
-				if tok == fflib.FFTok_right_brace {
-					break
-				}
+handle_ServiceName:
 
-				if tok == fflib.FFTok_comma {
-					if wantVal == true {
-						// TODO(pquerna): this isn't an ideal error message, this handles
-						// things like [,,,] as an array value.
-						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
-					}
-					continue
-				} else {
-					wantVal = true
-				}
+	/* handler: j.ServiceName type=string kind=string quoted=false*/
 
-				/* handler: tmp_uj__Filters type=*envoyhttp.EnvoyFilter kind=ptr quoted=false*/
+	{
 
-				{
-					if tok == fflib.FFTok_null {
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
 
-						tmp_uj__Filters = nil
+		if tok == fflib.FFTok_null {
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+		} else {
 
-					if tmp_uj__Filters == nil {
-						tmp_uj__Filters = new(EnvoyFilter)
-					}
+			outBuf := fs.Output.Bytes()
 
-					err = tmp_uj__Filters.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
-					}
-					state = fflib.FFParse_after_value
-				}
+			j.ServiceName = string(string(outBuf))
 
-				uj.Filters = append(uj.Filters, tmp_uj__Filters)
+		}
+	}
 
-				wantVal = false
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_CircuitBreakers:
+
+	/* handler: j.CircuitBreakers type=envoyhttp.EnvoyCircuitBreakers kind=struct quoted=false*/
+
+	{
+		if tok == fflib.FFTok_null {
+
+			j.CircuitBreakers = nil
+
+		} else {
+
+			if j.CircuitBreakers == nil {
+				j.CircuitBreakers = new(EnvoyCircuitBreakers)
+			}
+
+			err = j.CircuitBreakers.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
 			}
 		}
+		state = fflib.FFParse_after_value
 	}
 
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_Tracing:
+handle_HealthCheck:
 
-	/* handler: uj.Tracing type=envoyhttp.EnvoyTracingConfig kind=struct quoted=false*/
+	/* handler: j.HealthCheck type=envoyhttp.EnvoyHealthCheck kind=struct quoted=false*/
 
 	{
 		if tok == fflib.FFTok_null {
 
-			uj.Tracing = nil
+			j.HealthCheck = nil
 
-			state = fflib.FFParse_after_value
-			goto mainparse
-		}
+		} else {
 
-		if uj.Tracing == nil {
-			uj.Tracing = new(EnvoyTracingConfig)
-		}
+			if j.HealthCheck == nil {
+				j.HealthCheck = new(EnvoyHealthCheck)
+			}
 
-		err = uj.Tracing.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-		if err != nil {
-			return err
+			err = j.HealthCheck.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
 		}
 		state = fflib.FFParse_after_value
 	}
@@ -1410,20 +1255,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyHTTPVirtualHost) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyFilter) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyHTTPVirtualHost) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyFilter) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -1432,75 +1280,48 @@ func (mj *EnvoyHTTPVirtualHost) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{"name":`)
-	fflib.WriteJsonString(buf, string(mj.Name))
-	buf.WriteString(`,"domains":`)
-	if mj.Domains != nil {
-		buf.WriteString(`[`)
-		for i, v := range mj.Domains {
-			if i != 0 {
-				buf.WriteString(`,`)
-			}
-			fflib.WriteJsonString(buf, string(v))
-		}
-		buf.WriteString(`]`)
-	} else {
-		buf.WriteString(`null`)
-	}
-	buf.WriteString(`,"routes":`)
-	if mj.Routes != nil {
-		buf.WriteString(`[`)
-		for i, v := range mj.Routes {
-			if i != 0 {
-				buf.WriteString(`,`)
-			}
-
-			{
-
-				if v == nil {
-					buf.WriteString("null")
-					return nil
-				}
+	fflib.WriteJsonString(buf, string(j.Name))
+	if j.Config != nil {
+		buf.WriteString(`,"config":`)
 
-				err = v.MarshalJSONBuf(buf)
-				if err != nil {
-					return err
-				}
+		{
 
+			err = j.Config.MarshalJSONBuf(buf)
+			if err != nil {
+				return err
 			}
+
 		}
-		buf.WriteString(`]`)
 	} else {
-		buf.WriteString(`null`)
+		buf.WriteString(`,"config":null`)
 	}
 	buf.WriteByte('}')
 	return nil
 }
 
 const (
-	ffj_t_EnvoyHTTPVirtualHostbase = iota
-	ffj_t_EnvoyHTTPVirtualHostno_such_key
+	ffjtEnvoyFilterbase = iota
+	ffjtEnvoyFilternosuchkey
 
-	ffj_t_EnvoyHTTPVirtualHost_Name
+	ffjtEnvoyFilterName
 
-	ffj_t_EnvoyHTTPVirtualHost_Domains
-
-	ffj_t_EnvoyHTTPVirtualHost_Routes
+	ffjtEnvoyFilterConfig
 )
 
-var ffj_key_EnvoyHTTPVirtualHost_Name = []byte("name")
-
-var ffj_key_EnvoyHTTPVirtualHost_Domains = []byte("domains")
+var ffjKeyEnvoyFilterName = []byte("name")
 
-var ffj_key_EnvoyHTTPVirtualHost_Routes = []byte("routes")
+var ffjKeyEnvoyFilterConfig = []byte("config")
 
-func (uj *EnvoyHTTPVirtualHost) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyFilter) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyHTTPVirtualHost) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyHTTPVirtualHostbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyFilter) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyFilterbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -1546,57 +1367,43 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyHTTPVirtualHostno_such_key
+				currentKey = ffjtEnvoyFilternosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
 				switch kn[0] {
 
-				case 'd':
+				case 'c':
 
-					if bytes.Equal(ffj_key_EnvoyHTTPVirtualHost_Domains, kn) {
-						currentKey = ffj_t_EnvoyHTTPVirtualHost_Domains
+					if bytes.Equal(ffjKeyEnvoyFilterConfig, kn) {
+						currentKey = ffjtEnvoyFilterConfig
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'n':
 
-					if bytes.Equal(ffj_key_EnvoyHTTPVirtualHost_Name, kn) {
-						currentKey = ffj_t_EnvoyHTTPVirtualHost_Name
-						state = fflib.FFParse_want_colon
-						goto mainparse
-					}
-
-				case 'r':
-
-					if bytes.Equal(ffj_key_EnvoyHTTPVirtualHost_Routes, kn) {
-						currentKey = ffj_t_EnvoyHTTPVirtualHost_Routes
+					if bytes.Equal(ffjKeyEnvoyFilterName, kn) {
+						currentKey = ffjtEnvoyFilterName
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyHTTPVirtualHost_Routes, kn) {
-					currentKey = ffj_t_EnvoyHTTPVirtualHost_Routes
-					state = fflib.FFParse_want_colon
-					goto mainparse
-				}
-
-				if fflib.EqualFoldRight(ffj_key_EnvoyHTTPVirtualHost_Domains, kn) {
-					currentKey = ffj_t_EnvoyHTTPVirtualHost_Domains
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyFilterConfig, kn) {
+					currentKey = ffjtEnvoyFilterConfig
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyHTTPVirtualHost_Name, kn) {
-					currentKey = ffj_t_EnvoyHTTPVirtualHost_Name
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyFilterName, kn) {
+					currentKey = ffjtEnvoyFilterName
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyHTTPVirtualHostno_such_key
+				currentKey = ffjtEnvoyFilternosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -1613,16 +1420,13 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyHTTPVirtualHost_Name:
+				case ffjtEnvoyFilterName:
 					goto handle_Name
 
-				case ffj_t_EnvoyHTTPVirtualHost_Domains:
-					goto handle_Domains
-
-				case ffj_t_EnvoyHTTPVirtualHost_Routes:
-					goto handle_Routes
+				case ffjtEnvoyFilterConfig:
+					goto handle_Config
 
-				case ffj_t_EnvoyHTTPVirtualHostno_such_key:
+				case ffjtEnvoyFilternosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -1638,7 +1442,7 @@ mainparse:
 
 handle_Name:
 
-	/* handler: uj.Name type=string kind=string quoted=false*/
+	/* handler: j.Name type=string kind=string quoted=false*/
 
 	{
 
@@ -1654,7 +1458,7 @@ handle_Name:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Name = string(string(outBuf))
+			j.Name = string(string(outBuf))
 
 		}
 	}
@@ -1662,50 +1466,1665 @@ handle_Name:
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_Domains:
+handle_Config:
 
-	/* handler: uj.Domains type=[]string kind=slice quoted=false*/
+	/* handler: j.Config type=envoyhttp.EnvoyFilterConfig kind=struct quoted=false*/
 
 	{
+		if tok == fflib.FFTok_null {
 
-		{
-			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
-			}
-		}
+			j.Config = nil
 
-		if tok == fflib.FFTok_null {
-			uj.Domains = nil
 		} else {
 
-			uj.Domains = []string{}
+			if j.Config == nil {
+				j.Config = new(EnvoyFilterConfig)
+			}
 
-			wantVal := true
+			err = j.Config.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
+		}
+		state = fflib.FFParse_after_value
+	}
 
-			for {
+	state = fflib.FFParse_after_value
+	goto mainparse
 
-				var tmp_uj__Domains string
+wantedvalue:
+	return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+wrongtokenerror:
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v output=%s", wantedTok, tok, fs.Output.String()))
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+done:
 
-				tok = fs.Scan()
-				if tok == fflib.FFTok_error {
-					goto tokerror
-				}
-				if tok == fflib.FFTok_right_brace {
-					break
-				}
+	return nil
+}
 
-				if tok == fflib.FFTok_comma {
-					if wantVal == true {
-						// TODO(pquerna): this isn't an ideal error message, this handles
-						// things like [,,,] as an array value.
-						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
-					}
-					continue
-				} else {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyFilterConfig) MarshalJSON() ([]byte, error) {
+	var buf fflib.Buffer
+	if j == nil {
+		buf.WriteString("null")
+		return buf.Bytes(), nil
+	}
+	err := j.MarshalJSONBuf(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyFilterConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	var err error
+	var obj []byte
+	_ = obj
+	_ = err
+	buf.WriteString(`{ `)
+	if len(j.CodecType) != 0 {
+		buf.WriteString(`"codec_type":`)
+		fflib.WriteJsonString(buf, string(j.CodecType))
+		buf.WriteByte(',')
+	}
+	if len(j.StatPrefix) != 0 {
+		buf.WriteString(`"stat_prefix":`)
+		fflib.WriteJsonString(buf, string(j.StatPrefix))
+		buf.WriteByte(',')
+	}
+	if j.RouteConfig != nil {
+		if true {
+			buf.WriteString(`"route_config":`)
+
+			{
+
+				err = j.RouteConfig.MarshalJSONBuf(buf)
+				if err != nil {
+					return err
+				}
+
+			}
+			buf.WriteByte(',')
+		}
+	}
+	if len(j.Filters) != 0 {
+		buf.WriteString(`"filters":`)
+		if j.Filters != nil {
+			buf.WriteString(`[`)
+			for i, v := range j.Filters {
+				if i != 0 {
+					buf.WriteString(`,`)
+				}
+
+				{
+
+					if v == nil {
+						buf.WriteString("null")
+					} else {
+
+						err = v.MarshalJSONBuf(buf)
+						if err != nil {
+							return err
+						}
+
+					}
+
+				}
+			}
+			buf.WriteString(`]`)
+		} else {
+			buf.WriteString(`null`)
+		}
+		buf.WriteByte(',')
+	}
+	if j.Tracing != nil {
+		if true {
+			buf.WriteString(`"tracing":`)
+
+			{
+
+				err = j.Tracing.MarshalJSONBuf(buf)
+				if err != nil {
+					return err
+				}
+
+			}
+			buf.WriteByte(',')
+		}
+	}
+	if j.IdleTimeoutS != 0 {
+		buf.WriteString(`"idle_timeout_s":`)
+		fflib.FormatBits2(buf, uint64(j.IdleTimeoutS), 10, j.IdleTimeoutS < 0)
+		buf.WriteByte(',')
+	}
+	buf.Rewind(1)
+	buf.WriteByte('}')
+	return nil
+}
+
+const (
+	ffjtEnvoyFilterConfigbase = iota
+	ffjtEnvoyFilterConfignosuchkey
+
+	ffjtEnvoyFilterConfigCodecType
+
+	ffjtEnvoyFilterConfigStatPrefix
+
+	ffjtEnvoyFilterConfigRouteConfig
+
+	ffjtEnvoyFilterConfigFilters
+
+	ffjtEnvoyFilterConfigTracing
+
+	ffjtEnvoyFilterConfigIdleTimeoutS
+)
+
+var ffjKeyEnvoyFilterConfigCodecType = []byte("codec_type")
+
+var ffjKeyEnvoyFilterConfigStatPrefix = []byte("stat_prefix")
+
+var ffjKeyEnvoyFilterConfigRouteConfig = []byte("route_config")
+
+var ffjKeyEnvoyFilterConfigFilters = []byte("filters")
+
+var ffjKeyEnvoyFilterConfigTracing = []byte("tracing")
+
+var ffjKeyEnvoyFilterConfigIdleTimeoutS = []byte("idle_timeout_s")
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyFilterConfig) UnmarshalJSON(input []byte) error {
+	fs := fflib.NewFFLexer(input)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+}
+
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyFilterConfig) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyFilterConfigbase
+	_ = currentKey
+	tok := fflib.FFTok_init
+	wantedTok := fflib.FFTok_init
+
+mainparse:
+	for {
+		tok = fs.Scan()
+		//	println(fmt.Sprintf("debug: tok: %v  state: %v", tok, state))
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+
+		switch state {
+
+		case fflib.FFParse_map_start:
+			if tok != fflib.FFTok_left_bracket {
+				wantedTok = fflib.FFTok_left_bracket
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_key
+			continue
+
+		case fflib.FFParse_after_value:
+			if tok == fflib.FFTok_comma {
+				state = fflib.FFParse_want_key
+			} else if tok == fflib.FFTok_right_bracket {
+				goto done
+			} else {
+				wantedTok = fflib.FFTok_comma
+				goto wrongtokenerror
+			}
+
+		case fflib.FFParse_want_key:
+			// json {} ended. goto exit. woo.
+			if tok == fflib.FFTok_right_bracket {
+				goto done
+			}
+			if tok != fflib.FFTok_string {
+				wantedTok = fflib.FFTok_string
+				goto wrongtokenerror
+			}
+
+			kn := fs.Output.Bytes()
+			if len(kn) <= 0 {
+				// "" case. hrm.
+				currentKey = ffjtEnvoyFilterConfignosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			} else {
+				switch kn[0] {
+
+				case 'c':
+
+					if bytes.Equal(ffjKeyEnvoyFilterConfigCodecType, kn) {
+						currentKey = ffjtEnvoyFilterConfigCodecType
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'f':
+
+					if bytes.Equal(ffjKeyEnvoyFilterConfigFilters, kn) {
+						currentKey = ffjtEnvoyFilterConfigFilters
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'i':
+
+					if bytes.Equal(ffjKeyEnvoyFilterConfigIdleTimeoutS, kn) {
+						currentKey = ffjtEnvoyFilterConfigIdleTimeoutS
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'r':
+
+					if bytes.Equal(ffjKeyEnvoyFilterConfigRouteConfig, kn) {
+						currentKey = ffjtEnvoyFilterConfigRouteConfig
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 's':
+
+					if bytes.Equal(ffjKeyEnvoyFilterConfigStatPrefix, kn) {
+						currentKey = ffjtEnvoyFilterConfigStatPrefix
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 't':
+
+					if bytes.Equal(ffjKeyEnvoyFilterConfigTracing, kn) {
+						currentKey = ffjtEnvoyFilterConfigTracing
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyFilterConfigIdleTimeoutS, kn) {
+					currentKey = ffjtEnvoyFilterConfigIdleTimeoutS
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyFilterConfigTracing, kn) {
+					currentKey = ffjtEnvoyFilterConfigTracing
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyFilterConfigFilters, kn) {
+					currentKey = ffjtEnvoyFilterConfigFilters
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.AsciiEqualFold(ffjKeyEnvoyFilterConfigRouteConfig, kn) {
+					currentKey = ffjtEnvoyFilterConfigRouteConfig
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyFilterConfigStatPrefix, kn) {
+					currentKey = ffjtEnvoyFilterConfigStatPrefix
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.AsciiEqualFold(ffjKeyEnvoyFilterConfigCodecType, kn) {
+					currentKey = ffjtEnvoyFilterConfigCodecType
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				currentKey = ffjtEnvoyFilterConfignosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			}
+
+		case fflib.FFParse_want_colon:
+			if tok != fflib.FFTok_colon {
+				wantedTok = fflib.FFTok_colon
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_value
+			continue
+		case fflib.FFParse_want_value:
+
+			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
+				switch currentKey {
+
+				case ffjtEnvoyFilterConfigCodecType:
+					goto handle_CodecType
+
+				case ffjtEnvoyFilterConfigStatPrefix:
+					goto handle_StatPrefix
+
+				case ffjtEnvoyFilterConfigRouteConfig:
+					goto handle_RouteConfig
+
+				case ffjtEnvoyFilterConfigFilters:
+					goto handle_Filters
+
+				case ffjtEnvoyFilterConfigTracing:
+					goto handle_Tracing
+
+				case ffjtEnvoyFilterConfigIdleTimeoutS:
+					goto handle_IdleTimeoutS
+
+				case ffjtEnvoyFilterConfignosuchkey:
+					err = fs.SkipField(tok)
+					if err != nil {
+						return fs.WrapErr(err)
+					}
+					state = fflib.FFParse_after_value
+					goto mainparse
+				}
+			} else {
+				goto wantedvalue
+			}
+		}
+	}
+
+handle_CodecType:
+
+	/* handler: j.CodecType type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.CodecType = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_StatPrefix:
+
+	/* handler: j.StatPrefix type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.StatPrefix = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_RouteConfig:
+
+	/* handler: j.RouteConfig type=envoyhttp.EnvoyRouteConfig kind=struct quoted=false*/
+
+	{
+		if tok == fflib.FFTok_null {
+
+			j.RouteConfig = nil
+
+		} else {
+
+			if j.RouteConfig == nil {
+				j.RouteConfig = new(EnvoyRouteConfig)
+			}
+
+			err = j.RouteConfig.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
+		}
+		state = fflib.FFParse_after_value
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Filters:
+
+	/* handler: j.Filters type=[]*envoyhttp.EnvoyFilter kind=slice quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+			j.Filters = nil
+		} else {
+
+			j.Filters = []*EnvoyFilter{}
+
+			wantVal := true
+
+			for {
+
+				var tmpJFilters *EnvoyFilter
+
+				tok = fs.Scan()
+				if tok == fflib.FFTok_error {
+					goto tokerror
+				}
+				if tok == fflib.FFTok_right_brace {
+					break
+				}
+
+				if tok == fflib.FFTok_comma {
+					if wantVal == true {
+						// TODO(pquerna): this isn't an ideal error message, this handles
+						// things like [,,,] as an array value.
+						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+					}
+					continue
+				} else {
+					wantVal = true
+				}
+
+				/* handler: tmpJFilters type=*envoyhttp.EnvoyFilter kind=ptr quoted=false*/
+
+				{
+					if tok == fflib.FFTok_null {
+
+						tmpJFilters = nil
+
+					} else {
+
+						if tmpJFilters == nil {
+							tmpJFilters = new(EnvoyFilter)
+						}
+
+						err = tmpJFilters.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
+					}
+					state = fflib.FFParse_after_value
+				}
+
+				j.Filters = append(j.Filters, tmpJFilters)
+
+				wantVal = false
+			}
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Tracing:
+
+	/* handler: j.Tracing type=envoyhttp.EnvoyTracingConfig kind=struct quoted=false*/
+
+	{
+		if tok == fflib.FFTok_null {
+
+			j.Tracing = nil
+
+		} else {
+
+			if j.Tracing == nil {
+				j.Tracing = new(EnvoyTracingConfig)
+			}
+
+			err = j.Tracing.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
+		}
+		state = fflib.FFParse_after_value
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_IdleTimeoutS:
+
+	/* handler: j.IdleTimeoutS type=int64 kind=int64 quoted=false*/
+
+	{
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
+		}
+	}
+
+	{
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.IdleTimeoutS = int64(tval)
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+wantedvalue:
+	return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+wrongtokenerror:
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v output=%s", wantedTok, tok, fs.Output.String()))
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+done:
+
+	return nil
+}
+
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyHTTPVirtualHost) MarshalJSON() ([]byte, error) {
+	var buf fflib.Buffer
+	if j == nil {
+		buf.WriteString("null")
+		return buf.Bytes(), nil
+	}
+	err := j.MarshalJSONBuf(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyHTTPVirtualHost) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	var err error
+	var obj []byte
+	_ = obj
+	_ = err
+	buf.WriteString(`{"name":`)
+	fflib.WriteJsonString(buf, string(j.Name))
+	buf.WriteString(`,"domains":`)
+	if j.Domains != nil {
+		buf.WriteString(`[`)
+		for i, v := range j.Domains {
+			if i != 0 {
+				buf.WriteString(`,`)
+			}
+			fflib.WriteJsonString(buf, string(v))
+		}
+		buf.WriteString(`]`)
+	} else {
+		buf.WriteString(`null`)
+	}
+	buf.WriteString(`,"routes":`)
+	if j.Routes != nil {
+		buf.WriteString(`[`)
+		for i, v := range j.Routes {
+			if i != 0 {
+				buf.WriteString(`,`)
+			}
+
+			{
+
+				if v == nil {
+					buf.WriteString("null")
+				} else {
+
+					err = v.MarshalJSONBuf(buf)
+					if err != nil {
+						return err
+					}
+
+				}
+
+			}
+		}
+		buf.WriteString(`]`)
+	} else {
+		buf.WriteString(`null`)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+const (
+	ffjtEnvoyHTTPVirtualHostbase = iota
+	ffjtEnvoyHTTPVirtualHostnosuchkey
+
+	ffjtEnvoyHTTPVirtualHostName
+
+	ffjtEnvoyHTTPVirtualHostDomains
+
+	ffjtEnvoyHTTPVirtualHostRoutes
+)
+
+var ffjKeyEnvoyHTTPVirtualHostName = []byte("name")
+
+var ffjKeyEnvoyHTTPVirtualHostDomains = []byte("domains")
+
+var ffjKeyEnvoyHTTPVirtualHostRoutes = []byte("routes")
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyHTTPVirtualHost) UnmarshalJSON(input []byte) error {
+	fs := fflib.NewFFLexer(input)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+}
+
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyHTTPVirtualHost) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyHTTPVirtualHostbase
+	_ = currentKey
+	tok := fflib.FFTok_init
+	wantedTok := fflib.FFTok_init
+
+mainparse:
+	for {
+		tok = fs.Scan()
+		//	println(fmt.Sprintf("debug: tok: %v  state: %v", tok, state))
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+
+		switch state {
+
+		case fflib.FFParse_map_start:
+			if tok != fflib.FFTok_left_bracket {
+				wantedTok = fflib.FFTok_left_bracket
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_key
+			continue
+
+		case fflib.FFParse_after_value:
+			if tok == fflib.FFTok_comma {
+				state = fflib.FFParse_want_key
+			} else if tok == fflib.FFTok_right_bracket {
+				goto done
+			} else {
+				wantedTok = fflib.FFTok_comma
+				goto wrongtokenerror
+			}
+
+		case fflib.FFParse_want_key:
+			// json {} ended. goto exit. woo.
+			if tok == fflib.FFTok_right_bracket {
+				goto done
+			}
+			if tok != fflib.FFTok_string {
+				wantedTok = fflib.FFTok_string
+				goto wrongtokenerror
+			}
+
+			kn := fs.Output.Bytes()
+			if len(kn) <= 0 {
+				// "" case. hrm.
+				currentKey = ffjtEnvoyHTTPVirtualHostnosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			} else {
+				switch kn[0] {
+
+				case 'd':
+
+					if bytes.Equal(ffjKeyEnvoyHTTPVirtualHostDomains, kn) {
+						currentKey = ffjtEnvoyHTTPVirtualHostDomains
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'n':
+
+					if bytes.Equal(ffjKeyEnvoyHTTPVirtualHostName, kn) {
+						currentKey = ffjtEnvoyHTTPVirtualHostName
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'r':
+
+					if bytes.Equal(ffjKeyEnvoyHTTPVirtualHostRoutes, kn) {
+						currentKey = ffjtEnvoyHTTPVirtualHostRoutes
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHTTPVirtualHostRoutes, kn) {
+					currentKey = ffjtEnvoyHTTPVirtualHostRoutes
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHTTPVirtualHostDomains, kn) {
+					currentKey = ffjtEnvoyHTTPVirtualHostDomains
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyHTTPVirtualHostName, kn) {
+					currentKey = ffjtEnvoyHTTPVirtualHostName
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				currentKey = ffjtEnvoyHTTPVirtualHostnosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			}
+
+		case fflib.FFParse_want_colon:
+			if tok != fflib.FFTok_colon {
+				wantedTok = fflib.FFTok_colon
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_value
+			continue
+		case fflib.FFParse_want_value:
+
+			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
+				switch currentKey {
+
+				case ffjtEnvoyHTTPVirtualHostName:
+					goto handle_Name
+
+				case ffjtEnvoyHTTPVirtualHostDomains:
+					goto handle_Domains
+
+				case ffjtEnvoyHTTPVirtualHostRoutes:
+					goto handle_Routes
+
+				case ffjtEnvoyHTTPVirtualHostnosuchkey:
+					err = fs.SkipField(tok)
+					if err != nil {
+						return fs.WrapErr(err)
+					}
+					state = fflib.FFParse_after_value
+					goto mainparse
+				}
+			} else {
+				goto wantedvalue
+			}
+		}
+	}
+
+handle_Name:
+
+	/* handler: j.Name type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.Name = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Domains:
+
+	/* handler: j.Domains type=[]string kind=slice quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+			j.Domains = nil
+		} else {
+
+			j.Domains = []string{}
+
+			wantVal := true
+
+			for {
+
+				var tmpJDomains string
+
+				tok = fs.Scan()
+				if tok == fflib.FFTok_error {
+					goto tokerror
+				}
+				if tok == fflib.FFTok_right_brace {
+					break
+				}
+
+				if tok == fflib.FFTok_comma {
+					if wantVal == true {
+						// TODO(pquerna): this isn't an ideal error message, this handles
+						// things like [,,,] as an array value.
+						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+					}
+					continue
+				} else {
+					wantVal = true
+				}
+
+				/* handler: tmpJDomains type=string kind=string quoted=false*/
+
+				{
+
+					{
+						if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+							return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+						}
+					}
+
+					if tok == fflib.FFTok_null {
+
+					} else {
+
+						outBuf := fs.Output.Bytes()
+
+						tmpJDomains = string(string(outBuf))
+
+					}
+				}
+
+				j.Domains = append(j.Domains, tmpJDomains)
+
+				wantVal = false
+			}
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Routes:
+
+	/* handler: j.Routes type=[]*envoyhttp.EnvoyRoute kind=slice quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+			j.Routes = nil
+		} else {
+
+			j.Routes = []*EnvoyRoute{}
+
+			wantVal := true
+
+			for {
+
+				var tmpJRoutes *EnvoyRoute
+
+				tok = fs.Scan()
+				if tok == fflib.FFTok_error {
+					goto tokerror
+				}
+				if tok == fflib.FFTok_right_brace {
+					break
+				}
+
+				if tok == fflib.FFTok_comma {
+					if wantVal == true {
+						// TODO(pquerna): this isn't an ideal error message, this handles
+						// things like [,,,] as an array value.
+						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+					}
+					continue
+				} else {
+					wantVal = true
+				}
+
+				/* handler: tmpJRoutes type=*envoyhttp.EnvoyRoute kind=ptr quoted=false*/
+
+				{
+					if tok == fflib.FFTok_null {
+
+						tmpJRoutes = nil
+
+					} else {
+
+						if tmpJRoutes == nil {
+							tmpJRoutes = new(EnvoyRoute)
+						}
+
+						err = tmpJRoutes.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
+					}
+					state = fflib.FFParse_after_value
+				}
+
+				j.Routes = append(j.Routes, tmpJRoutes)
+
+				wantVal = false
+			}
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+wantedvalue:
+	return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+wrongtokenerror:
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v output=%s", wantedTok, tok, fs.Output.String()))
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+done:
+
+	return nil
+}
+
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyHealthCheck) MarshalJSON() ([]byte, error) {
+	var buf fflib.Buffer
+	if j == nil {
+		buf.WriteString("null")
+		return buf.Bytes(), nil
+	}
+	err := j.MarshalJSONBuf(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyHealthCheck) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	var err error
+	var obj []byte
+	_ = obj
+	_ = err
+	buf.WriteString(`{ "type":`)
+	fflib.WriteJsonString(buf, string(j.Type))
+	buf.WriteString(`,"timeout_ms":`)
+	fflib.FormatBits2(buf, uint64(j.TimeoutMs), 10, j.TimeoutMs < 0)
+	buf.WriteString(`,"interval_ms":`)
+	fflib.FormatBits2(buf, uint64(j.IntervalMs), 10, j.IntervalMs < 0)
+	buf.WriteString(`,"unhealthy_threshold":`)
+	fflib.FormatBits2(buf, uint64(j.UnhealthyThreshold), 10, j.UnhealthyThreshold < 0)
+	buf.WriteString(`,"healthy_threshold":`)
+	fflib.FormatBits2(buf, uint64(j.HealthyThreshold), 10, j.HealthyThreshold < 0)
+	buf.WriteByte(',')
+	if len(j.Path) != 0 {
+		buf.WriteString(`"path":`)
+		fflib.WriteJsonString(buf, string(j.Path))
+		buf.WriteByte(',')
+	}
+	if j.Send != nil {
+		if true {
+			buf.WriteString(`"send":`)
+			if j.Send != nil {
+				buf.WriteString(`[`)
+				for i, v := range *j.Send {
+					if i != 0 {
+						buf.WriteString(`,`)
+					}
+					fflib.WriteJsonString(buf, string(v))
+				}
+				buf.WriteString(`]`)
+			} else {
+				buf.WriteString(`null`)
+			}
+			buf.WriteByte(',')
+		}
+	}
+	if j.Receive != nil {
+		if true {
+			buf.WriteString(`"receive":`)
+			if j.Receive != nil {
+				buf.WriteString(`[`)
+				for i, v := range *j.Receive {
+					if i != 0 {
+						buf.WriteString(`,`)
+					}
+					fflib.WriteJsonString(buf, string(v))
+				}
+				buf.WriteString(`]`)
+			} else {
+				buf.WriteString(`null`)
+			}
+			buf.WriteByte(',')
+		}
+	}
+	buf.Rewind(1)
+	buf.WriteByte('}')
+	return nil
+}
+
+const (
+	ffjtEnvoyHealthCheckbase = iota
+	ffjtEnvoyHealthChecknosuchkey
+
+	ffjtEnvoyHealthCheckType
+
+	ffjtEnvoyHealthCheckTimeoutMs
+
+	ffjtEnvoyHealthCheckIntervalMs
+
+	ffjtEnvoyHealthCheckUnhealthyThreshold
+
+	ffjtEnvoyHealthCheckHealthyThreshold
+
+	ffjtEnvoyHealthCheckPath
+
+	ffjtEnvoyHealthCheckSend
+
+	ffjtEnvoyHealthCheckReceive
+)
+
+var ffjKeyEnvoyHealthCheckType = []byte("type")
+
+var ffjKeyEnvoyHealthCheckTimeoutMs = []byte("timeout_ms")
+
+var ffjKeyEnvoyHealthCheckIntervalMs = []byte("interval_ms")
+
+var ffjKeyEnvoyHealthCheckUnhealthyThreshold = []byte("unhealthy_threshold")
+
+var ffjKeyEnvoyHealthCheckHealthyThreshold = []byte("healthy_threshold")
+
+var ffjKeyEnvoyHealthCheckPath = []byte("path")
+
+var ffjKeyEnvoyHealthCheckSend = []byte("send")
+
+var ffjKeyEnvoyHealthCheckReceive = []byte("receive")
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyHealthCheck) UnmarshalJSON(input []byte) error {
+	fs := fflib.NewFFLexer(input)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+}
+
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyHealthCheck) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyHealthCheckbase
+	_ = currentKey
+	tok := fflib.FFTok_init
+	wantedTok := fflib.FFTok_init
+
+mainparse:
+	for {
+		tok = fs.Scan()
+		//	println(fmt.Sprintf("debug: tok: %v  state: %v", tok, state))
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+
+		switch state {
+
+		case fflib.FFParse_map_start:
+			if tok != fflib.FFTok_left_bracket {
+				wantedTok = fflib.FFTok_left_bracket
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_key
+			continue
+
+		case fflib.FFParse_after_value:
+			if tok == fflib.FFTok_comma {
+				state = fflib.FFParse_want_key
+			} else if tok == fflib.FFTok_right_bracket {
+				goto done
+			} else {
+				wantedTok = fflib.FFTok_comma
+				goto wrongtokenerror
+			}
+
+		case fflib.FFParse_want_key:
+			// json {} ended. goto exit. woo.
+			if tok == fflib.FFTok_right_bracket {
+				goto done
+			}
+			if tok != fflib.FFTok_string {
+				wantedTok = fflib.FFTok_string
+				goto wrongtokenerror
+			}
+
+			kn := fs.Output.Bytes()
+			if len(kn) <= 0 {
+				// "" case. hrm.
+				currentKey = ffjtEnvoyHealthChecknosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			} else {
+				switch kn[0] {
+
+				case 'h':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckHealthyThreshold, kn) {
+						currentKey = ffjtEnvoyHealthCheckHealthyThreshold
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'i':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckIntervalMs, kn) {
+						currentKey = ffjtEnvoyHealthCheckIntervalMs
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'p':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckPath, kn) {
+						currentKey = ffjtEnvoyHealthCheckPath
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'r':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckReceive, kn) {
+						currentKey = ffjtEnvoyHealthCheckReceive
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 's':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckSend, kn) {
+						currentKey = ffjtEnvoyHealthCheckSend
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 't':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckType, kn) {
+						currentKey = ffjtEnvoyHealthCheckType
+						state = fflib.FFParse_want_colon
+						goto mainparse
+
+					} else if bytes.Equal(ffjKeyEnvoyHealthCheckTimeoutMs, kn) {
+						currentKey = ffjtEnvoyHealthCheckTimeoutMs
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'u':
+
+					if bytes.Equal(ffjKeyEnvoyHealthCheckUnhealthyThreshold, kn) {
+						currentKey = ffjtEnvoyHealthCheckUnhealthyThreshold
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyHealthCheckReceive, kn) {
+					currentKey = ffjtEnvoyHealthCheckReceive
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHealthCheckSend, kn) {
+					currentKey = ffjtEnvoyHealthCheckSend
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyHealthCheckPath, kn) {
+					currentKey = ffjtEnvoyHealthCheckPath
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHealthCheckHealthyThreshold, kn) {
+					currentKey = ffjtEnvoyHealthCheckHealthyThreshold
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHealthCheckUnhealthyThreshold, kn) {
+					currentKey = ffjtEnvoyHealthCheckUnhealthyThreshold
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHealthCheckIntervalMs, kn) {
+					currentKey = ffjtEnvoyHealthCheckIntervalMs
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyHealthCheckTimeoutMs, kn) {
+					currentKey = ffjtEnvoyHealthCheckTimeoutMs
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyHealthCheckType, kn) {
+					currentKey = ffjtEnvoyHealthCheckType
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				currentKey = ffjtEnvoyHealthChecknosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			}
+
+		case fflib.FFParse_want_colon:
+			if tok != fflib.FFTok_colon {
+				wantedTok = fflib.FFTok_colon
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_value
+			continue
+		case fflib.FFParse_want_value:
+
+			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
+				switch currentKey {
+
+				case ffjtEnvoyHealthCheckType:
+					goto handle_Type
+
+				case ffjtEnvoyHealthCheckTimeoutMs:
+					goto handle_TimeoutMs
+
+				case ffjtEnvoyHealthCheckIntervalMs:
+					goto handle_IntervalMs
+
+				case ffjtEnvoyHealthCheckUnhealthyThreshold:
+					goto handle_UnhealthyThreshold
+
+				case ffjtEnvoyHealthCheckHealthyThreshold:
+					goto handle_HealthyThreshold
+
+				case ffjtEnvoyHealthCheckPath:
+					goto handle_Path
+
+				case ffjtEnvoyHealthCheckSend:
+					goto handle_Send
+
+				case ffjtEnvoyHealthCheckReceive:
+					goto handle_Receive
+
+				case ffjtEnvoyHealthChecknosuchkey:
+					err = fs.SkipField(tok)
+					if err != nil {
+						return fs.WrapErr(err)
+					}
+					state = fflib.FFParse_after_value
+					goto mainparse
+				}
+			} else {
+				goto wantedvalue
+			}
+		}
+	}
+
+handle_Type:
+
+	/* handler: j.Type type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.Type = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_TimeoutMs:
+
+	/* handler: j.TimeoutMs type=int64 kind=int64 quoted=false*/
+
+	{
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
+		}
+	}
+
+	{
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.TimeoutMs = int64(tval)
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_IntervalMs:
+
+	/* handler: j.IntervalMs type=int64 kind=int64 quoted=false*/
+
+	{
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
+		}
+	}
+
+	{
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.IntervalMs = int64(tval)
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_UnhealthyThreshold:
+
+	/* handler: j.UnhealthyThreshold type=int64 kind=int64 quoted=false*/
+
+	{
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
+		}
+	}
+
+	{
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.UnhealthyThreshold = int64(tval)
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_HealthyThreshold:
+
+	/* handler: j.HealthyThreshold type=int64 kind=int64 quoted=false*/
+
+	{
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int64", tok))
+		}
+	}
+
+	{
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+
+			j.HealthyThreshold = int64(tval)
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Path:
+
+	/* handler: j.Path type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.Path = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Send:
+
+	/* handler: j.Send type=[]string kind=slice quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+			j.Send = nil
+		} else {
+
+			j.Send = &[]string{}
+
+			wantVal := true
+
+			for {
+
+				var tmpJSend string
+
+				tok = fs.Scan()
+				if tok == fflib.FFTok_error {
+					goto tokerror
+				}
+				if tok == fflib.FFTok_right_brace {
+					break
+				}
+
+				if tok == fflib.FFTok_comma {
+					if wantVal == true {
+						// TODO(pquerna): this isn't an ideal error message, this handles
+						// things like [,,,] as an array value.
+						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+					}
+					continue
+				} else {
+					wantVal = true
+				}
+
+				/* handler: tmpJSend type=string kind=string quoted=false*/
+
+				{
+
+					{
+						if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+							return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+						}
+					}
+
+					if tok == fflib.FFTok_null {
+
+					} else {
+
+						outBuf := fs.Output.Bytes()
+
+						tmpJSend = string(string(outBuf))
+
+					}
+				}
+
+				*j.Send = append(*j.Send, tmpJSend)
+
+				wantVal = false
+			}
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Receive:
+
+	/* handler: j.Receive type=[]string kind=slice quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+			j.Receive = nil
+		} else {
+
+			j.Receive = &[]string{}
+
+			wantVal := true
+
+			for {
+
+				var tmpJReceive string
+
+				tok = fs.Scan()
+				if tok == fflib.FFTok_error {
+					goto tokerror
+				}
+				if tok == fflib.FFTok_right_brace {
+					break
+				}
+
+				if tok == fflib.FFTok_comma {
+					if wantVal == true {
+						// TODO(pquerna): this isn't an ideal error message, this handles
+						// things like [,,,] as an array value.
+						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+					}
+					continue
+				} else {
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__Domains type=string kind=string quoted=false*/
+				/* handler: tmpJReceive type=string kind=string quoted=false*/
 
 				{
 
@@ -1721,12 +3140,12 @@ handle_Domains:
 
 						outBuf := fs.Output.Bytes()
 
-						tmp_uj__Domains = string(string(outBuf))
+						tmpJReceive = string(string(outBuf))
 
 					}
 				}
 
-				uj.Domains = append(uj.Domains, tmp_uj__Domains)
+				*j.Receive = append(*j.Receive, tmpJReceive)
 
 				wantVal = false
 			}
@@ -1736,9 +3155,300 @@ handle_Domains:
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_Routes:
+wantedvalue:
+	return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+wrongtokenerror:
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v output=%s", wantedTok, tok, fs.Output.String()))
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+done:
+
+	return nil
+}
+
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyListener) MarshalJSON() ([]byte, error) {
+	var buf fflib.Buffer
+	if j == nil {
+		buf.WriteString("null")
+		return buf.Bytes(), nil
+	}
+	err := j.MarshalJSONBuf(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyListener) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	var err error
+	var obj []byte
+	_ = obj
+	_ = err
+	buf.WriteString(`{"name":`)
+	fflib.WriteJsonString(buf, string(j.Name))
+	buf.WriteString(`,"address":`)
+	fflib.WriteJsonString(buf, string(j.Address))
+	buf.WriteString(`,"filters":`)
+	if j.Filters != nil {
+		buf.WriteString(`[`)
+		for i, v := range j.Filters {
+			if i != 0 {
+				buf.WriteString(`,`)
+			}
+
+			{
+
+				if v == nil {
+					buf.WriteString("null")
+				} else {
+
+					err = v.MarshalJSONBuf(buf)
+					if err != nil {
+						return err
+					}
+
+				}
+
+			}
+		}
+		buf.WriteString(`]`)
+	} else {
+		buf.WriteString(`null`)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+const (
+	ffjtEnvoyListenerbase = iota
+	ffjtEnvoyListenernosuchkey
+
+	ffjtEnvoyListenerName
+
+	ffjtEnvoyListenerAddress
+
+	ffjtEnvoyListenerFilters
+)
+
+var ffjKeyEnvoyListenerName = []byte("name")
+
+var ffjKeyEnvoyListenerAddress = []byte("address")
+
+var ffjKeyEnvoyListenerFilters = []byte("filters")
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyListener) UnmarshalJSON(input []byte) error {
+	fs := fflib.NewFFLexer(input)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+}
+
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyListener) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyListenerbase
+	_ = currentKey
+	tok := fflib.FFTok_init
+	wantedTok := fflib.FFTok_init
+
+mainparse:
+	for {
+		tok = fs.Scan()
+		//	println(fmt.Sprintf("debug: tok: %v  state: %v", tok, state))
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+
+		switch state {
+
+		case fflib.FFParse_map_start:
+			if tok != fflib.FFTok_left_bracket {
+				wantedTok = fflib.FFTok_left_bracket
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_key
+			continue
+
+		case fflib.FFParse_after_value:
+			if tok == fflib.FFTok_comma {
+				state = fflib.FFParse_want_key
+			} else if tok == fflib.FFTok_right_bracket {
+				goto done
+			} else {
+				wantedTok = fflib.FFTok_comma
+				goto wrongtokenerror
+			}
+
+		case fflib.FFParse_want_key:
+			// json {} ended. goto exit. woo.
+			if tok == fflib.FFTok_right_bracket {
+				goto done
+			}
+			if tok != fflib.FFTok_string {
+				wantedTok = fflib.FFTok_string
+				goto wrongtokenerror
+			}
+
+			kn := fs.Output.Bytes()
+			if len(kn) <= 0 {
+				// "" case. hrm.
+				currentKey = ffjtEnvoyListenernosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			} else {
+				switch kn[0] {
+
+				case 'a':
+
+					if bytes.Equal(ffjKeyEnvoyListenerAddress, kn) {
+						currentKey = ffjtEnvoyListenerAddress
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'f':
+
+					if bytes.Equal(ffjKeyEnvoyListenerFilters, kn) {
+						currentKey = ffjtEnvoyListenerFilters
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'n':
+
+					if bytes.Equal(ffjKeyEnvoyListenerName, kn) {
+						currentKey = ffjtEnvoyListenerName
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyListenerFilters, kn) {
+					currentKey = ffjtEnvoyListenerFilters
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.EqualFoldRight(ffjKeyEnvoyListenerAddress, kn) {
+					currentKey = ffjtEnvoyListenerAddress
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyListenerName, kn) {
+					currentKey = ffjtEnvoyListenerName
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				currentKey = ffjtEnvoyListenernosuchkey
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			}
+
+		case fflib.FFParse_want_colon:
+			if tok != fflib.FFTok_colon {
+				wantedTok = fflib.FFTok_colon
+				goto wrongtokenerror
+			}
+			state = fflib.FFParse_want_value
+			continue
+		case fflib.FFParse_want_value:
+
+			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
+				switch currentKey {
+
+				case ffjtEnvoyListenerName:
+					goto handle_Name
+
+				case ffjtEnvoyListenerAddress:
+					goto handle_Address
+
+				case ffjtEnvoyListenerFilters:
+					goto handle_Filters
+
+				case ffjtEnvoyListenernosuchkey:
+					err = fs.SkipField(tok)
+					if err != nil {
+						return fs.WrapErr(err)
+					}
+					state = fflib.FFParse_after_value
+					goto mainparse
+				}
+			} else {
+				goto wantedvalue
+			}
+		}
+	}
+
+handle_Name:
+
+	/* handler: j.Name type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.Name = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Address:
 
-	/* handler: uj.Routes type=[]*envoyhttp.EnvoyRoute kind=slice quoted=false*/
+	/* handler: j.Address type=string kind=string quoted=false*/
+
+	{
+
+		{
+			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
+				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
+			}
+		}
+
+		if tok == fflib.FFTok_null {
+
+		} else {
+
+			outBuf := fs.Output.Bytes()
+
+			j.Address = string(string(outBuf))
+
+		}
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_Filters:
+
+	/* handler: j.Filters type=[]*envoyhttp.EnvoyFilter kind=slice quoted=false*/
 
 	{
 
@@ -1749,16 +3459,16 @@ handle_Routes:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Routes = nil
+			j.Filters = nil
 		} else {
 
-			uj.Routes = []*EnvoyRoute{}
+			j.Filters = []*EnvoyFilter{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__Routes *EnvoyRoute
+				var tmpJFilters *EnvoyFilter
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -1779,29 +3489,28 @@ handle_Routes:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__Routes type=*envoyhttp.EnvoyRoute kind=ptr quoted=false*/
+				/* handler: tmpJFilters type=*envoyhttp.EnvoyFilter kind=ptr quoted=false*/
 
 				{
 					if tok == fflib.FFTok_null {
 
-						tmp_uj__Routes = nil
+						tmpJFilters = nil
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
-
-					if tmp_uj__Routes == nil {
-						tmp_uj__Routes = new(EnvoyRoute)
-					}
+					} else {
 
-					err = tmp_uj__Routes.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
+						if tmpJFilters == nil {
+							tmpJFilters = new(EnvoyFilter)
+						}
+
+						err = tmpJFilters.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
 					}
 					state = fflib.FFParse_after_value
 				}
 
-				uj.Routes = append(uj.Routes, tmp_uj__Routes)
+				j.Filters = append(j.Filters, tmpJFilters)
 
 				wantVal = false
 			}
@@ -1829,20 +3538,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyListener) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyRetryPolicy) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyListener) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyRetryPolicy) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -1850,65 +3562,42 @@ func (mj *EnvoyListener) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	var obj []byte
 	_ = obj
 	_ = err
-	buf.WriteString(`{"name":`)
-	fflib.WriteJsonString(buf, string(mj.Name))
-	buf.WriteString(`,"address":`)
-	fflib.WriteJsonString(buf, string(mj.Address))
-	buf.WriteString(`,"filters":`)
-	if mj.Filters != nil {
-		buf.WriteString(`[`)
-		for i, v := range mj.Filters {
-			if i != 0 {
-				buf.WriteString(`,`)
-			}
-
-			{
-
-				if v == nil {
-					buf.WriteString("null")
-					return nil
-				}
-
-				err = v.MarshalJSONBuf(buf)
-				if err != nil {
-					return err
-				}
-
-			}
-		}
-		buf.WriteString(`]`)
-	} else {
-		buf.WriteString(`null`)
+	buf.WriteString(`{ "retry_on":`)
+	fflib.WriteJsonString(buf, string(j.RetryOn))
+	buf.WriteByte(',')
+	if j.NumRetries != 0 {
+		buf.WriteString(`"num_retries":`)
+		fflib.FormatBits2(buf, uint64(j.NumRetries), 10, j.NumRetries < 0)
+		buf.WriteByte(',')
 	}
+	buf.Rewind(1)
 	buf.WriteByte('}')
 	return nil
 }
 
 const (
-	ffj_t_EnvoyListenerbase = iota
-	ffj_t_EnvoyListenerno_such_key
-
-	ffj_t_EnvoyListener_Name
+	ffjtEnvoyRetryPolicybase = iota
+	ffjtEnvoyRetryPolicynosuchkey
 
-	ffj_t_EnvoyListener_Address
+	ffjtEnvoyRetryPolicyRetryOn
 
-	ffj_t_EnvoyListener_Filters
+	ffjtEnvoyRetryPolicyNumRetries
 )
 
-var ffj_key_EnvoyListener_Name = []byte("name")
+var ffjKeyEnvoyRetryPolicyRetryOn = []byte("retry_on")
 
-var ffj_key_EnvoyListener_Address = []byte("address")
+var ffjKeyEnvoyRetryPolicyNumRetries = []byte("num_retries")
 
-var ffj_key_EnvoyListener_Filters = []byte("filters")
-
-func (uj *EnvoyListener) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyRetryPolicy) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyListener) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyListenerbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyRetryPolicy) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyRetryPolicybase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -1954,57 +3643,43 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyListenerno_such_key
+				currentKey = ffjtEnvoyRetryPolicynosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
 				switch kn[0] {
 
-				case 'a':
-
-					if bytes.Equal(ffj_key_EnvoyListener_Address, kn) {
-						currentKey = ffj_t_EnvoyListener_Address
-						state = fflib.FFParse_want_colon
-						goto mainparse
-					}
-
-				case 'f':
+				case 'n':
 
-					if bytes.Equal(ffj_key_EnvoyListener_Filters, kn) {
-						currentKey = ffj_t_EnvoyListener_Filters
+					if bytes.Equal(ffjKeyEnvoyRetryPolicyNumRetries, kn) {
+						currentKey = ffjtEnvoyRetryPolicyNumRetries
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
-				case 'n':
+				case 'r':
 
-					if bytes.Equal(ffj_key_EnvoyListener_Name, kn) {
-						currentKey = ffj_t_EnvoyListener_Name
+					if bytes.Equal(ffjKeyEnvoyRetryPolicyRetryOn, kn) {
+						currentKey = ffjtEnvoyRetryPolicyRetryOn
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyListener_Filters, kn) {
-					currentKey = ffj_t_EnvoyListener_Filters
-					state = fflib.FFParse_want_colon
-					goto mainparse
-				}
-
-				if fflib.EqualFoldRight(ffj_key_EnvoyListener_Address, kn) {
-					currentKey = ffj_t_EnvoyListener_Address
+				if fflib.EqualFoldRight(ffjKeyEnvoyRetryPolicyNumRetries, kn) {
+					currentKey = ffjtEnvoyRetryPolicyNumRetries
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyListener_Name, kn) {
-					currentKey = ffj_t_EnvoyListener_Name
+				if fflib.AsciiEqualFold(ffjKeyEnvoyRetryPolicyRetryOn, kn) {
+					currentKey = ffjtEnvoyRetryPolicyRetryOn
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyListenerno_such_key
+				currentKey = ffjtEnvoyRetryPolicynosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -2021,16 +3696,13 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyListener_Name:
-					goto handle_Name
-
-				case ffj_t_EnvoyListener_Address:
-					goto handle_Address
+				case ffjtEnvoyRetryPolicyRetryOn:
+					goto handle_RetryOn
 
-				case ffj_t_EnvoyListener_Filters:
-					goto handle_Filters
+				case ffjtEnvoyRetryPolicyNumRetries:
+					goto handle_NumRetries
 
-				case ffj_t_EnvoyListenerno_such_key:
+				case ffjtEnvoyRetryPolicynosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -2044,9 +3716,9 @@ mainparse:
 		}
 	}
 
-handle_Name:
+handle_RetryOn:
 
-	/* handler: uj.Name type=string kind=string quoted=false*/
+	/* handler: j.RetryOn type=string kind=string quoted=false*/
 
 	{
 
@@ -2062,7 +3734,7 @@ handle_Name:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Name = string(string(outBuf))
+			j.RetryOn = string(string(outBuf))
 
 		}
 	}
@@ -2070,101 +3742,30 @@ handle_Name:
 	state = fflib.FFParse_after_value
 	goto mainparse
 
-handle_Address:
+handle_NumRetries:
 
-	/* handler: uj.Address type=string kind=string quoted=false*/
+	/* handler: j.NumRetries type=int kind=int quoted=false*/
 
 	{
-
-		{
-			if tok != fflib.FFTok_string && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for string", tok))
-			}
-		}
-
-		if tok == fflib.FFTok_null {
-
-		} else {
-
-			outBuf := fs.Output.Bytes()
-
-			uj.Address = string(string(outBuf))
-
+		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
+			return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for int", tok))
 		}
 	}
 
-	state = fflib.FFParse_after_value
-	goto mainparse
-
-handle_Filters:
-
-	/* handler: uj.Filters type=[]*envoyhttp.EnvoyFilter kind=slice quoted=false*/
-
 	{
 
-		{
-			if tok != fflib.FFTok_left_brace && tok != fflib.FFTok_null {
-				return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for ", tok))
-			}
-		}
-
 		if tok == fflib.FFTok_null {
-			uj.Filters = nil
-		} else {
-
-			uj.Filters = []*EnvoyFilter{}
-
-			wantVal := true
-
-			for {
-
-				var tmp_uj__Filters *EnvoyFilter
-
-				tok = fs.Scan()
-				if tok == fflib.FFTok_error {
-					goto tokerror
-				}
-				if tok == fflib.FFTok_right_brace {
-					break
-				}
-
-				if tok == fflib.FFTok_comma {
-					if wantVal == true {
-						// TODO(pquerna): this isn't an ideal error message, this handles
-						// things like [,,,] as an array value.
-						return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
-					}
-					continue
-				} else {
-					wantVal = true
-				}
-
-				/* handler: tmp_uj__Filters type=*envoyhttp.EnvoyFilter kind=ptr quoted=false*/
-
-				{
-					if tok == fflib.FFTok_null {
 
-						tmp_uj__Filters = nil
-
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+		} else {
 
-					if tmp_uj__Filters == nil {
-						tmp_uj__Filters = new(EnvoyFilter)
-					}
+			tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
 
-					err = tmp_uj__Filters.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
-					}
-					state = fflib.FFParse_after_value
-				}
+			if err != nil {
+				return fs.WrapErr(err)
+			}
 
-				uj.Filters = append(uj.Filters, tmp_uj__Filters)
+			j.NumRetries = int(tval)
 
-				wantVal = false
-			}
 		}
 	}
 
@@ -2189,20 +3790,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyRoute) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyRoute) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -2211,21 +3815,36 @@ func (mj *EnvoyRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{ "timeout_ms":`)
-	fflib.FormatBits2(buf, uint64(mj.TimeoutMs), 10, mj.TimeoutMs < 0)
+	fflib.FormatBits2(buf, uint64(j.TimeoutMs), 10, j.TimeoutMs < 0)
 	buf.WriteString(`,"prefix":`)
-	fflib.WriteJsonString(buf, string(mj.Prefix))
+	fflib.WriteJsonString(buf, string(j.Prefix))
 	buf.WriteString(`,"host_rewrite":`)
-	fflib.WriteJsonString(buf, string(mj.HostRewrite))
+	fflib.WriteJsonString(buf, string(j.HostRewrite))
 	buf.WriteString(`,"cluster":`)
-	fflib.WriteJsonString(buf, string(mj.Cluster))
+	fflib.WriteJsonString(buf, string(j.Cluster))
 	buf.WriteByte(',')
-	if mj.Decorator != nil {
+	if j.Decorator != nil {
 		if true {
 			buf.WriteString(`"decorator":`)
 
 			{
 
-				err = mj.Decorator.MarshalJSONBuf(buf)
+				err = j.Decorator.MarshalJSONBuf(buf)
+				if err != nil {
+					return err
+				}
+
+			}
+			buf.WriteByte(',')
+		}
+	}
+	if j.RetryPolicy != nil {
+		if true {
+			buf.WriteString(`"retry_policy":`)
+
+			{
+
+				err = j.RetryPolicy.MarshalJSONBuf(buf)
 				if err != nil {
 					return err
 				}
@@ -2240,38 +3859,44 @@ func (mj *EnvoyRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_EnvoyRoutebase = iota
-	ffj_t_EnvoyRouteno_such_key
+	ffjtEnvoyRoutebase = iota
+	ffjtEnvoyRoutenosuchkey
+
+	ffjtEnvoyRouteTimeoutMs
 
-	ffj_t_EnvoyRoute_TimeoutMs
+	ffjtEnvoyRoutePrefix
 
-	ffj_t_EnvoyRoute_Prefix
+	ffjtEnvoyRouteHostRewrite
 
-	ffj_t_EnvoyRoute_HostRewrite
+	ffjtEnvoyRouteCluster
 
-	ffj_t_EnvoyRoute_Cluster
+	ffjtEnvoyRouteDecorator
 
-	ffj_t_EnvoyRoute_Decorator
+	ffjtEnvoyRouteRetryPolicy
 )
 
-var ffj_key_EnvoyRoute_TimeoutMs = []byte("timeout_ms")
+var ffjKeyEnvoyRouteTimeoutMs = []byte("timeout_ms")
 
-var ffj_key_EnvoyRoute_Prefix = []byte("prefix")
+var ffjKeyEnvoyRoutePrefix = []byte("prefix")
 
-var ffj_key_EnvoyRoute_HostRewrite = []byte("host_rewrite")
+var ffjKeyEnvoyRouteHostRewrite = []byte("host_rewrite")
 
-var ffj_key_EnvoyRoute_Cluster = []byte("cluster")
+var ffjKeyEnvoyRouteCluster = []byte("cluster")
 
-var ffj_key_EnvoyRoute_Decorator = []byte("decorator")
+var ffjKeyEnvoyRouteDecorator = []byte("decorator")
 
-func (uj *EnvoyRoute) UnmarshalJSON(input []byte) error {
+var ffjKeyEnvoyRouteRetryPolicy = []byte("retry_policy")
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyRoute) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyRoute) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyRoutebase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyRoute) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyRoutebase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -2317,7 +3942,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyRouteno_such_key
+				currentKey = ffjtEnvoyRoutenosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -2325,77 +3950,91 @@ mainparse:
 
 				case 'c':
 
-					if bytes.Equal(ffj_key_EnvoyRoute_Cluster, kn) {
-						currentKey = ffj_t_EnvoyRoute_Cluster
+					if bytes.Equal(ffjKeyEnvoyRouteCluster, kn) {
+						currentKey = ffjtEnvoyRouteCluster
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'd':
 
-					if bytes.Equal(ffj_key_EnvoyRoute_Decorator, kn) {
-						currentKey = ffj_t_EnvoyRoute_Decorator
+					if bytes.Equal(ffjKeyEnvoyRouteDecorator, kn) {
+						currentKey = ffjtEnvoyRouteDecorator
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'h':
 
-					if bytes.Equal(ffj_key_EnvoyRoute_HostRewrite, kn) {
-						currentKey = ffj_t_EnvoyRoute_HostRewrite
+					if bytes.Equal(ffjKeyEnvoyRouteHostRewrite, kn) {
+						currentKey = ffjtEnvoyRouteHostRewrite
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'p':
 
-					if bytes.Equal(ffj_key_EnvoyRoute_Prefix, kn) {
-						currentKey = ffj_t_EnvoyRoute_Prefix
+					if bytes.Equal(ffjKeyEnvoyRoutePrefix, kn) {
+						currentKey = ffjtEnvoyRoutePrefix
+						state = fflib.FFParse_want_colon
+						goto mainparse
+					}
+
+				case 'r':
+
+					if bytes.Equal(ffjKeyEnvoyRouteRetryPolicy, kn) {
+						currentKey = ffjtEnvoyRouteRetryPolicy
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 't':
 
-					if bytes.Equal(ffj_key_EnvoyRoute_TimeoutMs, kn) {
-						currentKey = ffj_t_EnvoyRoute_TimeoutMs
+					if bytes.Equal(ffjKeyEnvoyRouteTimeoutMs, kn) {
+						currentKey = ffjtEnvoyRouteTimeoutMs
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyRoute_Decorator, kn) {
-					currentKey = ffj_t_EnvoyRoute_Decorator
+				if fflib.AsciiEqualFold(ffjKeyEnvoyRouteRetryPolicy, kn) {
+					currentKey = ffjtEnvoyRouteRetryPolicy
+					state = fflib.FFParse_want_colon
+					goto mainparse
+				}
+
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyRouteDecorator, kn) {
+					currentKey = ffjtEnvoyRouteDecorator
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyRoute_Cluster, kn) {
-					currentKey = ffj_t_EnvoyRoute_Cluster
+				if fflib.EqualFoldRight(ffjKeyEnvoyRouteCluster, kn) {
+					currentKey = ffjtEnvoyRouteCluster
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyRoute_HostRewrite, kn) {
-					currentKey = ffj_t_EnvoyRoute_HostRewrite
+				if fflib.EqualFoldRight(ffjKeyEnvoyRouteHostRewrite, kn) {
+					currentKey = ffjtEnvoyRouteHostRewrite
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyRoute_Prefix, kn) {
-					currentKey = ffj_t_EnvoyRoute_Prefix
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyRoutePrefix, kn) {
+					currentKey = ffjtEnvoyRoutePrefix
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyRoute_TimeoutMs, kn) {
-					currentKey = ffj_t_EnvoyRoute_TimeoutMs
+				if fflib.EqualFoldRight(ffjKeyEnvoyRouteTimeoutMs, kn) {
+					currentKey = ffjtEnvoyRouteTimeoutMs
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyRouteno_such_key
+				currentKey = ffjtEnvoyRoutenosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -2412,22 +4051,25 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyRoute_TimeoutMs:
+				case ffjtEnvoyRouteTimeoutMs:
 					goto handle_TimeoutMs
 
-				case ffj_t_EnvoyRoute_Prefix:
+				case ffjtEnvoyRoutePrefix:
 					goto handle_Prefix
 
-				case ffj_t_EnvoyRoute_HostRewrite:
+				case ffjtEnvoyRouteHostRewrite:
 					goto handle_HostRewrite
 
-				case ffj_t_EnvoyRoute_Cluster:
+				case ffjtEnvoyRouteCluster:
 					goto handle_Cluster
 
-				case ffj_t_EnvoyRoute_Decorator:
+				case ffjtEnvoyRouteDecorator:
 					goto handle_Decorator
 
-				case ffj_t_EnvoyRouteno_such_key:
+				case ffjtEnvoyRouteRetryPolicy:
+					goto handle_RetryPolicy
+
+				case ffjtEnvoyRoutenosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -2443,7 +4085,7 @@ mainparse:
 
 handle_TimeoutMs:
 
-	/* handler: uj.TimeoutMs type=int kind=int quoted=false*/
+	/* handler: j.TimeoutMs type=int kind=int quoted=false*/
 
 	{
 		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
@@ -2463,7 +4105,7 @@ handle_TimeoutMs:
 				return fs.WrapErr(err)
 			}
 
-			uj.TimeoutMs = int(tval)
+			j.TimeoutMs = int(tval)
 
 		}
 	}
@@ -2473,7 +4115,7 @@ handle_TimeoutMs:
 
 handle_Prefix:
 
-	/* handler: uj.Prefix type=string kind=string quoted=false*/
+	/* handler: j.Prefix type=string kind=string quoted=false*/
 
 	{
 
@@ -2489,7 +4131,7 @@ handle_Prefix:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Prefix = string(string(outBuf))
+			j.Prefix = string(string(outBuf))
 
 		}
 	}
@@ -2499,7 +4141,7 @@ handle_Prefix:
 
 handle_HostRewrite:
 
-	/* handler: uj.HostRewrite type=string kind=string quoted=false*/
+	/* handler: j.HostRewrite type=string kind=string quoted=false*/
 
 	{
 
@@ -2515,7 +4157,7 @@ handle_HostRewrite:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.HostRewrite = string(string(outBuf))
+			j.HostRewrite = string(string(outBuf))
 
 		}
 	}
@@ -2525,7 +4167,7 @@ handle_HostRewrite:
 
 handle_Cluster:
 
-	/* handler: uj.Cluster type=string kind=string quoted=false*/
+	/* handler: j.Cluster type=string kind=string quoted=false*/
 
 	{
 
@@ -2541,7 +4183,7 @@ handle_Cluster:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Cluster = string(string(outBuf))
+			j.Cluster = string(string(outBuf))
 
 		}
 	}
@@ -2551,24 +4193,49 @@ handle_Cluster:
 
 handle_Decorator:
 
-	/* handler: uj.Decorator type=envoyhttp.EnvoyRouteDecorator kind=struct quoted=false*/
+	/* handler: j.Decorator type=envoyhttp.EnvoyRouteDecorator kind=struct quoted=false*/
 
 	{
 		if tok == fflib.FFTok_null {
 
-			uj.Decorator = nil
+			j.Decorator = nil
 
-			state = fflib.FFParse_after_value
-			goto mainparse
-		}
+		} else {
+
+			if j.Decorator == nil {
+				j.Decorator = new(EnvoyRouteDecorator)
+			}
 
-		if uj.Decorator == nil {
-			uj.Decorator = new(EnvoyRouteDecorator)
+			err = j.Decorator.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
 		}
+		state = fflib.FFParse_after_value
+	}
+
+	state = fflib.FFParse_after_value
+	goto mainparse
+
+handle_RetryPolicy:
+
+	/* handler: j.RetryPolicy type=envoyhttp.EnvoyRetryPolicy kind=struct quoted=false*/
+
+	{
+		if tok == fflib.FFTok_null {
+
+			j.RetryPolicy = nil
+
+		} else {
+
+			if j.RetryPolicy == nil {
+				j.RetryPolicy = new(EnvoyRetryPolicy)
+			}
 
-		err = uj.Decorator.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-		if err != nil {
-			return err
+			err = j.RetryPolicy.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+			if err != nil {
+				return err
+			}
 		}
 		state = fflib.FFParse_after_value
 	}
@@ -2594,20 +4261,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyRouteConfig) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyRouteConfig) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -2616,11 +4286,11 @@ func (mj *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{ `)
-	if len(mj.VirtualHosts) != 0 {
+	if len(j.VirtualHosts) != 0 {
 		buf.WriteString(`"virtual_hosts":`)
-		if mj.VirtualHosts != nil {
+		if j.VirtualHosts != nil {
 			buf.WriteString(`[`)
-			for i, v := range mj.VirtualHosts {
+			for i, v := range j.VirtualHosts {
 				if i != 0 {
 					buf.WriteString(`,`)
 				}
@@ -2629,12 +4299,13 @@ func (mj *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 
 					if v == nil {
 						buf.WriteString("null")
-						return nil
-					}
+					} else {
+
+						err = v.MarshalJSONBuf(buf)
+						if err != nil {
+							return err
+						}
 
-					err = v.MarshalJSONBuf(buf)
-					if err != nil {
-						return err
 					}
 
 				}
@@ -2645,11 +4316,11 @@ func (mj *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 		}
 		buf.WriteByte(',')
 	}
-	if len(mj.Routes) != 0 {
+	if len(j.Routes) != 0 {
 		buf.WriteString(`"routes":`)
-		if mj.Routes != nil {
+		if j.Routes != nil {
 			buf.WriteString(`[`)
-			for i, v := range mj.Routes {
+			for i, v := range j.Routes {
 				if i != 0 {
 					buf.WriteString(`,`)
 				}
@@ -2658,12 +4329,13 @@ func (mj *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 
 					if v == nil {
 						buf.WriteString("null")
-						return nil
-					}
+					} else {
+
+						err = v.MarshalJSONBuf(buf)
+						if err != nil {
+							return err
+						}
 
-					err = v.MarshalJSONBuf(buf)
-					if err != nil {
-						return err
 					}
 
 				}
@@ -2680,26 +4352,28 @@ func (mj *EnvoyRouteConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_EnvoyRouteConfigbase = iota
-	ffj_t_EnvoyRouteConfigno_such_key
+	ffjtEnvoyRouteConfigbase = iota
+	ffjtEnvoyRouteConfignosuchkey
 
-	ffj_t_EnvoyRouteConfig_VirtualHosts
+	ffjtEnvoyRouteConfigVirtualHosts
 
-	ffj_t_EnvoyRouteConfig_Routes
+	ffjtEnvoyRouteConfigRoutes
 )
 
-var ffj_key_EnvoyRouteConfig_VirtualHosts = []byte("virtual_hosts")
+var ffjKeyEnvoyRouteConfigVirtualHosts = []byte("virtual_hosts")
 
-var ffj_key_EnvoyRouteConfig_Routes = []byte("routes")
+var ffjKeyEnvoyRouteConfigRoutes = []byte("routes")
 
-func (uj *EnvoyRouteConfig) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyRouteConfig) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyRouteConfig) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyRouteConfigbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyRouteConfig) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyRouteConfigbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -2745,7 +4419,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyRouteConfigno_such_key
+				currentKey = ffjtEnvoyRouteConfignosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -2753,35 +4427,35 @@ mainparse:
 
 				case 'r':
 
-					if bytes.Equal(ffj_key_EnvoyRouteConfig_Routes, kn) {
-						currentKey = ffj_t_EnvoyRouteConfig_Routes
+					if bytes.Equal(ffjKeyEnvoyRouteConfigRoutes, kn) {
+						currentKey = ffjtEnvoyRouteConfigRoutes
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'v':
 
-					if bytes.Equal(ffj_key_EnvoyRouteConfig_VirtualHosts, kn) {
-						currentKey = ffj_t_EnvoyRouteConfig_VirtualHosts
+					if bytes.Equal(ffjKeyEnvoyRouteConfigVirtualHosts, kn) {
+						currentKey = ffjtEnvoyRouteConfigVirtualHosts
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyRouteConfig_Routes, kn) {
-					currentKey = ffj_t_EnvoyRouteConfig_Routes
+				if fflib.EqualFoldRight(ffjKeyEnvoyRouteConfigRoutes, kn) {
+					currentKey = ffjtEnvoyRouteConfigRoutes
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyRouteConfig_VirtualHosts, kn) {
-					currentKey = ffj_t_EnvoyRouteConfig_VirtualHosts
+				if fflib.EqualFoldRight(ffjKeyEnvoyRouteConfigVirtualHosts, kn) {
+					currentKey = ffjtEnvoyRouteConfigVirtualHosts
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyRouteConfigno_such_key
+				currentKey = ffjtEnvoyRouteConfignosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -2798,13 +4472,13 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyRouteConfig_VirtualHosts:
+				case ffjtEnvoyRouteConfigVirtualHosts:
 					goto handle_VirtualHosts
 
-				case ffj_t_EnvoyRouteConfig_Routes:
+				case ffjtEnvoyRouteConfigRoutes:
 					goto handle_Routes
 
-				case ffj_t_EnvoyRouteConfigno_such_key:
+				case ffjtEnvoyRouteConfignosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -2820,7 +4494,7 @@ mainparse:
 
 handle_VirtualHosts:
 
-	/* handler: uj.VirtualHosts type=[]*envoyhttp.EnvoyHTTPVirtualHost kind=slice quoted=false*/
+	/* handler: j.VirtualHosts type=[]*envoyhttp.EnvoyHTTPVirtualHost kind=slice quoted=false*/
 
 	{
 
@@ -2831,16 +4505,16 @@ handle_VirtualHosts:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.VirtualHosts = nil
+			j.VirtualHosts = nil
 		} else {
 
-			uj.VirtualHosts = []*EnvoyHTTPVirtualHost{}
+			j.VirtualHosts = []*EnvoyHTTPVirtualHost{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__VirtualHosts *EnvoyHTTPVirtualHost
+				var tmpJVirtualHosts *EnvoyHTTPVirtualHost
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -2861,29 +4535,28 @@ handle_VirtualHosts:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__VirtualHosts type=*envoyhttp.EnvoyHTTPVirtualHost kind=ptr quoted=false*/
+				/* handler: tmpJVirtualHosts type=*envoyhttp.EnvoyHTTPVirtualHost kind=ptr quoted=false*/
 
 				{
 					if tok == fflib.FFTok_null {
 
-						tmp_uj__VirtualHosts = nil
+						tmpJVirtualHosts = nil
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+					} else {
 
-					if tmp_uj__VirtualHosts == nil {
-						tmp_uj__VirtualHosts = new(EnvoyHTTPVirtualHost)
-					}
+						if tmpJVirtualHosts == nil {
+							tmpJVirtualHosts = new(EnvoyHTTPVirtualHost)
+						}
 
-					err = tmp_uj__VirtualHosts.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
+						err = tmpJVirtualHosts.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
 					}
 					state = fflib.FFParse_after_value
 				}
 
-				uj.VirtualHosts = append(uj.VirtualHosts, tmp_uj__VirtualHosts)
+				j.VirtualHosts = append(j.VirtualHosts, tmpJVirtualHosts)
 
 				wantVal = false
 			}
@@ -2895,7 +4568,7 @@ handle_VirtualHosts:
 
 handle_Routes:
 
-	/* handler: uj.Routes type=[]*envoyhttp.EnvoyTCPRoute kind=slice quoted=false*/
+	/* handler: j.Routes type=[]*envoyhttp.EnvoyTCPRoute kind=slice quoted=false*/
 
 	{
 
@@ -2906,16 +4579,16 @@ handle_Routes:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Routes = nil
+			j.Routes = nil
 		} else {
 
-			uj.Routes = []*EnvoyTCPRoute{}
+			j.Routes = []*EnvoyTCPRoute{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__Routes *EnvoyTCPRoute
+				var tmpJRoutes *EnvoyTCPRoute
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -2936,29 +4609,28 @@ handle_Routes:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__Routes type=*envoyhttp.EnvoyTCPRoute kind=ptr quoted=false*/
+				/* handler: tmpJRoutes type=*envoyhttp.EnvoyTCPRoute kind=ptr quoted=false*/
 
 				{
 					if tok == fflib.FFTok_null {
 
-						tmp_uj__Routes = nil
+						tmpJRoutes = nil
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+					} else {
 
-					if tmp_uj__Routes == nil {
-						tmp_uj__Routes = new(EnvoyTCPRoute)
-					}
+						if tmpJRoutes == nil {
+							tmpJRoutes = new(EnvoyTCPRoute)
+						}
 
-					err = tmp_uj__Routes.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
+						err = tmpJRoutes.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
 					}
 					state = fflib.FFParse_after_value
 				}
 
-				uj.Routes = append(uj.Routes, tmp_uj__Routes)
+				j.Routes = append(j.Routes, tmpJRoutes)
 
 				wantVal = false
 			}
@@ -2986,20 +4658,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyRouteDecorator) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyRouteDecorator) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyRouteDecorator) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyRouteDecorator) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -3008,9 +4683,9 @@ func (mj *EnvoyRouteDecorator) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{ `)
-	if len(mj.Operation) != 0 {
+	if len(j.Operation) != 0 {
 		buf.WriteString(`"operation":`)
-		fflib.WriteJsonString(buf, string(mj.Operation))
+		fflib.WriteJsonString(buf, string(j.Operation))
 		buf.WriteByte(',')
 	}
 	buf.Rewind(1)
@@ -3019,22 +4694,24 @@ func (mj *EnvoyRouteDecorator) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_EnvoyRouteDecoratorbase = iota
-	ffj_t_EnvoyRouteDecoratorno_such_key
+	ffjtEnvoyRouteDecoratorbase = iota
+	ffjtEnvoyRouteDecoratornosuchkey
 
-	ffj_t_EnvoyRouteDecorator_Operation
+	ffjtEnvoyRouteDecoratorOperation
 )
 
-var ffj_key_EnvoyRouteDecorator_Operation = []byte("operation")
+var ffjKeyEnvoyRouteDecoratorOperation = []byte("operation")
 
-func (uj *EnvoyRouteDecorator) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyRouteDecorator) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyRouteDecorator) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyRouteDecoratorbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyRouteDecorator) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyRouteDecoratorbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -3080,7 +4757,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyRouteDecoratorno_such_key
+				currentKey = ffjtEnvoyRouteDecoratornosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -3088,21 +4765,21 @@ mainparse:
 
 				case 'o':
 
-					if bytes.Equal(ffj_key_EnvoyRouteDecorator_Operation, kn) {
-						currentKey = ffj_t_EnvoyRouteDecorator_Operation
+					if bytes.Equal(ffjKeyEnvoyRouteDecoratorOperation, kn) {
+						currentKey = ffjtEnvoyRouteDecoratorOperation
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyRouteDecorator_Operation, kn) {
-					currentKey = ffj_t_EnvoyRouteDecorator_Operation
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyRouteDecoratorOperation, kn) {
+					currentKey = ffjtEnvoyRouteDecoratorOperation
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyRouteDecoratorno_such_key
+				currentKey = ffjtEnvoyRouteDecoratornosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -3119,10 +4796,10 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyRouteDecorator_Operation:
+				case ffjtEnvoyRouteDecoratorOperation:
 					goto handle_Operation
 
-				case ffj_t_EnvoyRouteDecoratorno_such_key:
+				case ffjtEnvoyRouteDecoratornosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -3138,7 +4815,7 @@ mainparse:
 
 handle_Operation:
 
-	/* handler: uj.Operation type=string kind=string quoted=false*/
+	/* handler: j.Operation type=string kind=string quoted=false*/
 
 	{
 
@@ -3154,7 +4831,7 @@ handle_Operation:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Operation = string(string(outBuf))
+			j.Operation = string(string(outBuf))
 
 		}
 	}
@@ -3180,20 +4857,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyService) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyService) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyService) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyService) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -3202,22 +4882,22 @@ func (mj *EnvoyService) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{"ip_address":`)
-	fflib.WriteJsonString(buf, string(mj.IPAddress))
+	fflib.WriteJsonString(buf, string(j.IPAddress))
 	buf.WriteString(`,"last_check_in":`)
-	fflib.WriteJsonString(buf, string(mj.LastCheckIn))
+	fflib.WriteJsonString(buf, string(j.LastCheckIn))
 	buf.WriteString(`,"port":`)
-	fflib.FormatBits2(buf, uint64(mj.Port), 10, mj.Port < 0)
+	fflib.FormatBits2(buf, uint64(j.Port), 10, j.Port < 0)
 	buf.WriteString(`,"revision":`)
-	fflib.WriteJsonString(buf, string(mj.Revision))
+	fflib.WriteJsonString(buf, string(j.Revision))
 	buf.WriteString(`,"service":`)
-	fflib.WriteJsonString(buf, string(mj.Service))
+	fflib.WriteJsonString(buf, string(j.Service))
 	buf.WriteString(`,"service_repo_name":`)
-	fflib.WriteJsonString(buf, string(mj.ServiceRepoName))
-	if mj.Tags == nil {
+	fflib.WriteJsonString(buf, string(j.ServiceRepoName))
+	if j.Tags == nil {
 		buf.WriteString(`,"tags":null`)
 	} else {
 		buf.WriteString(`,"tags":{ `)
-		for key, value := range mj.Tags {
+		for key, value := range j.Tags {
 			fflib.WriteJsonString(buf, key)
 			buf.WriteString(`:`)
 			fflib.WriteJsonString(buf, string(value))
@@ -3231,46 +4911,48 @@ func (mj *EnvoyService) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_EnvoyServicebase = iota
-	ffj_t_EnvoyServiceno_such_key
+	ffjtEnvoyServicebase = iota
+	ffjtEnvoyServicenosuchkey
 
-	ffj_t_EnvoyService_IPAddress
+	ffjtEnvoyServiceIPAddress
 
-	ffj_t_EnvoyService_LastCheckIn
+	ffjtEnvoyServiceLastCheckIn
 
-	ffj_t_EnvoyService_Port
+	ffjtEnvoyServicePort
 
-	ffj_t_EnvoyService_Revision
+	ffjtEnvoyServiceRevision
 
-	ffj_t_EnvoyService_Service
+	ffjtEnvoyServiceService
 
-	ffj_t_EnvoyService_ServiceRepoName
+	ffjtEnvoyServiceServiceRepoName
 
-	ffj_t_EnvoyService_Tags
+	ffjtEnvoyServiceTags
 )
 
-var ffj_key_EnvoyService_IPAddress = []byte("ip_address")
+var ffjKeyEnvoyServiceIPAddress = []byte("ip_address")
 
-var ffj_key_EnvoyService_LastCheckIn = []byte("last_check_in")
+var ffjKeyEnvoyServiceLastCheckIn = []byte("last_check_in")
 
-var ffj_key_EnvoyService_Port = []byte("port")
+var ffjKeyEnvoyServicePort = []byte("port")
 
-var ffj_key_EnvoyService_Revision = []byte("revision")
+var ffjKeyEnvoyServiceRevision = []byte("revision")
 
-var ffj_key_EnvoyService_Service = []byte("service")
+var ffjKeyEnvoyServiceService = []byte("service")
 
-var ffj_key_EnvoyService_ServiceRepoName = []byte("service_repo_name")
+var ffjKeyEnvoyServiceServiceRepoName = []byte("service_repo_name")
 
-var ffj_key_EnvoyService_Tags = []byte("tags")
+var ffjKeyEnvoyServiceTags = []byte("tags")
 
-func (uj *EnvoyService) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyService) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyService) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyServicebase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyService) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyServicebase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -3316,7 +4998,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyServiceno_such_key
+				currentKey = ffjtEnvoyServicenosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -3324,102 +5006,102 @@ mainparse:
 
 				case 'i':
 
-					if bytes.Equal(ffj_key_EnvoyService_IPAddress, kn) {
-						currentKey = ffj_t_EnvoyService_IPAddress
+					if bytes.Equal(ffjKeyEnvoyServiceIPAddress, kn) {
+						currentKey = ffjtEnvoyServiceIPAddress
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'l':
 
-					if bytes.Equal(ffj_key_EnvoyService_LastCheckIn, kn) {
-						currentKey = ffj_t_EnvoyService_LastCheckIn
+					if bytes.Equal(ffjKeyEnvoyServiceLastCheckIn, kn) {
+						currentKey = ffjtEnvoyServiceLastCheckIn
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'p':
 
-					if bytes.Equal(ffj_key_EnvoyService_Port, kn) {
-						currentKey = ffj_t_EnvoyService_Port
+					if bytes.Equal(ffjKeyEnvoyServicePort, kn) {
+						currentKey = ffjtEnvoyServicePort
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'r':
 
-					if bytes.Equal(ffj_key_EnvoyService_Revision, kn) {
-						currentKey = ffj_t_EnvoyService_Revision
+					if bytes.Equal(ffjKeyEnvoyServiceRevision, kn) {
+						currentKey = ffjtEnvoyServiceRevision
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 's':
 
-					if bytes.Equal(ffj_key_EnvoyService_Service, kn) {
-						currentKey = ffj_t_EnvoyService_Service
+					if bytes.Equal(ffjKeyEnvoyServiceService, kn) {
+						currentKey = ffjtEnvoyServiceService
 						state = fflib.FFParse_want_colon
 						goto mainparse
 
-					} else if bytes.Equal(ffj_key_EnvoyService_ServiceRepoName, kn) {
-						currentKey = ffj_t_EnvoyService_ServiceRepoName
+					} else if bytes.Equal(ffjKeyEnvoyServiceServiceRepoName, kn) {
+						currentKey = ffjtEnvoyServiceServiceRepoName
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 't':
 
-					if bytes.Equal(ffj_key_EnvoyService_Tags, kn) {
-						currentKey = ffj_t_EnvoyService_Tags
+					if bytes.Equal(ffjKeyEnvoyServiceTags, kn) {
+						currentKey = ffjtEnvoyServiceTags
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyService_Tags, kn) {
-					currentKey = ffj_t_EnvoyService_Tags
+				if fflib.EqualFoldRight(ffjKeyEnvoyServiceTags, kn) {
+					currentKey = ffjtEnvoyServiceTags
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyService_ServiceRepoName, kn) {
-					currentKey = ffj_t_EnvoyService_ServiceRepoName
+				if fflib.EqualFoldRight(ffjKeyEnvoyServiceServiceRepoName, kn) {
+					currentKey = ffjtEnvoyServiceServiceRepoName
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyService_Service, kn) {
-					currentKey = ffj_t_EnvoyService_Service
+				if fflib.EqualFoldRight(ffjKeyEnvoyServiceService, kn) {
+					currentKey = ffjtEnvoyServiceService
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyService_Revision, kn) {
-					currentKey = ffj_t_EnvoyService_Revision
+				if fflib.EqualFoldRight(ffjKeyEnvoyServiceRevision, kn) {
+					currentKey = ffjtEnvoyServiceRevision
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_EnvoyService_Port, kn) {
-					currentKey = ffj_t_EnvoyService_Port
+				if fflib.SimpleLetterEqualFold(ffjKeyEnvoyServicePort, kn) {
+					currentKey = ffjtEnvoyServicePort
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyService_LastCheckIn, kn) {
-					currentKey = ffj_t_EnvoyService_LastCheckIn
+				if fflib.EqualFoldRight(ffjKeyEnvoyServiceLastCheckIn, kn) {
+					currentKey = ffjtEnvoyServiceLastCheckIn
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyService_IPAddress, kn) {
-					currentKey = ffj_t_EnvoyService_IPAddress
+				if fflib.EqualFoldRight(ffjKeyEnvoyServiceIPAddress, kn) {
+					currentKey = ffjtEnvoyServiceIPAddress
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyServiceno_such_key
+				currentKey = ffjtEnvoyServicenosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -3436,28 +5118,28 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyService_IPAddress:
+				case ffjtEnvoyServiceIPAddress:
 					goto handle_IPAddress
 
-				case ffj_t_EnvoyService_LastCheckIn:
+				case ffjtEnvoyServiceLastCheckIn:
 					goto handle_LastCheckIn
 
-				case ffj_t_EnvoyService_Port:
+				case ffjtEnvoyServicePort:
 					goto handle_Port
 
-				case ffj_t_EnvoyService_Revision:
+				case ffjtEnvoyServiceRevision:
 					goto handle_Revision
 
-				case ffj_t_EnvoyService_Service:
+				case ffjtEnvoyServiceService:
 					goto handle_Service
 
-				case ffj_t_EnvoyService_ServiceRepoName:
+				case ffjtEnvoyServiceServiceRepoName:
 					goto handle_ServiceRepoName
 
-				case ffj_t_EnvoyService_Tags:
+				case ffjtEnvoyServiceTags:
 					goto handle_Tags
 
-				case ffj_t_EnvoyServiceno_such_key:
+				case ffjtEnvoyServicenosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -3473,7 +5155,7 @@ mainparse:
 
 handle_IPAddress:
 
-	/* handler: uj.IPAddress type=string kind=string quoted=false*/
+	/* handler: j.IPAddress type=string kind=string quoted=false*/
 
 	{
 
@@ -3489,7 +5171,7 @@ handle_IPAddress:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.IPAddress = string(string(outBuf))
+			j.IPAddress = string(string(outBuf))
 
 		}
 	}
@@ -3499,7 +5181,7 @@ handle_IPAddress:
 
 handle_LastCheckIn:
 
-	/* handler: uj.LastCheckIn type=string kind=string quoted=false*/
+	/* handler: j.LastCheckIn type=string kind=string quoted=false*/
 
 	{
 
@@ -3515,7 +5197,7 @@ handle_LastCheckIn:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.LastCheckIn = string(string(outBuf))
+			j.LastCheckIn = string(string(outBuf))
 
 		}
 	}
@@ -3525,7 +5207,7 @@ handle_LastCheckIn:
 
 handle_Port:
 
-	/* handler: uj.Port type=int64 kind=int64 quoted=false*/
+	/* handler: j.Port type=int64 kind=int64 quoted=false*/
 
 	{
 		if tok != fflib.FFTok_integer && tok != fflib.FFTok_null {
@@ -3545,7 +5227,7 @@ handle_Port:
 				return fs.WrapErr(err)
 			}
 
-			uj.Port = int64(tval)
+			j.Port = int64(tval)
 
 		}
 	}
@@ -3555,7 +5237,7 @@ handle_Port:
 
 handle_Revision:
 
-	/* handler: uj.Revision type=string kind=string quoted=false*/
+	/* handler: j.Revision type=string kind=string quoted=false*/
 
 	{
 
@@ -3571,7 +5253,7 @@ handle_Revision:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Revision = string(string(outBuf))
+			j.Revision = string(string(outBuf))
 
 		}
 	}
@@ -3581,7 +5263,7 @@ handle_Revision:
 
 handle_Service:
 
-	/* handler: uj.Service type=string kind=string quoted=false*/
+	/* handler: j.Service type=string kind=string quoted=false*/
 
 	{
 
@@ -3597,7 +5279,7 @@ handle_Service:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Service = string(string(outBuf))
+			j.Service = string(string(outBuf))
 
 		}
 	}
@@ -3607,7 +5289,7 @@ handle_Service:
 
 handle_ServiceRepoName:
 
-	/* handler: uj.ServiceRepoName type=string kind=string quoted=false*/
+	/* handler: j.ServiceRepoName type=string kind=string quoted=false*/
 
 	{
 
@@ -3623,7 +5305,7 @@ handle_ServiceRepoName:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.ServiceRepoName = string(string(outBuf))
+			j.ServiceRepoName = string(string(outBuf))
 
 		}
 	}
@@ -3633,7 +5315,7 @@ handle_ServiceRepoName:
 
 handle_Tags:
 
-	/* handler: uj.Tags type=map[string]string kind=map quoted=false*/
+	/* handler: j.Tags type=map[string]string kind=map quoted=false*/
 
 	{
 
@@ -3644,10 +5326,10 @@ handle_Tags:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Tags = nil
+			j.Tags = nil
 		} else {
 
-			uj.Tags = make(map[string]string, 0)
+			j.Tags = make(map[string]string, 0)
 
 			wantVal := true
 
@@ -3655,7 +5337,7 @@ handle_Tags:
 
 				var k string
 
-				var tmp_uj__Tags string
+				var tmpJTags string
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -3704,7 +5386,7 @@ handle_Tags:
 				}
 
 				tok = fs.Scan()
-				/* handler: tmp_uj__Tags type=string kind=string quoted=false*/
+				/* handler: tmpJTags type=string kind=string quoted=false*/
 
 				{
 
@@ -3720,12 +5402,12 @@ handle_Tags:
 
 						outBuf := fs.Output.Bytes()
 
-						tmp_uj__Tags = string(string(outBuf))
+						tmpJTags = string(string(outBuf))
 
 					}
 				}
 
-				uj.Tags[k] = tmp_uj__Tags
+				j.Tags[k] = tmpJTags
 
 				wantVal = false
 			}
@@ -3754,20 +5436,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyTCPRoute) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyTCPRoute) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyTCPRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyTCPRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -3776,13 +5461,13 @@ func (mj *EnvoyTCPRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{ "cluster":`)
-	fflib.WriteJsonString(buf, string(mj.Cluster))
+	fflib.WriteJsonString(buf, string(j.Cluster))
 	buf.WriteByte(',')
-	if len(mj.DestinationIPList) != 0 {
+	if len(j.DestinationIPList) != 0 {
 		buf.WriteString(`"destination_ip_list":`)
-		if mj.DestinationIPList != nil {
+		if j.DestinationIPList != nil {
 			buf.WriteString(`[`)
-			for i, v := range mj.DestinationIPList {
+			for i, v := range j.DestinationIPList {
 				if i != 0 {
 					buf.WriteString(`,`)
 				}
@@ -3794,16 +5479,16 @@ func (mj *EnvoyTCPRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 		}
 		buf.WriteByte(',')
 	}
-	if len(mj.DestinationPorts) != 0 {
+	if len(j.DestinationPorts) != 0 {
 		buf.WriteString(`"destination_ports":`)
-		fflib.WriteJsonString(buf, string(mj.DestinationPorts))
+		fflib.WriteJsonString(buf, string(j.DestinationPorts))
 		buf.WriteByte(',')
 	}
-	if len(mj.SourceIPList) != 0 {
+	if len(j.SourceIPList) != 0 {
 		buf.WriteString(`"source_ip_list":`)
-		if mj.SourceIPList != nil {
+		if j.SourceIPList != nil {
 			buf.WriteString(`[`)
-			for i, v := range mj.SourceIPList {
+			for i, v := range j.SourceIPList {
 				if i != 0 {
 					buf.WriteString(`,`)
 				}
@@ -3815,11 +5500,11 @@ func (mj *EnvoyTCPRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 		}
 		buf.WriteByte(',')
 	}
-	if len(mj.SourcePorts) != 0 {
+	if len(j.SourcePorts) != 0 {
 		buf.WriteString(`"source_ports":`)
-		if mj.SourcePorts != nil {
+		if j.SourcePorts != nil {
 			buf.WriteString(`[`)
-			for i, v := range mj.SourcePorts {
+			for i, v := range j.SourcePorts {
 				if i != 0 {
 					buf.WriteString(`,`)
 				}
@@ -3837,38 +5522,40 @@ func (mj *EnvoyTCPRoute) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_EnvoyTCPRoutebase = iota
-	ffj_t_EnvoyTCPRouteno_such_key
+	ffjtEnvoyTCPRoutebase = iota
+	ffjtEnvoyTCPRoutenosuchkey
 
-	ffj_t_EnvoyTCPRoute_Cluster
+	ffjtEnvoyTCPRouteCluster
 
-	ffj_t_EnvoyTCPRoute_DestinationIPList
+	ffjtEnvoyTCPRouteDestinationIPList
 
-	ffj_t_EnvoyTCPRoute_DestinationPorts
+	ffjtEnvoyTCPRouteDestinationPorts
 
-	ffj_t_EnvoyTCPRoute_SourceIPList
+	ffjtEnvoyTCPRouteSourceIPList
 
-	ffj_t_EnvoyTCPRoute_SourcePorts
+	ffjtEnvoyTCPRouteSourcePorts
 )
 
-var ffj_key_EnvoyTCPRoute_Cluster = []byte("cluster")
+var ffjKeyEnvoyTCPRouteCluster = []byte("cluster")
 
-var ffj_key_EnvoyTCPRoute_DestinationIPList = []byte("destination_ip_list")
+var ffjKeyEnvoyTCPRouteDestinationIPList = []byte("destination_ip_list")
 
-var ffj_key_EnvoyTCPRoute_DestinationPorts = []byte("destination_ports")
+var ffjKeyEnvoyTCPRouteDestinationPorts = []byte("destination_ports")
 
-var ffj_key_EnvoyTCPRoute_SourceIPList = []byte("source_ip_list")
+var ffjKeyEnvoyTCPRouteSourceIPList = []byte("source_ip_list")
 
-var ffj_key_EnvoyTCPRoute_SourcePorts = []byte("source_ports")
+var ffjKeyEnvoyTCPRouteSourcePorts = []byte("source_ports")
 
-func (uj *EnvoyTCPRoute) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyTCPRoute) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyTCPRoute) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyTCPRoutebase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyTCPRoute) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyTCPRoutebase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -3914,7 +5601,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyTCPRouteno_such_key
+				currentKey = ffjtEnvoyTCPRoutenosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -3922,71 +5609,71 @@ mainparse:
 
 				case 'c':
 
-					if bytes.Equal(ffj_key_EnvoyTCPRoute_Cluster, kn) {
-						currentKey = ffj_t_EnvoyTCPRoute_Cluster
+					if bytes.Equal(ffjKeyEnvoyTCPRouteCluster, kn) {
+						currentKey = ffjtEnvoyTCPRouteCluster
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'd':
 
-					if bytes.Equal(ffj_key_EnvoyTCPRoute_DestinationIPList, kn) {
-						currentKey = ffj_t_EnvoyTCPRoute_DestinationIPList
+					if bytes.Equal(ffjKeyEnvoyTCPRouteDestinationIPList, kn) {
+						currentKey = ffjtEnvoyTCPRouteDestinationIPList
 						state = fflib.FFParse_want_colon
 						goto mainparse
 
-					} else if bytes.Equal(ffj_key_EnvoyTCPRoute_DestinationPorts, kn) {
-						currentKey = ffj_t_EnvoyTCPRoute_DestinationPorts
+					} else if bytes.Equal(ffjKeyEnvoyTCPRouteDestinationPorts, kn) {
+						currentKey = ffjtEnvoyTCPRouteDestinationPorts
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 's':
 
-					if bytes.Equal(ffj_key_EnvoyTCPRoute_SourceIPList, kn) {
-						currentKey = ffj_t_EnvoyTCPRoute_SourceIPList
+					if bytes.Equal(ffjKeyEnvoyTCPRouteSourceIPList, kn) {
+						currentKey = ffjtEnvoyTCPRouteSourceIPList
 						state = fflib.FFParse_want_colon
 						goto mainparse
 
-					} else if bytes.Equal(ffj_key_EnvoyTCPRoute_SourcePorts, kn) {
-						currentKey = ffj_t_EnvoyTCPRoute_SourcePorts
+					} else if bytes.Equal(ffjKeyEnvoyTCPRouteSourcePorts, kn) {
+						currentKey = ffjtEnvoyTCPRouteSourcePorts
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyTCPRoute_SourcePorts, kn) {
-					currentKey = ffj_t_EnvoyTCPRoute_SourcePorts
+				if fflib.EqualFoldRight(ffjKeyEnvoyTCPRouteSourcePorts, kn) {
+					currentKey = ffjtEnvoyTCPRouteSourcePorts
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyTCPRoute_SourceIPList, kn) {
-					currentKey = ffj_t_EnvoyTCPRoute_SourceIPList
+				if fflib.EqualFoldRight(ffjKeyEnvoyTCPRouteSourceIPList, kn) {
+					currentKey = ffjtEnvoyTCPRouteSourceIPList
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyTCPRoute_DestinationPorts, kn) {
-					currentKey = ffj_t_EnvoyTCPRoute_DestinationPorts
+				if fflib.EqualFoldRight(ffjKeyEnvoyTCPRouteDestinationPorts, kn) {
+					currentKey = ffjtEnvoyTCPRouteDestinationPorts
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyTCPRoute_DestinationIPList, kn) {
-					currentKey = ffj_t_EnvoyTCPRoute_DestinationIPList
+				if fflib.EqualFoldRight(ffjKeyEnvoyTCPRouteDestinationIPList, kn) {
+					currentKey = ffjtEnvoyTCPRouteDestinationIPList
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_EnvoyTCPRoute_Cluster, kn) {
-					currentKey = ffj_t_EnvoyTCPRoute_Cluster
+				if fflib.EqualFoldRight(ffjKeyEnvoyTCPRouteCluster, kn) {
+					currentKey = ffjtEnvoyTCPRouteCluster
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyTCPRouteno_such_key
+				currentKey = ffjtEnvoyTCPRoutenosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -4003,22 +5690,22 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyTCPRoute_Cluster:
+				case ffjtEnvoyTCPRouteCluster:
 					goto handle_Cluster
 
-				case ffj_t_EnvoyTCPRoute_DestinationIPList:
+				case ffjtEnvoyTCPRouteDestinationIPList:
 					goto handle_DestinationIPList
 
-				case ffj_t_EnvoyTCPRoute_DestinationPorts:
+				case ffjtEnvoyTCPRouteDestinationPorts:
 					goto handle_DestinationPorts
 
-				case ffj_t_EnvoyTCPRoute_SourceIPList:
+				case ffjtEnvoyTCPRouteSourceIPList:
 					goto handle_SourceIPList
 
-				case ffj_t_EnvoyTCPRoute_SourcePorts:
+				case ffjtEnvoyTCPRouteSourcePorts:
 					goto handle_SourcePorts
 
-				case ffj_t_EnvoyTCPRouteno_such_key:
+				case ffjtEnvoyTCPRoutenosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -4034,7 +5721,7 @@ mainparse:
 
 handle_Cluster:
 
-	/* handler: uj.Cluster type=string kind=string quoted=false*/
+	/* handler: j.Cluster type=string kind=string quoted=false*/
 
 	{
 
@@ -4050,7 +5737,7 @@ handle_Cluster:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Cluster = string(string(outBuf))
+			j.Cluster = string(string(outBuf))
 
 		}
 	}
@@ -4060,7 +5747,7 @@ handle_Cluster:
 
 handle_DestinationIPList:
 
-	/* handler: uj.DestinationIPList type=[]string kind=slice quoted=false*/
+	/* handler: j.DestinationIPList type=[]string kind=slice quoted=false*/
 
 	{
 
@@ -4071,16 +5758,16 @@ handle_DestinationIPList:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.DestinationIPList = nil
+			j.DestinationIPList = nil
 		} else {
 
-			uj.DestinationIPList = []string{}
+			j.DestinationIPList = []string{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__DestinationIPList string
+				var tmpJDestinationIPList string
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -4101,7 +5788,7 @@ handle_DestinationIPList:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__DestinationIPList type=string kind=string quoted=false*/
+				/* handler: tmpJDestinationIPList type=string kind=string quoted=false*/
 
 				{
 
@@ -4117,12 +5804,12 @@ handle_DestinationIPList:
 
 						outBuf := fs.Output.Bytes()
 
-						tmp_uj__DestinationIPList = string(string(outBuf))
+						tmpJDestinationIPList = string(string(outBuf))
 
 					}
 				}
 
-				uj.DestinationIPList = append(uj.DestinationIPList, tmp_uj__DestinationIPList)
+				j.DestinationIPList = append(j.DestinationIPList, tmpJDestinationIPList)
 
 				wantVal = false
 			}
@@ -4134,7 +5821,7 @@ handle_DestinationIPList:
 
 handle_DestinationPorts:
 
-	/* handler: uj.DestinationPorts type=string kind=string quoted=false*/
+	/* handler: j.DestinationPorts type=string kind=string quoted=false*/
 
 	{
 
@@ -4150,7 +5837,7 @@ handle_DestinationPorts:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.DestinationPorts = string(string(outBuf))
+			j.DestinationPorts = string(string(outBuf))
 
 		}
 	}
@@ -4160,7 +5847,7 @@ handle_DestinationPorts:
 
 handle_SourceIPList:
 
-	/* handler: uj.SourceIPList type=[]string kind=slice quoted=false*/
+	/* handler: j.SourceIPList type=[]string kind=slice quoted=false*/
 
 	{
 
@@ -4171,16 +5858,16 @@ handle_SourceIPList:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.SourceIPList = nil
+			j.SourceIPList = nil
 		} else {
 
-			uj.SourceIPList = []string{}
+			j.SourceIPList = []string{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__SourceIPList string
+				var tmpJSourceIPList string
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -4201,7 +5888,7 @@ handle_SourceIPList:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__SourceIPList type=string kind=string quoted=false*/
+				/* handler: tmpJSourceIPList type=string kind=string quoted=false*/
 
 				{
 
@@ -4217,12 +5904,12 @@ handle_SourceIPList:
 
 						outBuf := fs.Output.Bytes()
 
-						tmp_uj__SourceIPList = string(string(outBuf))
+						tmpJSourceIPList = string(string(outBuf))
 
 					}
 				}
 
-				uj.SourceIPList = append(uj.SourceIPList, tmp_uj__SourceIPList)
+				j.SourceIPList = append(j.SourceIPList, tmpJSourceIPList)
 
 				wantVal = false
 			}
@@ -4234,7 +5921,7 @@ handle_SourceIPList:
 
 handle_SourcePorts:
 
-	/* handler: uj.SourcePorts type=[]string kind=slice quoted=false*/
+	/* handler: j.SourcePorts type=[]string kind=slice quoted=false*/
 
 	{
 
@@ -4245,16 +5932,16 @@ handle_SourcePorts:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.SourcePorts = nil
+			j.SourcePorts = nil
 		} else {
 
-			uj.SourcePorts = []string{}
+			j.SourcePorts = []string{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__SourcePorts string
+				var tmpJSourcePorts string
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -4275,7 +5962,7 @@ handle_SourcePorts:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__SourcePorts type=string kind=string quoted=false*/
+				/* handler: tmpJSourcePorts type=string kind=string quoted=false*/
 
 				{
 
@@ -4291,12 +5978,12 @@ handle_SourcePorts:
 
 						outBuf := fs.Output.Bytes()
 
-						tmp_uj__SourcePorts = string(string(outBuf))
+						tmpJSourcePorts = string(string(outBuf))
 
 					}
 				}
 
-				uj.SourcePorts = append(uj.SourcePorts, tmp_uj__SourcePorts)
+				j.SourcePorts = append(j.SourcePorts, tmpJSourcePorts)
 
 				wantVal = false
 			}
@@ -4324,20 +6011,23 @@ done:
 	return nil
 }
 
-func (mj *EnvoyTracingConfig) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *EnvoyTracingConfig) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *EnvoyTracingConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *EnvoyTracingConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -4346,28 +6036,30 @@ func (mj *EnvoyTracingConfig) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{"operation_name":`)
-	fflib.WriteJsonString(buf, string(mj.OperationName))
+	fflib.WriteJsonString(buf, string(j.OperationName))
 	buf.WriteByte('}')
 	return nil
 }
 
 const (
-	ffj_t_EnvoyTracingConfigbase = iota
-	ffj_t_EnvoyTracingConfigno_such_key
+	ffjtEnvoyTracingConfigbase = iota
+	ffjtEnvoyTracingConfignosuchkey
 
-	ffj_t_EnvoyTracingConfig_OperationName
+	ffjtEnvoyTracingConfigOperationName
 )
 
-var ffj_key_EnvoyTracingConfig_OperationName = []byte("operation_name")
+var ffjKeyEnvoyTracingConfigOperationName = []byte("operation_name")
 
-func (uj *EnvoyTracingConfig) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *EnvoyTracingConfig) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *EnvoyTracingConfig) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_EnvoyTracingConfigbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *EnvoyTracingConfig) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtEnvoyTracingConfigbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -4413,7 +6105,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_EnvoyTracingConfigno_such_key
+				currentKey = ffjtEnvoyTracingConfignosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -4421,21 +6113,21 @@ mainparse:
 
 				case 'o':
 
-					if bytes.Equal(ffj_key_EnvoyTracingConfig_OperationName, kn) {
-						currentKey = ffj_t_EnvoyTracingConfig_OperationName
+					if bytes.Equal(ffjKeyEnvoyTracingConfigOperationName, kn) {
+						currentKey = ffjtEnvoyTracingConfigOperationName
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.AsciiEqualFold(ffj_key_EnvoyTracingConfig_OperationName, kn) {
-					currentKey = ffj_t_EnvoyTracingConfig_OperationName
+				if fflib.AsciiEqualFold(ffjKeyEnvoyTracingConfigOperationName, kn) {
+					currentKey = ffjtEnvoyTracingConfigOperationName
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_EnvoyTracingConfigno_such_key
+				currentKey = ffjtEnvoyTracingConfignosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -4452,10 +6144,10 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_EnvoyTracingConfig_OperationName:
+				case ffjtEnvoyTracingConfigOperationName:
 					goto handle_OperationName
 
-				case ffj_t_EnvoyTracingConfigno_such_key:
+				case ffjtEnvoyTracingConfignosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -4471,7 +6163,7 @@ mainparse:
 
 handle_OperationName:
 
-	/* handler: uj.OperationName type=string kind=string quoted=false*/
+	/* handler: j.OperationName type=string kind=string quoted=false*/
 
 	{
 
@@ -4487,7 +6179,7 @@ handle_OperationName:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.OperationName = string(string(outBuf))
+			j.OperationName = string(string(outBuf))
 
 		}
 	}
@@ -4513,20 +6205,23 @@ done:
 	return nil
 }
 
-func (mj *LDSResult) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *LDSResult) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *LDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *LDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -4535,9 +6230,9 @@ func (mj *LDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{"listeners":`)
-	if mj.Listeners != nil {
+	if j.Listeners != nil {
 		buf.WriteString(`[`)
-		for i, v := range mj.Listeners {
+		for i, v := range j.Listeners {
 			if i != 0 {
 				buf.WriteString(`,`)
 			}
@@ -4546,12 +6241,13 @@ func (mj *LDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 
 				if v == nil {
 					buf.WriteString("null")
-					return nil
-				}
+				} else {
+
+					err = v.MarshalJSONBuf(buf)
+					if err != nil {
+						return err
+					}
 
-				err = v.MarshalJSONBuf(buf)
-				if err != nil {
-					return err
 				}
 
 			}
@@ -4565,22 +6261,24 @@ func (mj *LDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 }
 
 const (
-	ffj_t_LDSResultbase = iota
-	ffj_t_LDSResultno_such_key
+	ffjtLDSResultbase = iota
+	ffjtLDSResultnosuchkey
 
-	ffj_t_LDSResult_Listeners
+	ffjtLDSResultListeners
 )
 
-var ffj_key_LDSResult_Listeners = []byte("listeners")
+var ffjKeyLDSResultListeners = []byte("listeners")
 
-func (uj *LDSResult) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *LDSResult) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *LDSResult) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_LDSResultbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *LDSResult) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtLDSResultbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -4626,7 +6324,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_LDSResultno_such_key
+				currentKey = ffjtLDSResultnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -4634,21 +6332,21 @@ mainparse:
 
 				case 'l':
 
-					if bytes.Equal(ffj_key_LDSResult_Listeners, kn) {
-						currentKey = ffj_t_LDSResult_Listeners
+					if bytes.Equal(ffjKeyLDSResultListeners, kn) {
+						currentKey = ffjtLDSResultListeners
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_LDSResult_Listeners, kn) {
-					currentKey = ffj_t_LDSResult_Listeners
+				if fflib.EqualFoldRight(ffjKeyLDSResultListeners, kn) {
+					currentKey = ffjtLDSResultListeners
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_LDSResultno_such_key
+				currentKey = ffjtLDSResultnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -4665,10 +6363,10 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_LDSResult_Listeners:
+				case ffjtLDSResultListeners:
 					goto handle_Listeners
 
-				case ffj_t_LDSResultno_such_key:
+				case ffjtLDSResultnosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -4684,7 +6382,7 @@ mainparse:
 
 handle_Listeners:
 
-	/* handler: uj.Listeners type=[]*envoyhttp.EnvoyListener kind=slice quoted=false*/
+	/* handler: j.Listeners type=[]*envoyhttp.EnvoyListener kind=slice quoted=false*/
 
 	{
 
@@ -4695,16 +6393,16 @@ handle_Listeners:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Listeners = nil
+			j.Listeners = nil
 		} else {
 
-			uj.Listeners = []*EnvoyListener{}
+			j.Listeners = []*EnvoyListener{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__Listeners *EnvoyListener
+				var tmpJListeners *EnvoyListener
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -4725,29 +6423,28 @@ handle_Listeners:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__Listeners type=*envoyhttp.EnvoyListener kind=ptr quoted=false*/
+				/* handler: tmpJListeners type=*envoyhttp.EnvoyListener kind=ptr quoted=false*/
 
 				{
 					if tok == fflib.FFTok_null {
 
-						tmp_uj__Listeners = nil
+						tmpJListeners = nil
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+					} else {
 
-					if tmp_uj__Listeners == nil {
-						tmp_uj__Listeners = new(EnvoyListener)
-					}
+						if tmpJListeners == nil {
+							tmpJListeners = new(EnvoyListener)
+						}
 
-					err = tmp_uj__Listeners.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
+						err = tmpJListeners.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
 					}
 					state = fflib.FFParse_after_value
 				}
 
-				uj.Listeners = append(uj.Listeners, tmp_uj__Listeners)
+				j.Listeners = append(j.Listeners, tmpJListeners)
 
 				wantVal = false
 			}
@@ -4775,20 +6472,23 @@ done:
 	return nil
 }
 
-func (mj *SDSResult) MarshalJSON() ([]byte, error) {
+// MarshalJSON marshal bytes to json - template
+func (j *SDSResult) MarshalJSON() ([]byte, error) {
 	var buf fflib.Buffer
-	if mj == nil {
+	if j == nil {
 		buf.WriteString("null")
 		return buf.Bytes(), nil
 	}
-	err := mj.MarshalJSONBuf(&buf)
+	err := j.MarshalJSONBuf(&buf)
 	if err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
-func (mj *SDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
-	if mj == nil {
+
+// MarshalJSONBuf marshal buff to json - template
+func (j *SDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
+	if j == nil {
 		buf.WriteString("null")
 		return nil
 	}
@@ -4797,11 +6497,11 @@ func (mj *SDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 	_ = obj
 	_ = err
 	buf.WriteString(`{"env":`)
-	fflib.WriteJsonString(buf, string(mj.Env))
+	fflib.WriteJsonString(buf, string(j.Env))
 	buf.WriteString(`,"hosts":`)
-	if mj.Hosts != nil {
+	if j.Hosts != nil {
 		buf.WriteString(`[`)
-		for i, v := range mj.Hosts {
+		for i, v := range j.Hosts {
 			if i != 0 {
 				buf.WriteString(`,`)
 			}
@@ -4810,12 +6510,13 @@ func (mj *SDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 
 				if v == nil {
 					buf.WriteString("null")
-					return nil
-				}
+				} else {
+
+					err = v.MarshalJSONBuf(buf)
+					if err != nil {
+						return err
+					}
 
-				err = v.MarshalJSONBuf(buf)
-				if err != nil {
-					return err
 				}
 
 			}
@@ -4825,36 +6526,38 @@ func (mj *SDSResult) MarshalJSONBuf(buf fflib.EncodingBuffer) error {
 		buf.WriteString(`null`)
 	}
 	buf.WriteString(`,"service":`)
-	fflib.WriteJsonString(buf, string(mj.Service))
+	fflib.WriteJsonString(buf, string(j.Service))
 	buf.WriteByte('}')
 	return nil
 }
 
 const (
-	ffj_t_SDSResultbase = iota
-	ffj_t_SDSResultno_such_key
+	ffjtSDSResultbase = iota
+	ffjtSDSResultnosuchkey
 
-	ffj_t_SDSResult_Env
+	ffjtSDSResultEnv
 
-	ffj_t_SDSResult_Hosts
+	ffjtSDSResultHosts
 
-	ffj_t_SDSResult_Service
+	ffjtSDSResultService
 )
 
-var ffj_key_SDSResult_Env = []byte("env")
+var ffjKeySDSResultEnv = []byte("env")
 
-var ffj_key_SDSResult_Hosts = []byte("hosts")
+var ffjKeySDSResultHosts = []byte("hosts")
 
-var ffj_key_SDSResult_Service = []byte("service")
+var ffjKeySDSResultService = []byte("service")
 
-func (uj *SDSResult) UnmarshalJSON(input []byte) error {
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *SDSResult) UnmarshalJSON(input []byte) error {
 	fs := fflib.NewFFLexer(input)
-	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+	return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
-func (uj *SDSResult) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
-	var err error = nil
-	currentKey := ffj_t_SDSResultbase
+// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
+func (j *SDSResult) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	currentKey := ffjtSDSResultbase
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
@@ -4900,7 +6603,7 @@ mainparse:
 			kn := fs.Output.Bytes()
 			if len(kn) <= 0 {
 				// "" case. hrm.
-				currentKey = ffj_t_SDSResultno_such_key
+				currentKey = ffjtSDSResultnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			} else {
@@ -4908,49 +6611,49 @@ mainparse:
 
 				case 'e':
 
-					if bytes.Equal(ffj_key_SDSResult_Env, kn) {
-						currentKey = ffj_t_SDSResult_Env
+					if bytes.Equal(ffjKeySDSResultEnv, kn) {
+						currentKey = ffjtSDSResultEnv
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 'h':
 
-					if bytes.Equal(ffj_key_SDSResult_Hosts, kn) {
-						currentKey = ffj_t_SDSResult_Hosts
+					if bytes.Equal(ffjKeySDSResultHosts, kn) {
+						currentKey = ffjtSDSResultHosts
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				case 's':
 
-					if bytes.Equal(ffj_key_SDSResult_Service, kn) {
-						currentKey = ffj_t_SDSResult_Service
+					if bytes.Equal(ffjKeySDSResultService, kn) {
+						currentKey = ffjtSDSResultService
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					}
 
 				}
 
-				if fflib.EqualFoldRight(ffj_key_SDSResult_Service, kn) {
-					currentKey = ffj_t_SDSResult_Service
+				if fflib.EqualFoldRight(ffjKeySDSResultService, kn) {
+					currentKey = ffjtSDSResultService
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.EqualFoldRight(ffj_key_SDSResult_Hosts, kn) {
-					currentKey = ffj_t_SDSResult_Hosts
+				if fflib.EqualFoldRight(ffjKeySDSResultHosts, kn) {
+					currentKey = ffjtSDSResultHosts
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				if fflib.SimpleLetterEqualFold(ffj_key_SDSResult_Env, kn) {
-					currentKey = ffj_t_SDSResult_Env
+				if fflib.SimpleLetterEqualFold(ffjKeySDSResultEnv, kn) {
+					currentKey = ffjtSDSResultEnv
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 
-				currentKey = ffj_t_SDSResultno_such_key
+				currentKey = ffjtSDSResultnosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
 			}
@@ -4967,16 +6670,16 @@ mainparse:
 			if tok == fflib.FFTok_left_brace || tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_integer || tok == fflib.FFTok_double || tok == fflib.FFTok_string || tok == fflib.FFTok_bool || tok == fflib.FFTok_null {
 				switch currentKey {
 
-				case ffj_t_SDSResult_Env:
+				case ffjtSDSResultEnv:
 					goto handle_Env
 
-				case ffj_t_SDSResult_Hosts:
+				case ffjtSDSResultHosts:
 					goto handle_Hosts
 
-				case ffj_t_SDSResult_Service:
+				case ffjtSDSResultService:
 					goto handle_Service
 
-				case ffj_t_SDSResultno_such_key:
+				case ffjtSDSResultnosuchkey:
 					err = fs.SkipField(tok)
 					if err != nil {
 						return fs.WrapErr(err)
@@ -4992,7 +6695,7 @@ mainparse:
 
 handle_Env:
 
-	/* handler: uj.Env type=string kind=string quoted=false*/
+	/* handler: j.Env type=string kind=string quoted=false*/
 
 	{
 
@@ -5008,7 +6711,7 @@ handle_Env:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Env = string(string(outBuf))
+			j.Env = string(string(outBuf))
 
 		}
 	}
@@ -5018,7 +6721,7 @@ handle_Env:
 
 handle_Hosts:
 
-	/* handler: uj.Hosts type=[]*envoyhttp.EnvoyService kind=slice quoted=false*/
+	/* handler: j.Hosts type=[]*envoyhttp.EnvoyService kind=slice quoted=false*/
 
 	{
 
@@ -5029,16 +6732,16 @@ handle_Hosts:
 		}
 
 		if tok == fflib.FFTok_null {
-			uj.Hosts = nil
+			j.Hosts = nil
 		} else {
 
-			uj.Hosts = []*EnvoyService{}
+			j.Hosts = []*EnvoyService{}
 
 			wantVal := true
 
 			for {
 
-				var tmp_uj__Hosts *EnvoyService
+				var tmpJHosts *EnvoyService
 
 				tok = fs.Scan()
 				if tok == fflib.FFTok_error {
@@ -5059,29 +6762,28 @@ handle_Hosts:
 					wantVal = true
 				}
 
-				/* handler: tmp_uj__Hosts type=*envoyhttp.EnvoyService kind=ptr quoted=false*/
+				/* handler: tmpJHosts type=*envoyhttp.EnvoyService kind=ptr quoted=false*/
 
 				{
 					if tok == fflib.FFTok_null {
 
-						tmp_uj__Hosts = nil
+						tmpJHosts = nil
 
-						state = fflib.FFParse_after_value
-						goto mainparse
-					}
+					} else {
 
-					if tmp_uj__Hosts == nil {
-						tmp_uj__Hosts = new(EnvoyService)
-					}
+						if tmpJHosts == nil {
+							tmpJHosts = new(EnvoyService)
+						}
 
-					err = tmp_uj__Hosts.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
-					if err != nil {
-						return err
+						err = tmpJHosts.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
+						if err != nil {
+							return err
+						}
 					}
 					state = fflib.FFParse_after_value
 				}
 
-				uj.Hosts = append(uj.Hosts, tmp_uj__Hosts)
+				j.Hosts = append(j.Hosts, tmpJHosts)
 
 				wantVal = false
 			}
@@ -5093,7 +6795,7 @@ handle_Hosts:
 
 handle_Service:
 
-	/* handler: uj.Service type=string kind=string quoted=false*/
+	/* handler: j.Service type=string kind=string quoted=false*/
 
 	{
 
@@ -5109,7 +6811,7 @@ handle_Service:
 
 			outBuf := fs.Output.Bytes()
 
-			uj.Service = string(string(outBuf))
+			j.Service = string(string(outBuf))
 
 		}
 	}
@@ -32,6 +32,9 @@ var (
 		ServiceName:     "chretien",
 		ProxyMode:       "http",
 		Action:          shimrpc.RegistrarRequest_REGISTER,
+		ContainerId:     "deadbeef0002",
+		ContainerName:   "chretien-1",
+		Image:           "nginx:1.19",
 	}
 
 	req3 = &shimrpc.RegistrarRequest{
@@ -44,6 +47,26 @@ var (
 		ProxyMode:       "http",
 		Action:          shimrpc.RegistrarRequest_REGISTER,
 	}
+
+	tunedReq = &shimrpc.RegistrarRequest{
+		FrontendAddr:    "192.168.168.96",
+		FrontendPort:    24680,
+		BackendAddr:     "172.16.10.4",
+		BackendPort:     8000,
+		EnvironmentName: "dev",
+		ServiceName:     "mandeville",
+		ProxyMode:       "http",
+		Action:          shimrpc.RegistrarRequest_REGISTER,
+		Tuning: map[string]string{
+			"EnvoyConnectTimeout": "2s",
+			"EnvoyRouteTimeout":   "15s",
+			"EnvoyIdleTimeout":    "90s",
+			"EnvoyRetryOn":        "5xx",
+			"EnvoyNumRetries":     "3",
+			"EnvoyLBPolicy":       "least_request",
+			"EnvoyMaxConnections": "100",
+		},
+	}
 )
 
 func Test_clustersHandler(t *testing.T) {
@@ -66,6 +89,44 @@ func Test_clustersHandler(t *testing.T) {
 			So(body, ShouldContainSubstring, "hakluyt")
 		})
 
+		Convey("uses the original settings without tuning", func() {
+			cluster := api.EnvoyClustersFromRegistrar()[0]
+
+			So(cluster.ConnectTimeoutMs, ShouldEqual, 500)
+			So(cluster.LBType, ShouldEqual, "round_robin")
+			So(cluster.CircuitBreakers, ShouldBeNil)
+		})
+
+		Convey("applies the tuning", func() {
+			registrar.Register(context.Background(), tunedReq)
+
+			api.clustersHandler(recorder, req, nil)
+			_, _, body := getResult(recorder)
+
+			So(body, ShouldContainSubstring, `"connect_timeout_ms":2000`)
+			So(body, ShouldContainSubstring, `"lb_type":"least_request"`)
+			So(body, ShouldContainSubstring, `"circuit_breakers":{"default":{ "max_connections":100}}`)
+		})
+
+		Convey("health checks when asked to", func() {
+			req := *req1
+			req.Tuning = map[string]string{"EnvoyHealthCheck": "tcp"}
+			registrar.Register(context.Background(), &req)
+
+			checked := *req3
+			checked.Tuning = map[string]string{
+				"EnvoyHealthCheck":     "http",
+				"EnvoyHealthCheckPath": "/status",
+			}
+			registrar.Register(context.Background(), &checked)
+
+			api.clustersHandler(recorder, httptest.NewRequest("GET", "/clusters", nil), nil)
+			_, _, body := getResult(recorder)
+
+			So(body, ShouldContainSubstring, `"health_check":{ "type":"tcp","timeout_ms":2000,"interval_ms":10000,"unhealthy_threshold":3,"healthy_threshold":2,"send":[],"receive":[]}`)
+			So(body, ShouldContainSubstring, `"path":"/status"`)
+		})
+
 		Convey("does not include deregistered services", func() {
 			req1.Action = shimrpc.RegistrarRequest_DEREGISTER
 			registrar.Register(context.Background(), req1)
@@ -122,6 +183,20 @@ func Test_registrationHandler(t *testing.T) {
 			So(body, ShouldContainSubstring, "bede")
 		})
 
+		Convey("tags endpoints with their container", func() {
+			req := httptest.NewRequest("GET", "/registration/chretien-dev-23451", nil)
+			params := map[string]string{
+				"service": "chretien-dev-23451",
+			}
+			api.registrationHandler(recorder, req, params)
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `"container_id":"deadbeef0002"`)
+			So(body, ShouldContainSubstring, `"container_name":"chretien-1"`)
+			So(body, ShouldContainSubstring, `"image":"nginx:1.19"`)
+		})
+
 		Convey("does not include deregistered endpoints", func() {
 			req1.Action = shimrpc.RegistrarRequest_DEREGISTER
 			registrar.Register(context.Background(), req1)
@@ -162,6 +237,36 @@ func Test_listenersHandler(t *testing.T) {
 			So(body, ShouldContainSubstring, "chretien")
 		})
 
+		Convey("applies the tuning", func() {
+			registrar.Register(context.Background(), tunedReq)
+
+			req := httptest.NewRequest("GET", "/listeners/", nil)
+			api.listenersHandler(recorder, req, nil)
+			_, _, body := getResult(recorder)
+
+			So(body, ShouldContainSubstring, `"timeout_ms":15000`)
+			So(body, ShouldContainSubstring, `"retry_policy":{ "retry_on":"5xx","num_retries":3}`)
+			So(body, ShouldContainSubstring, `"idle_timeout_s":90`)
+		})
+
+		Convey("brackets IPv6 addresses", func() {
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr: "2001:db8::1",
+				FrontendPort: 8443,
+				BackendAddr:  "fd00::4",
+				BackendPort:  443,
+				ServiceName:  "dampier",
+				ProxyMode:    "tcp",
+			})
+
+			req := httptest.NewRequest("GET", "/listeners/", nil)
+			api.listenersHandler(recorder, req, nil)
+			_, _, body := getResult(recorder)
+
+			So(body, ShouldContainSubstring, "tcp://[2001:db8::1]:8443")
+			So(body, ShouldContainSubstring, "tcp://192.168.168.99:12345")
+		})
+
 		Convey("respects the ProxyMode setting when returning results", func() {
 
 			Convey("for TCP mode", func() {
@@ -183,6 +288,46 @@ func Test_listenersHandler(t *testing.T) {
 				So(body, ShouldNotContainSubstring, "chretien")
 			})
 
+			Convey("skipping UDP entries", func() {
+				registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+					FrontendAddr: "192.168.168.99",
+					FrontendPort: 53,
+					BackendAddr:  "172.16.10.4",
+					BackendPort:  53,
+					ServiceName:  "dampier",
+					ProxyMode:    "udp-envoy",
+					Protocol:     "udp",
+				})
+
+				req := httptest.NewRequest("GET", "/listeners/", nil)
+				api.listenersHandler(recorder, req, nil)
+				status, _, body := getResult(recorder)
+
+				So(status, ShouldEqual, 200)
+				So(body, ShouldContainSubstring, "bede")
+				So(body, ShouldNotContainSubstring, "dampier")
+			})
+
+			Convey("skipping entries that need TLS", func() {
+				registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+					FrontendAddr: "192.168.168.99",
+					FrontendPort: 8443,
+					BackendAddr:  "172.16.10.4",
+					BackendPort:  443,
+					ServiceName:  "dampier",
+					ProxyMode:    "http",
+					Tuning:       map[string]string{"EnvoyTLSSecret": "dampier"},
+				})
+
+				req := httptest.NewRequest("GET", "/listeners/", nil)
+				api.listenersHandler(recorder, req, nil)
+				status, _, body := getResult(recorder)
+
+				So(status, ShouldEqual, 200)
+				So(body, ShouldContainSubstring, "bede")
+				So(body, ShouldNotContainSubstring, "dampier")
+			})
+
 			Convey("for HTTP mode", func() {
 				req1.Action = shimrpc.RegistrarRequest_DEREGISTER
 				registrar.Register(context.Background(), req1)
@@ -201,6 +346,83 @@ func Test_listenersHandler(t *testing.T) {
 	})
 }
 
+func Test_healthHandler(t *testing.T) {
+	Convey("healthHandler()", t, func() {
+		registrar := NewRegistrar()
+		registrar.Register(context.Background(), req1)
+
+		checked := *req3
+		checked.Tuning = map[string]string{"EnvoyHealthCheck": "http"}
+		registrar.Register(context.Background(), &checked)
+
+		adminStatus := http.StatusOK
+		admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/clusters" || r.URL.Query().Get("format") != "json" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(adminStatus)
+			w.Write([]byte(`{"cluster_statuses":[{"name":"hakluyt-dev-23555","host_statuses":[
+				{"address":{"socket_address":{"address":"172.16.10.3","port_value":9000}},
+				 "health_status":{"failed_active_health_check":true,"eds_health_status":"HEALTHY"}}
+			]}]}`))
+		}))
+		defer admin.Close()
+
+		api := NewEnvoyApi(registrar)
+		api.Admin = NewEnvoyAdmin(admin.URL + "/")
+
+		req := httptest.NewRequest("GET", "/health", nil)
+		recorder := httptest.NewRecorder()
+
+		Convey("returns Envoy's view of every service", func() {
+			api.healthHandler(recorder, req, nil)
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `{"name":"bede-dev-12345","hosts":[]}`)
+			So(body, ShouldContainSubstring,
+				`{"name":"hakluyt-dev-23555","health_check":"http","hosts":[{"address":"172.16.10.3:9000","healthy":false,"failed_active_health_check":true,"eds_health_status":"HEALTHY"}]}`,
+			)
+		})
+
+		Convey("returns a single service", func() {
+			api.healthHandler(recorder, req, map[string]string{"service": "hakluyt-dev-23555"})
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 200)
+			So(body, ShouldStartWith, `{"name":"hakluyt-dev-23555"`)
+			So(body, ShouldNotContainSubstring, "bede")
+		})
+
+		Convey("returns an error for unknown services", func() {
+			api.healthHandler(recorder, req, map[string]string{"service": "bocaccio"})
+			status, _, _ := getResult(recorder)
+
+			So(status, ShouldEqual, 404)
+		})
+
+		Convey("returns an error when Envoy does", func() {
+			adminStatus = http.StatusInternalServerError
+
+			api.healthHandler(recorder, req, nil)
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 502)
+			So(body, ShouldContainSubstring, "500")
+		})
+
+		Convey("returns an error without an admin URL", func() {
+			api.Admin = nil
+
+			api.healthHandler(recorder, req, nil)
+			status, _, _ := getResult(recorder)
+
+			So(status, ShouldEqual, 503)
+		})
+	})
+}
+
 func Test_HttpMux(t *testing.T) {
 	Convey("HttpMux() returns a configured mux", t, func() {
 		registrar := NewRegistrar()
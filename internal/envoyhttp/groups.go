@@ -0,0 +1,114 @@
+package envoyhttp
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Replicas of a service normally each get their own listener and cluster,
+// because they are published on different host ports. Containers that set
+// the ServicePort label also share a listener on that port, and a cluster
+// that load balances across all of them.
+
+// A ServiceGroup is the replicas of a service that share a ServicePort.
+type ServiceGroup struct {
+	Entry   *Entry   // Describes the shared listener and cluster
+	Members []*Entry // The replicas, sorted by name
+}
+
+// GroupName returns the name of the shared listener and cluster for an
+// entry with a ServicePort.
+func GroupName(entry *Entry) string {
+	return withProtocol(entry, fmt.Sprintf("%s-service-%d", serviceAndEnv(entry), entry.ServicePort))
+}
+
+// ServiceGroups returns the groups of replicas that share a ServicePort,
+// sorted by name. Envoy can't listen on a port twice, so a group is left out
+// if a container has published the ServicePort itself, if a shared listener
+// is there, or if a group before it by name already listens there.
+func (r *Registrar) ServiceGroups() []*ServiceGroup {
+	groups := make(map[string]*ServiceGroup)
+	var names []string
+
+	r.EachEntry(func(name string, entry *Entry) error {
+		if entry.ServicePort == 0 {
+			return nil
+		}
+
+		groupName := GroupName(entry)
+		if _, ok := groups[groupName]; !ok {
+			groups[groupName] = &ServiceGroup{}
+			names = append(names, groupName)
+		}
+		groups[groupName].Members = append(groups[groupName].Members, entry)
+
+		return nil
+	})
+
+	sort.Strings(names)
+
+	var result []*ServiceGroup
+	taken := make(map[string]string) // Group names by ListenerKey
+	for _, name := range names {
+		group := groups[name]
+		sort.Slice(group.Members, func(i, j int) bool {
+			return group.Members[i].Name < group.Members[j].Name
+		})
+		group.Entry = groupEntry(name, group.Members[0])
+		group.Entry.DockerHealth = groupHealth(group.Members)
+
+		key := ListenerKey(group.Entry)
+		if existing := r.GetListener(key); existing != nil {
+			log.Warnf("Not serving %s, %s is already registered as %s for %s",
+				name, key, existing.Name, existing.ContainerString(),
+			)
+			continue
+		}
+
+		if shared := r.sharedNameFor(group.Entry.FrontendAddr); len(shared) > 0 && !group.Entry.IsUDP() {
+			log.Errorf("Not serving %s, %s is reserved for the %s", name, key, shared)
+			continue
+		}
+
+		if other, ok := taken[key]; ok {
+			log.Warnf("Not serving %s, %s is already served by %s", name, key, other)
+			continue
+		}
+		taken[key] = name
+
+		result = append(result, group)
+	}
+
+	return result
+}
+
+// GetServiceGroup returns the named group, or nil if there isn't one.
+func (r *Registrar) GetServiceGroup(name string) *ServiceGroup {
+	for _, group := range r.ServiceGroups() {
+		if group.Entry.Name == name {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// groupEntry describes the shared listener and cluster for a group. It
+// listens where the first replica does, but on the ServicePort, and takes
+// its settings from that replica too.
+func groupEntry(name string, first *Entry) *Entry {
+	entry := *first
+	entry.Name = name
+	entry.FrontendAddr = &net.TCPAddr{IP: first.FrontendAddr.IP, Port: first.ServicePort}
+
+	// It doesn't belong to any one container
+	entry.ContainerID = ""
+	entry.ContainerName = ""
+	entry.Image = ""
+	entry.Labels = nil
+
+	return &entry
+}
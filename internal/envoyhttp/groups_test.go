@@ -0,0 +1,92 @@
+package envoyhttp
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ServiceGroups(t *testing.T) {
+	Convey("ServiceGroups()", t, func() {
+		registrar := NewRegistrar()
+
+		replica := func(frontendPort int32, backend string) *shimrpc.RegistrarRequest {
+			return &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    frontendPort,
+				BackendAddr:     backend,
+				BackendPort:     80,
+				EnvironmentName: "dev",
+				ServiceName:     "polo",
+				ProxyMode:       "http",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				ServicePort:     8080,
+			}
+		}
+
+		registrar.Register(context.Background(), replica(32001, "172.16.10.11"))
+		registrar.Register(context.Background(), replica(32000, "172.16.10.10"))
+		registrar.Register(context.Background(), req1)
+
+		Convey("groups the replicas that share a ServicePort", func() {
+			groups := registrar.ServiceGroups()
+
+			So(len(groups), ShouldEqual, 1)
+			So(groups[0].Entry.Name, ShouldEqual, "polo-dev-service-8080")
+			So(groups[0].Entry.FrontendAddr.String(), ShouldEqual, "0.0.0.0:8080")
+			So(len(groups[0].Members), ShouldEqual, 2)
+			So(groups[0].Members[0].Name, ShouldEqual, "polo-dev-32000")
+			So(groups[0].Members[1].Name, ShouldEqual, "polo-dev-32001")
+		})
+
+		Convey("keeps the replicas' own listeners", func() {
+			So(registrar.GetEntry("polo-dev-32000"), ShouldNotBeNil)
+			So(registrar.GetEntry("polo-dev-32001"), ShouldNotBeNil)
+		})
+
+		Convey("leaves out a group whose ServicePort is published", func() {
+			registrar.Register(context.Background(), replica(8080, "172.16.10.12"))
+
+			So(registrar.ServiceGroups(), ShouldBeEmpty)
+		})
+
+		Convey("serves only the first of the groups that share a ServicePort", func() {
+			other := replica(32002, "172.16.10.12")
+			other.ServiceName = "marco"
+			registrar.Register(context.Background(), other)
+
+			groups := registrar.ServiceGroups()
+			So(len(groups), ShouldEqual, 1)
+			So(groups[0].Entry.Name, ShouldEqual, "marco-dev-service-8080")
+		})
+
+		Convey("are served as one SDS service with a host for each replica", func() {
+			api := NewEnvoyApi(registrar)
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/registration/polo-dev-service-8080", nil)
+			api.registrationHandler(recorder, req, map[string]string{"service": "polo-dev-service-8080"})
+			status, _, body := getResult(recorder)
+
+			So(status, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `"ip_address":"172.16.10.10"`)
+			So(body, ShouldContainSubstring, `"ip_address":"172.16.10.11"`)
+
+			Convey("with a cluster and listener of their own", func() {
+				var names []string
+				for _, cluster := range api.EnvoyClustersFromRegistrar() {
+					names = append(names, cluster.Name)
+				}
+				So(names, ShouldContain, "polo-dev-service-8080")
+
+				var addresses []string
+				for _, listener := range api.EnvoyListenersFromRegistrar() {
+					addresses = append(addresses, listener.Address)
+				}
+				So(addresses, ShouldContain, "tcp://0.0.0.0:8080")
+			})
+		})
+	})
+}
@@ -0,0 +1,204 @@
+package envoyhttp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// The health check labels. The rest only apply if EnvoyHealthCheck is set.
+	HealthCheckLabel         = "EnvoyHealthCheck"
+	HealthCheckPathLabel     = "EnvoyHealthCheckPath"
+	HealthCheckIntervalLabel = "EnvoyHealthCheckInterval"
+	HealthCheckTimeoutLabel  = "EnvoyHealthCheckTimeout"
+	HealthyThresholdLabel    = "EnvoyHealthyThreshold"
+	UnhealthyThresholdLabel  = "EnvoyUnhealthyThreshold"
+	HealthCheckStatusesLabel = "EnvoyHealthCheckStatuses"
+
+	// Health check types
+	HealthCheckHTTP = "http"
+	HealthCheckTCP  = "tcp"
+
+	DefaultHealthCheckPath     = "/"
+	DefaultHealthCheckInterval = 10 * time.Second
+	DefaultHealthCheckTimeout  = 2 * time.Second
+	DefaultHealthyThreshold    = 2
+	DefaultUnhealthyThreshold  = 3
+	DefaultHealthCheckStatuses = "200"
+)
+
+var healthCheckTypes = []string{HealthCheckHTTP, HealthCheckTCP}
+
+// HealthCheck holds the settings for Envoy's active health checks of a
+// service's backends. The zero value is no health checks.
+type HealthCheck struct {
+	Type               string        `json:",omitempty"` // HealthCheckHTTP or HealthCheckTCP
+	Path               string        `json:",omitempty"` // HTTP only
+	Interval           time.Duration `json:",omitempty"`
+	Timeout            time.Duration `json:",omitempty"`
+	HealthyThreshold   int           `json:",omitempty"`
+	UnhealthyThreshold int           `json:",omitempty"`
+	Statuses           string        `json:",omitempty"` // HTTP only, like 200-299,304
+}
+
+// Enabled tells us if Envoy should health check the backends.
+func (h *HealthCheck) Enabled() bool {
+	return len(h.Type) > 0
+}
+
+// PathOrDefault returns the path for HTTP health checks.
+func (h *HealthCheck) PathOrDefault() string {
+	if len(h.Path) < 1 {
+		return DefaultHealthCheckPath
+	}
+
+	return h.Path
+}
+
+// IntervalOrDefault returns how often to health check.
+func (h *HealthCheck) IntervalOrDefault() time.Duration {
+	if h.Interval == 0 {
+		return DefaultHealthCheckInterval
+	}
+
+	return h.Interval
+}
+
+// TimeoutOrDefault returns how long to wait for each health check.
+func (h *HealthCheck) TimeoutOrDefault() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultHealthCheckTimeout
+	}
+
+	return h.Timeout
+}
+
+// HealthyThresholdOrDefault returns how many checks must pass before a
+// backend is healthy again.
+func (h *HealthCheck) HealthyThresholdOrDefault() int {
+	if h.HealthyThreshold == 0 {
+		return DefaultHealthyThreshold
+	}
+
+	return h.HealthyThreshold
+}
+
+// UnhealthyThresholdOrDefault returns how many checks must fail before a
+// backend is unhealthy.
+func (h *HealthCheck) UnhealthyThresholdOrDefault() int {
+	if h.UnhealthyThreshold == 0 {
+		return DefaultUnhealthyThreshold
+	}
+
+	return h.UnhealthyThreshold
+}
+
+// StatusRanges returns the HTTP statuses that pass the health check, as
+// ranges that include the start but not the end, the way Envoy wants them.
+func (h *HealthCheck) StatusRanges() [][2]int64 {
+	statuses := h.Statuses
+	if len(statuses) < 1 {
+		statuses = DefaultHealthCheckStatuses
+	}
+
+	var ranges [][2]int64
+	for _, status := range strings.Split(statuses, ",") {
+		// Already validated by parseStatuses
+		parts := strings.SplitN(status, "-", 2)
+		start, _ := strconv.ParseInt(parts[0], 10, 64)
+		end := start
+		if len(parts) > 1 {
+			end, _ = strconv.ParseInt(parts[1], 10, 64)
+		}
+		ranges = append(ranges, [2]int64{start, end + 1})
+	}
+
+	return ranges
+}
+
+// set parses a single health check label. It returns false if the label
+// isn't one of ours.
+func (h *HealthCheck) set(name, value string) (bool, error) {
+	var err error
+
+	switch name {
+	case HealthCheckLabel:
+		h.Type, err = parseOneOf(value, healthCheckTypes)
+	case HealthCheckPathLabel:
+		h.Path, err = parsePath(value)
+	case HealthCheckIntervalLabel:
+		h.Interval, err = parsePositiveDuration(value)
+	case HealthCheckTimeoutLabel:
+		h.Timeout, err = parsePositiveDuration(value)
+	case HealthyThresholdLabel:
+		h.HealthyThreshold, err = parsePositiveInt(value)
+	case UnhealthyThresholdLabel:
+		h.UnhealthyThreshold, err = parsePositiveInt(value)
+	case HealthCheckStatusesLabel:
+		h.Statuses, err = parseStatuses(value)
+	default:
+		return false, nil
+	}
+
+	return true, err
+}
+
+// validate checks that the labels make sense together.
+func (h *HealthCheck) validate() error {
+	if !h.Enabled() {
+		if *h != (HealthCheck{}) {
+			return fmt.Errorf("Invalid health check labels: %s must also be set", HealthCheckLabel)
+		}
+		return nil
+	}
+
+	if h.Type != HealthCheckHTTP && (len(h.Path) > 0 || len(h.Statuses) > 0) {
+		return fmt.Errorf("Invalid health check labels: %s and %s need %s=%s",
+			HealthCheckPathLabel, HealthCheckStatusesLabel, HealthCheckLabel, HealthCheckHTTP,
+		)
+	}
+
+	return nil
+}
+
+func parsePath(value string) (string, error) {
+	if !strings.HasPrefix(value, "/") {
+		return "", fmt.Errorf("expected a path starting with /")
+	}
+
+	return value, nil
+}
+
+// parseStatuses checks each of the comma separated HTTP statuses, which may
+// be ranges like 200-299.
+func parseStatuses(value string) (string, error) {
+	var statuses []string
+	for _, status := range strings.Split(value, ",") {
+		status = strings.TrimSpace(status)
+
+		parts := strings.SplitN(status, "-", 2)
+		start, err := parseStatus(parts[0])
+		end := start
+		if err == nil && len(parts) > 1 {
+			end, err = parseStatus(parts[1])
+		}
+		if err != nil || end < start {
+			return "", fmt.Errorf("expected HTTP statuses or ranges like 200-299,304")
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return strings.Join(statuses, ","), nil
+}
+
+func parseStatus(value string) (int, error) {
+	status, err := strconv.Atoi(value)
+	if err != nil || status < 100 || status > 599 {
+		return 0, fmt.Errorf("invalid status")
+	}
+
+	return status, nil
+}
@@ -0,0 +1,265 @@
+package envoyhttp
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Each container normally gets its own listener on its published port. The
+// server can also have shared listeners that many containers are reached on:
+//
+//  * An HTTP ingress on the IngressAddr, which routes by the Host header to
+//    the containers that claim the domain with their VirtualHosts label.
+//  * A TLS passthrough on the SNIAddr, which routes TLS connections by their
+//    SNI to the TCP mode containers that claim the server name with their
+//    ServerNames label, without terminating TLS.
+
+const (
+	// VirtualHostsLabel lists the domains a container serves on the ingress
+	VirtualHostsLabel = "VirtualHosts"
+
+	// ServerNamesLabel lists the TLS server names a container serves on the
+	// TLS passthrough
+	ServerNamesLabel = "ServerNames"
+
+	// IngressName is the name of the shared ingress listener
+	IngressName = "ingress"
+
+	// SNIName is the name of the shared TLS passthrough listener
+	SNIName = "sni-passthrough"
+)
+
+var (
+	// A domain, maybe with a leading wildcard and a port
+	domainRegexp = regexp.MustCompile(`^(\*\.)?[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?$`)
+
+	// The same, but there are no ports in SNI
+	serverNameRegexp = regexp.MustCompile(`^(\*\.)?[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+)
+
+// An Ingress is a shared listener and the virtual hosts on it.
+type Ingress struct {
+	Entry *Entry         // Describes the shared listener
+	Hosts []*IngressHost // Sorted by name
+}
+
+// An IngressHost routes the domains, or server names, to the cluster for an
+// entry, or for a ServiceGroup.
+type IngressHost struct {
+	Entry   *Entry
+	Domains []string
+}
+
+// VirtualHosts returns the domains the entry serves on the ingress. They
+// were checked when it was registered.
+func (e *Entry) VirtualHosts() []string {
+	domains, _ := ParseVirtualHosts(e.Labels[VirtualHostsLabel])
+	return domains
+}
+
+// ServerNames returns the server names the entry serves on the TLS
+// passthrough. They were checked when it was registered.
+func (e *Entry) ServerNames() []string {
+	names, _ := ParseServerNames(e.Labels[ServerNamesLabel])
+	return names
+}
+
+// ParseVirtualHosts checks each of the comma separated domains in the
+// VirtualHosts label.
+func ParseVirtualHosts(value string) ([]string, error) {
+	return parseDomains(VirtualHostsLabel, value, domainRegexp)
+}
+
+// ParseServerNames checks each of the comma separated server names in the
+// ServerNames label.
+func ParseServerNames(value string) ([]string, error) {
+	return parseDomains(ServerNamesLabel, value, serverNameRegexp)
+}
+
+func parseDomains(label, value string, valid *regexp.Regexp) ([]string, error) {
+	var domains []string
+	for _, domain := range strings.Split(value, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if len(domain) < 1 {
+			continue
+		}
+
+		if !valid.MatchString(domain) {
+			return nil, fmt.Errorf("Invalid label %s: '%s' is not a domain", label, domain)
+		}
+
+		domains = append(domains, domain)
+	}
+
+	return domains, nil
+}
+
+// Ingress returns the shared ingress listener and the virtual hosts on it,
+// or nil if there isn't one.
+func (r *Registrar) Ingress() *Ingress {
+	if r.IngressAddr == nil {
+		return nil
+	}
+
+	return r.sharedListener(ingressEntry(IngressName, "http", r.IngressAddr), func(entry *Entry) []string {
+		if entry.ProxyMode != "http" {
+			return nil
+		}
+		return entry.VirtualHosts()
+	})
+}
+
+// SNIPassthrough returns the shared TLS passthrough listener and the server
+// names on it, or nil if there isn't one.
+func (r *Registrar) SNIPassthrough() *Ingress {
+	if r.SNIAddr == nil {
+		return nil
+	}
+
+	return r.sharedListener(ingressEntry(SNIName, "tcp", r.SNIAddr), func(entry *Entry) []string {
+		// The container must do TLS itself
+		if entry.ProxyMode != "tcp" || entry.Tuning.HasTLS() {
+			return nil
+		}
+		return entry.ServerNames()
+	})
+}
+
+// Overlaps tells us if Envoy would be unable to listen on both addresses.
+func Overlaps(a, b *net.TCPAddr) bool {
+	if a == nil || b == nil || a.Port != b.Port {
+		return false
+	}
+
+	return a.IP.Equal(b.IP) || a.IP.IsUnspecified() || b.IP.IsUnspecified()
+}
+
+// sharedNameFor returns the name of the shared listener that a TCP listener
+// on the address would get in the way of, or an empty string.
+func (r *Registrar) sharedNameFor(addr *net.TCPAddr) string {
+	switch {
+	case Overlaps(addr, r.IngressAddr):
+		return IngressName
+	case Overlaps(addr, r.SNIAddr):
+		return SNIName
+	}
+
+	return ""
+}
+
+// checkShared returns an error if the entry, or the listener for its
+// ServicePort, would be where a shared listener is. The shared listeners
+// serve lots of containers, so we don't let one container take them away.
+func (r *Registrar) checkShared(entry *Entry) error {
+	if entry.IsUDP() {
+		return nil
+	}
+
+	if name := r.sharedNameFor(entry.FrontendAddr); len(name) > 0 {
+		return fmt.Errorf("Listener %s is reserved for the %s", ListenerKey(entry), name)
+	}
+
+	if entry.ServicePort == 0 {
+		return nil
+	}
+
+	servicePort := &net.TCPAddr{IP: entry.FrontendAddr.IP, Port: entry.ServicePort}
+	if name := r.sharedNameFor(servicePort); len(name) > 0 {
+		return fmt.Errorf("Invalid label ServicePort: %d is reserved for the %s", entry.ServicePort, name)
+	}
+
+	return nil
+}
+
+// SharedConflicts returns an error for each entry that is in the way of a
+// shared listener. Registrations that would be are refused, so these can
+// only have been restored from before the shared listeners were set up.
+func (r *Registrar) SharedConflicts() []error {
+	var conflicts []error
+	r.EachEntry(func(name string, entry *Entry) error {
+		if err := r.checkShared(entry); err != nil {
+			conflicts = append(conflicts, fmt.Errorf("%s: %s", name, err))
+		}
+		return nil
+	})
+
+	return conflicts
+}
+
+// sharedListener returns the shared listener, with a host for each entry
+// that has domains on it. Replicas that share a ServicePort share a host,
+// taking its domains from the first replica. Each domain goes to the first
+// entry by name that claims it, since Envoy can't route one domain to two
+// places. The listener isn't served if an entry is in its way, which can
+// only happen if the entry was restored, see SharedConflicts.
+func (r *Registrar) sharedListener(shared *Entry, domainsFor func(entry *Entry) []string) *Ingress {
+	var conflict *Entry
+	r.EachEntry(func(name string, entry *Entry) error {
+		if !entry.IsUDP() && Overlaps(entry.FrontendAddr, shared.FrontendAddr) {
+			conflict = entry
+		}
+		return nil
+	})
+
+	if conflict != nil {
+		log.Errorf("Not serving the %s, %s is registered as %s for %s",
+			shared.Name, ListenerKey(conflict), conflict.Name, conflict.ContainerString(),
+		)
+		return nil
+	}
+
+	var candidates []*IngressHost
+	r.EachEntry(func(name string, entry *Entry) error {
+		if entry.ServicePort == 0 && !entry.IsUDP() {
+			candidates = append(candidates, &IngressHost{Entry: entry, Domains: domainsFor(entry)})
+		}
+		return nil
+	})
+
+	for _, group := range r.ServiceGroups() {
+		if !group.Entry.IsUDP() {
+			candidates = append(candidates, &IngressHost{Entry: group.Entry, Domains: domainsFor(group.Members[0])})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Entry.Name < candidates[j].Entry.Name
+	})
+
+	ingress := &Ingress{Entry: shared}
+	claimed := make(map[string]string)
+	for _, host := range candidates {
+		var domains []string
+		for _, domain := range host.Domains {
+			if owner, ok := claimed[domain]; ok {
+				log.Warnf("Not routing %s to %s on the %s, it already goes to %s", domain, host.Entry.Name, shared.Name, owner)
+				continue
+			}
+			claimed[domain] = host.Entry.Name
+			domains = append(domains, domain)
+		}
+
+		if len(domains) > 0 {
+			host.Domains = domains
+			ingress.Hosts = append(ingress.Hosts, host)
+		}
+	}
+
+	return ingress
+}
+
+// ingressEntry describes a shared listener.
+func ingressEntry(name, proxyMode string, addr *net.TCPAddr) *Entry {
+	return &Entry{
+		Name:         name,
+		FrontendAddr: addr,
+		ServiceName:  name,
+		ProxyMode:    proxyMode,
+		DualStack:    isWildcardV6(addr.IP), // Like MergeDualStack
+	}
+}
@@ -0,0 +1,220 @@
+package envoyhttp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Ingress(t *testing.T) {
+	Convey("Ingress()", t, func() {
+		registrar := NewRegistrar()
+		registrar.IngressAddr = &net.TCPAddr{IP: net.IPv4zero, Port: 80}
+
+		service := func(name string, frontendPort int32, virtualHosts string) *shimrpc.RegistrarRequest {
+			return &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    frontendPort,
+				BackendAddr:     "172.16.10.20",
+				BackendPort:     int32(frontendPort),
+				EnvironmentName: "dev",
+				ServiceName:     name,
+				ProxyMode:       "http",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				Labels:          map[string]string{VirtualHostsLabel: virtualHosts},
+			}
+		}
+
+		registrar.Register(context.Background(), service("bede", 32000, "bede.example.com, *.bede.example.com"))
+		registrar.Register(context.Background(), service("chretien", 32001, "Chretien.example.com:8080"))
+		registrar.Register(context.Background(), req1)
+
+		Convey("routes each service's domains to its cluster", func() {
+			ingress := registrar.Ingress()
+
+			So(ingress.Entry.Name, ShouldEqual, "ingress")
+			So(ingress.Entry.FrontendAddr.String(), ShouldEqual, "0.0.0.0:80")
+			So(ingress.Entry.ProxyMode, ShouldEqual, "http")
+			So(len(ingress.Hosts), ShouldEqual, 2)
+			So(ingress.Hosts[0].Entry.Name, ShouldEqual, "bede-dev-32000")
+			So(ingress.Hosts[0].Domains, ShouldResemble, []string{"bede.example.com", "*.bede.example.com"})
+			So(ingress.Hosts[1].Entry.Name, ShouldEqual, "chretien-dev-32001")
+			So(ingress.Hosts[1].Domains, ShouldResemble, []string{"chretien.example.com:8080"})
+		})
+
+		Convey("isn't there unless it has an address", func() {
+			registrar.IngressAddr = nil
+			So(registrar.Ingress(), ShouldBeNil)
+		})
+
+		Convey("refuses containers that publish its port", func() {
+			_, err := registrar.Register(context.Background(), service("hakluyt", 80, ""))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "reserved for the ingress")
+			So(registrar.Ingress(), ShouldNotBeNil)
+		})
+
+		Convey("refuses containers with its port as their ServicePort", func() {
+			req := service("hakluyt", 32002, "")
+			req.ServicePort = 80
+			_, err := registrar.Register(context.Background(), req)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "ServicePort")
+		})
+
+		Convey("isn't there when a restored entry has its port", func() {
+			entry, _ := RequestToEntry(service("hakluyt", 80, ""))
+			registrar.AddEntry(entry)
+
+			So(registrar.Ingress(), ShouldBeNil)
+			So(len(registrar.SharedConflicts()), ShouldEqual, 1)
+		})
+
+		Convey("wins over a restored group on its port", func() {
+			entry, _ := RequestToEntry(service("hakluyt", 32002, ""))
+			entry.ServicePort = 80
+			registrar.AddEntry(entry)
+
+			So(registrar.ServiceGroups(), ShouldBeEmpty)
+			So(registrar.Ingress(), ShouldNotBeNil)
+			So(len(registrar.SharedConflicts()), ShouldEqual, 1)
+		})
+
+		Convey("leaves out TCP services", func() {
+			req := service("hakluyt", 32002, "hakluyt.example.com")
+			req.ProxyMode = "tcp"
+			registrar.Register(context.Background(), req)
+
+			So(len(registrar.Ingress().Hosts), ShouldEqual, 2)
+		})
+
+		Convey("routes a domain to the first service that claims it", func() {
+			registrar.Register(context.Background(), service("hakluyt", 32002, "hakluyt.example.com,bede.example.com"))
+			ingress := registrar.Ingress()
+
+			So(len(ingress.Hosts), ShouldEqual, 3)
+			So(ingress.Hosts[2].Entry.Name, ShouldEqual, "hakluyt-dev-32002")
+			So(ingress.Hosts[2].Domains, ShouldResemble, []string{"hakluyt.example.com"})
+
+			Convey("and leaves out services without any left", func() {
+				registrar.Register(context.Background(), service("mandeville", 32003, "bede.example.com"))
+				So(len(registrar.Ingress().Hosts), ShouldEqual, 3)
+			})
+		})
+
+		Convey("routes replicas that share a ServicePort to their group", func() {
+			for i, port := range []int32{32010, 32011} {
+				req := service("polo", port, "polo.example.com")
+				req.BackendAddr = []string{"172.16.10.10", "172.16.10.11"}[i]
+				req.ServicePort = 8080
+				registrar.Register(context.Background(), req)
+			}
+			ingress := registrar.Ingress()
+
+			So(len(ingress.Hosts), ShouldEqual, 3)
+			So(ingress.Hosts[2].Entry.Name, ShouldEqual, "polo-dev-service-8080")
+			So(ingress.Hosts[2].Domains, ShouldResemble, []string{"polo.example.com"})
+		})
+
+		Convey("rejects registrations with invalid virtual hosts", func() {
+			_, err := registrar.Register(context.Background(), service("hakluyt", 32002, "hakluyt.example.com,not a domain"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "VirtualHosts")
+			So(registrar.GetEntry("hakluyt-dev-32002"), ShouldBeNil)
+		})
+
+		Convey("is served over the v1 API", func() {
+			api := NewEnvoyApi(registrar)
+
+			var ingress *EnvoyListener
+			for _, listener := range api.EnvoyListenersFromRegistrar() {
+				if listener.Name == "ingress" {
+					ingress = listener
+				}
+			}
+
+			So(ingress, ShouldNotBeNil)
+			So(ingress.Address, ShouldEqual, "tcp://0.0.0.0:80")
+			hosts := ingress.Filters[0].Config.RouteConfig.VirtualHosts
+			So(len(hosts), ShouldEqual, 2)
+			So(hosts[0].Domains, ShouldResemble, []string{"bede.example.com", "*.bede.example.com"})
+			So(hosts[0].Routes[0].Cluster, ShouldEqual, "bede-dev-32000")
+		})
+	})
+}
+
+func Test_SNIPassthrough(t *testing.T) {
+	Convey("SNIPassthrough()", t, func() {
+		registrar := NewRegistrar()
+		registrar.SNIAddr = &net.TCPAddr{IP: net.IPv4zero, Port: 443}
+
+		service := func(name string, frontendPort int32, serverNames string) *shimrpc.RegistrarRequest {
+			return &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    frontendPort,
+				BackendAddr:     "172.16.10.20",
+				BackendPort:     443,
+				EnvironmentName: "dev",
+				ServiceName:     name,
+				ProxyMode:       "tcp",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				Labels:          map[string]string{ServerNamesLabel: serverNames},
+			}
+		}
+
+		registrar.Register(context.Background(), service("bede", 32443, "bede.example.com,*.bede.example.com"))
+		registrar.Register(context.Background(), service("chretien", 32444, "chretien.example.com, bede.example.com"))
+		registrar.Register(context.Background(), req2)
+
+		Convey("routes each service's server names to its cluster", func() {
+			sni := registrar.SNIPassthrough()
+
+			So(sni.Entry.Name, ShouldEqual, "sni-passthrough")
+			So(sni.Entry.FrontendAddr.String(), ShouldEqual, "0.0.0.0:443")
+			So(len(sni.Hosts), ShouldEqual, 2)
+			So(sni.Hosts[0].Entry.Name, ShouldEqual, "bede-dev-32443")
+			So(sni.Hosts[0].Domains, ShouldResemble, []string{"bede.example.com", "*.bede.example.com"})
+			So(sni.Hosts[1].Entry.Name, ShouldEqual, "chretien-dev-32444")
+			So(sni.Hosts[1].Domains, ShouldResemble, []string{"chretien.example.com"})
+		})
+
+		Convey("isn't there unless it has an address", func() {
+			registrar.SNIAddr = nil
+			So(registrar.SNIPassthrough(), ShouldBeNil)
+		})
+
+		Convey("leaves out services that don't do TLS themselves", func() {
+			req := service("hakluyt", 32445, "hakluyt.example.com")
+			req.ProxyMode = "http"
+			registrar.Register(context.Background(), req)
+
+			req = service("mandeville", 32446, "mandeville.example.com")
+			req.Tuning = map[string]string{"EnvoyTLSSecret": "mandeville"}
+			registrar.Register(context.Background(), req)
+
+			So(len(registrar.SNIPassthrough().Hosts), ShouldEqual, 2)
+		})
+
+		Convey("refuses containers that publish its port", func() {
+			_, err := registrar.Register(context.Background(), service("hakluyt", 443, ""))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "reserved for the sni-passthrough")
+		})
+
+		Convey("rejects registrations with invalid server names", func() {
+			_, err := registrar.Register(context.Background(), service("hakluyt", 32445, "hakluyt.example.com:443"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "ServerNames")
+		})
+
+		Convey("isn't served over the v1 API", func() {
+			api := NewEnvoyApi(registrar)
+			for _, listener := range api.EnvoyListenersFromRegistrar() {
+				So(listener.Name, ShouldNotEqual, "sni-passthrough")
+			}
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package envoyhttp
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultLeaseTTL is how long a lease lasts without a heartbeat
+	DefaultLeaseTTL = 15 * time.Second
+	// DefaultLeaseGrace is how long we hold an entry after its lease
+	// stream breaks, giving the shim a chance to reconnect.
+	DefaultLeaseGrace = 30 * time.Second
+)
+
+// Lease is a GRPC callback that handles a lease stream from a shim. The
+// first request registers the entry and every request after that renews
+// the lease. If the stream breaks, the entry is held for the grace period
+// and then removed unless the shim has come back.
+func (r *Registrar) Lease(stream shimrpc.Registrar_LeaseServer) error {
+	var name string
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if len(name) > 0 {
+				log.Infof("Lease stream for %s closed, holding it for %s", name, r.LeaseGrace)
+				r.renewLease(name, r.LeaseGrace)
+			}
+
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		entry, err := RequestToEntry(req)
+		if err != nil {
+			return err
+		}
+
+		if req.Action == shimrpc.RegistrarRequest_DEREGISTER {
+			if len(name) > 0 {
+				r.DeregisterEntry(entry)
+			}
+			return nil
+		}
+
+		// Only touches the entries on the first request or if something
+		// changed. Heartbeats shouldn't cause a new snapshot.
+		name, err = r.RegisterEntry(entry)
+		if err != nil {
+			return err
+		}
+		r.renewLease(name, r.LeaseTTL)
+
+		err = stream.Send(&shimrpc.LeaseReply{
+			StatusCode: 1,
+			TtlSeconds: int32(r.LeaseTTL / time.Second),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// renewLease sets the named lease to expire after the duration passed in.
+func (r *Registrar) renewLease(name string, ttl time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+
+	// The entry may have been removed while we weren't looking. If it's
+	// draining, it goes when the drain is over, whatever the shim does.
+	key, ok := r.services[name]
+	if !ok || r.entries[key].IsDraining() {
+		return
+	}
+
+	r.leases[name] = time.Now().Add(ttl)
+}
+
+// ExpireLeases removes all the entries whose leases expired before the
+// time passed in. Entries registered without a lease never expire.
+func (r *Registrar) ExpireLeases(now time.Time) {
+	var expired []string
+
+	r.RLock()
+	for name, expiry := range r.leases {
+		if now.After(expiry) {
+			expired = append(expired, name)
+		}
+	}
+	r.RUnlock()
+
+	for _, name := range expired {
+		// Unless it was renewed in the meantime
+		removed := r.RemoveEntryIf(name, func(entry *Entry) bool {
+			expiry, ok := r.leases[name]
+			return ok && now.After(expiry)
+		})
+		if removed {
+			log.Warnf("Lease for %s expired", name)
+		}
+	}
+}
+
+// RunLeaseExpiry expires leases, and drained entries, every interval until
+// the context is cancelled.
+func (r *Registrar) RunLeaseExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			r.ExpireLeases(now)
+			r.ExpireDrains(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
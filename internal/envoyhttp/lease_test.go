@@ -0,0 +1,154 @@
+package envoyhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+)
+
+// mockLeaseStream plays back a list of requests and then returns the
+// final error from Recv().
+type mockLeaseStream struct {
+	grpc.ServerStream
+	requests []*shimrpc.RegistrarRequest
+	err      error
+	replies  []*shimrpc.LeaseReply
+	closing  func() // Called before the stream breaks
+}
+
+func (s *mockLeaseStream) Recv() (*shimrpc.RegistrarRequest, error) {
+	if len(s.requests) < 1 {
+		if s.closing != nil {
+			s.closing()
+		}
+		return nil, s.err
+	}
+
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *mockLeaseStream) Send(reply *shimrpc.LeaseReply) error {
+	s.replies = append(s.replies, reply)
+	return nil
+}
+
+func Test_Lease(t *testing.T) {
+	Convey("Lease()", t, func() {
+		registrar := NewRegistrar()
+		registrar.LeaseTTL = 10 * time.Second
+		registrar.LeaseGrace = 20 * time.Second
+
+		Convey("registers the entry and replies with the TTL", func() {
+			stream := &mockLeaseStream{requests: []*shimrpc.RegistrarRequest{req1}, err: io.EOF}
+
+			So(registrar.Lease(stream), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+			So(len(stream.replies), ShouldEqual, 1)
+			So(stream.replies[0].StatusCode, ShouldEqual, 1)
+			So(stream.replies[0].TtlSeconds, ShouldEqual, 10)
+		})
+
+		Convey("only notifies listeners once for repeated heartbeats", func() {
+			listener := registrar.Listen()
+			stream := &mockLeaseStream{
+				requests: []*shimrpc.RegistrarRequest{req1, req1, req1},
+				err:      io.EOF,
+			}
+
+			So(registrar.Lease(stream), ShouldBeNil)
+			So(len(stream.replies), ShouldEqual, 3)
+
+			<-listener
+			select {
+			case <-listener:
+				So("second notification", ShouldBeEmpty)
+			default:
+			}
+		})
+
+		Convey("holds the entry for the grace period when the stream breaks", func() {
+			stream := &mockLeaseStream{
+				requests: []*shimrpc.RegistrarRequest{req1},
+				err:      errors.New("intentional broken stream"),
+			}
+
+			So(registrar.Lease(stream), ShouldNotBeNil)
+
+			registrar.ExpireLeases(time.Now().Add(15 * time.Second))
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+
+			registrar.ExpireLeases(time.Now().Add(25 * time.Second))
+			So(registrar.GetEntry("bede-dev-12345"), ShouldBeNil)
+		})
+
+		Convey("removes the entry on deregister", func() {
+			deregister := *req1
+			deregister.Action = shimrpc.RegistrarRequest_DEREGISTER
+			stream := &mockLeaseStream{
+				requests: []*shimrpc.RegistrarRequest{req1, &deregister},
+				err:      io.EOF,
+			}
+
+			So(registrar.Lease(stream), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldBeNil)
+		})
+
+		Convey("leaves a deregistered entry to drain when the stream closes", func() {
+			registrar.DrainTime = time.Minute
+			deregister := *req1
+			deregister.Action = shimrpc.RegistrarRequest_DEREGISTER
+			stream := &mockLeaseStream{
+				requests: []*shimrpc.RegistrarRequest{req1},
+				err:      io.EOF,
+				closing: func() {
+					registrar.Register(context.Background(), &deregister)
+				},
+			}
+
+			So(registrar.Lease(stream), ShouldBeNil)
+			So(registrar.GetEntry("bede-dev-12345").IsDraining(), ShouldBeTrue)
+			So(registrar.leases, ShouldBeEmpty)
+
+			registrar.ExpireLeases(time.Now().Add(25 * time.Second))
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+		})
+	})
+}
+
+func Test_ExpireLeases(t *testing.T) {
+	Convey("ExpireLeases()", t, func() {
+		registrar := NewRegistrar()
+		entry, _ := RequestToEntry(req1)
+		registrar.AddEntry(entry)
+
+		Convey("never expires entries registered without a lease", func() {
+			registrar.ExpireLeases(time.Now().Add(24 * time.Hour))
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+		})
+
+		Convey("expires entries whose lease has run out", func() {
+			registrar.renewLease("bede-dev-12345", 1*time.Second)
+
+			registrar.ExpireLeases(time.Now())
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+
+			registrar.ExpireLeases(time.Now().Add(2 * time.Second))
+			So(registrar.GetEntry("bede-dev-12345"), ShouldBeNil)
+		})
+
+		Convey("drops the lease when the entry is removed", func() {
+			registrar.renewLease("bede-dev-12345", 1*time.Second)
+			registrar.RemoveEntry("bede-dev-12345")
+
+			So(registrar.leases, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,143 @@
+package envoyhttp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Right after a container starts, the process inside may not be listening
+// yet. So we probe the backend of each new entry and only publish its
+// endpoint once it answers. That's an HTTP GET of the health check path for
+// HTTP entries with an HTTP health check, and a TCP connect otherwise. UDP
+// backends can't be probed and are published straight away.
+
+const (
+	// DefaultReadinessInterval is how long we wait between probes
+	DefaultReadinessInterval = 250 * time.Millisecond
+	// ProbeTimeout is how long we wait on any one probe
+	ProbeTimeout = 1 * time.Second
+)
+
+// IsReady tells us if the entry's backend has answered, so Envoy can send
+// traffic to it.
+func (e *Entry) IsReady() bool {
+	return !e.Probing
+}
+
+// shouldProbe tells us if we need to wait for a new entry's backend.
+func (r *Registrar) shouldProbe(entry *Entry) bool {
+	return r.ReadinessTimeout > 0 && !entry.IsUDP()
+}
+
+// waitForBackend probes the entry's backend until it answers, and then
+// publishes the entry. If it hasn't answered by the ReadinessTimeout we
+// publish it anyway, unless the Registrar says not to. It gives up when the
+// entry is replaced or removed.
+func (r *Registrar) waitForBackend(entry *Entry) {
+	key := ListenerKey(entry)
+	deadline := time.Now().Add(r.ReadinessTimeout)
+	warned := false
+
+	for r.isProbing(key, entry) {
+		err := r.probe(entry)
+		if err == nil {
+			log.Infof("Backend %s for %s is ready", entry.BackendAddr, entry.Name)
+			r.markReady(key, entry)
+			return
+		}
+
+		if !warned && time.Now().After(deadline) {
+			if r.ReadinessPublishAnyway {
+				log.Warnf("Backend %s for %s isn't ready after %s, publishing anyway: %s",
+					entry.BackendAddr, entry.Name, r.ReadinessTimeout, err)
+				r.markReady(key, entry)
+				return
+			}
+
+			log.Errorf("Backend %s for %s isn't ready after %s, still waiting: %s",
+				entry.BackendAddr, entry.Name, r.ReadinessTimeout, err)
+			warned = true
+		}
+
+		time.Sleep(r.ReadinessInterval)
+	}
+}
+
+// isProbing tells us if the entry on the listener is still the one we're
+// probing, and hasn't been published yet.
+func (r *Registrar) isProbing(key string, entry *Entry) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	existing, ok := r.entries[key]
+	return ok && existing.Probing && sameBackend(existing, entry)
+}
+
+// markReady publishes the entry on the listener, if it's still the one we
+// were probing.
+func (r *Registrar) markReady(key string, entry *Entry) {
+	r.Lock()
+	defer r.Unlock()
+
+	existing, ok := r.entries[key]
+	if !ok || !existing.Probing || !sameBackend(existing, entry) {
+		return
+	}
+
+	// Entries are shared with whoever looked them up, so we replace it
+	ready := *existing
+	ready.Probing = false
+	r.entries[key] = &ready
+
+	r.notifyListeners()
+}
+
+// sameBackend tells us if two entries point at the same container backend.
+func sameBackend(a, b *Entry) bool {
+	return a.BackendAddr.String() == b.BackendAddr.String() && a.ContainerID == b.ContainerID
+}
+
+// ProbeBackend checks once whether the entry's backend is answering.
+func ProbeBackend(entry *Entry) error {
+	hc := &entry.Tuning.HealthCheck
+	if entry.ProxyMode == "http" && hc.Type == HealthCheckHTTP {
+		return probeHTTP(entry.BackendAddr, hc)
+	}
+
+	conn, err := net.DialTimeout("tcp", entry.BackendAddr.String(), ProbeTimeout)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// probeHTTP makes sure the backend passes its HTTP health check.
+func probeHTTP(addr *net.TCPAddr, hc *HealthCheck) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", "http://"+addr.String()+hc.PathOrDefault(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	for _, statuses := range hc.StatusRanges() {
+		if int64(resp.StatusCode) >= statuses[0] && int64(resp.StatusCode) < statuses[1] {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("health check returned %s", resp.Status)
+}
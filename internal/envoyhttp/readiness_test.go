@@ -0,0 +1,141 @@
+package envoyhttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ProbeBackend(t *testing.T) {
+	Convey("ProbeBackend()", t, func() {
+		Convey("connects to TCP backends", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			So(err, ShouldBeNil)
+
+			entry := &Entry{BackendAddr: listener.Addr().(*net.TCPAddr), ProxyMode: "tcp"}
+			So(ProbeBackend(entry), ShouldBeNil)
+
+			listener.Close()
+			So(ProbeBackend(entry), ShouldNotBeNil)
+		})
+
+		Convey("runs the health check on HTTP backends", func() {
+			status := http.StatusServiceUnavailable
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/status" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			entry := &Entry{
+				BackendAddr: server.Listener.Addr().(*net.TCPAddr),
+				ProxyMode:   "http",
+				Tuning:      Tuning{HealthCheck: HealthCheck{Type: HealthCheckHTTP, Path: "/status"}},
+			}
+			So(ProbeBackend(entry), ShouldNotBeNil)
+
+			status = http.StatusOK
+			So(ProbeBackend(entry), ShouldBeNil)
+		})
+	})
+}
+
+func Test_waitForBackend(t *testing.T) {
+	Convey("Waiting for backends", t, func() {
+		var answering int32
+		registrar := NewRegistrar()
+		registrar.ReadinessTimeout = 1 * time.Minute
+		registrar.ReadinessInterval = 1 * time.Millisecond
+		registrar.probe = func(entry *Entry) error {
+			if atomic.LoadInt32(&answering) == 0 {
+				return errors.New("intentional probe error")
+			}
+			return nil
+		}
+
+		api := NewEnvoyApi(registrar)
+		registration := func() string {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/registration/chretien-dev-23451", nil)
+			api.registrationHandler(recorder, req, map[string]string{"service": "chretien-dev-23451"})
+			_, _, body := getResult(recorder)
+			return body
+		}
+
+		ready := func() bool {
+			return registrar.GetEntry("chretien-dev-23451").IsReady()
+		}
+
+		Convey("doesn't publish new backends until they answer", func() {
+			registrar.Register(context.Background(), req2)
+
+			So(ready(), ShouldBeFalse)
+			So(registration(), ShouldContainSubstring, `"hosts":[]`)
+
+			atomic.StoreInt32(&answering, 1)
+			So(eventually(ready), ShouldBeTrue)
+			So(registration(), ShouldContainSubstring, "172.16.10.2")
+		})
+
+		Convey("doesn't probe the same backend again", func() {
+			registrar.Register(context.Background(), req2)
+
+			atomic.StoreInt32(&answering, 1)
+			So(eventually(ready), ShouldBeTrue)
+			atomic.StoreInt32(&answering, 0)
+
+			changed := *req2
+			changed.Labels = map[string]string{"version": "2"}
+			registrar.Register(context.Background(), &changed)
+
+			So(ready(), ShouldBeTrue)
+		})
+
+		Convey("publishes backends that don't answer after the timeout", func() {
+			registrar.ReadinessTimeout = 10 * time.Millisecond
+			registrar.Register(context.Background(), req3)
+
+			So(eventually(func() bool { return registrar.GetEntry("hakluyt-dev-23555").IsReady() }), ShouldBeTrue)
+		})
+
+		Convey("keeps waiting after the timeout when asked to", func() {
+			registrar.ReadinessTimeout = 1 * time.Millisecond
+			registrar.ReadinessPublishAnyway = false
+			registrar.Register(context.Background(), req3)
+
+			time.Sleep(20 * time.Millisecond)
+			So(registrar.GetEntry("hakluyt-dev-23555").IsReady(), ShouldBeFalse)
+
+			atomic.StoreInt32(&answering, 1)
+			So(eventually(func() bool { return registrar.GetEntry("hakluyt-dev-23555").IsReady() }), ShouldBeTrue)
+		})
+
+		Reset(func() {
+			// Stops the probes
+			registrar.RemoveEntry("chretien-dev-23451")
+			registrar.RemoveEntry("hakluyt-dev-23555")
+		})
+	})
+}
+
+// eventually waits a little while for the condition to become true
+func eventually(condition func() bool) bool {
+	for i := 0; i < 200; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
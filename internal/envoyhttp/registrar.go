@@ -5,35 +5,200 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// Protocols for the Protocol field on an Entry
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
 type Entry struct {
-	FrontendAddr    *net.TCPAddr
+	Name            string       // What the Registrar serves it as, see AddEntry
+	FrontendAddr    *net.TCPAddr // Also used for UDP, see Protocol
 	BackendAddr     *net.TCPAddr
 	ServiceName     string
 	EnvironmentName string
 	ProxyMode       string
+	Protocol        string // Empty means TCP, for older shims and journals
+	ContainerID     string // Empty for older shims, until we learn it from Docker
+	ContainerName   string
+	Image           string
+	Labels          map[string]string
+	DualStack       bool   // Listening on :: for both IPv4 and IPv6, see MergeDualStack
+	Tuning          Tuning // From the container's Envoy* labels
+	ServicePort     int    // Shared with the other replicas, see ServiceGroups. 0 is none.
+	DockerHealth    string `json:"-"` // From the container's HEALTHCHECK, see SetContainerHealth
+	Probing         bool   `json:"-"` // Waiting for the backend to answer, see ProbeBackend
+	Draining        bool   `json:"-"` // On its way out, see DrainEntry
+}
+
+// IsUDP tells us if Envoy should proxy UDP for this entry.
+func (e *Entry) IsUDP() bool {
+	return e.Protocol == ProtocolUDP
+}
+
+// HasContainer tells us if we know which container the entry belongs to.
+func (e *Entry) HasContainer() bool {
+	return len(e.ContainerID) > 0
+}
+
+// ContainerString describes the container the entry belongs to, for logging.
+func (e *Entry) ContainerString() string {
+	if !e.HasContainer() {
+		return "unknown container"
+	}
+
+	if len(e.ContainerName) < 1 {
+		return "container " + e.ContainerID
+	}
+
+	return fmt.Sprintf("container %s (%s)", e.ContainerName, e.ContainerID)
+}
+
+// ClusterName returns the name Envoy knows the entry by. That's the name the
+// Registrar gave it, or its SvcName if it isn't in one.
+func (e *Entry) ClusterName() string {
+	if len(e.Name) < 1 {
+		return SvcName(e)
+	}
+
+	return e.Name
+}
+
+// SameAs compares the fields of the entry that come from the shim.
+func (e *Entry) SameAs(other *Entry) bool {
+	return e.FrontendAddr.String() == other.FrontendAddr.String() &&
+		e.BackendAddr.String() == other.BackendAddr.String() &&
+		e.ServiceName == other.ServiceName &&
+		e.EnvironmentName == other.EnvironmentName &&
+		e.ProxyMode == other.ProxyMode &&
+		e.IsUDP() == other.IsUDP() &&
+		e.DualStack == other.DualStack &&
+		e.Tuning == other.Tuning &&
+		e.ServicePort == other.ServicePort &&
+		e.ContainerID == other.ContainerID &&
+		e.ContainerName == other.ContainerName &&
+		e.Image == other.Image &&
+		sameLabels(e.Labels, other.Labels)
+}
+
+func sameLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+
+	return true
 }
 
 type Registrar struct {
 	sync.RWMutex
-	entries map[string]*Entry
+	entries   map[string]*Entry    // By ListenerKey
+	services  map[string]string    // ListenerKeys by entry name
+	leases    map[string]time.Time // Expiry times for leased entries, by name
+	drains    map[string]time.Time // When draining entries are removed, by name
+	listeners []chan struct{}
+	store     Store
+
+	containerHealth map[string]string // Docker health by container ID
+
+	LeaseTTL   time.Duration
+	LeaseGrace time.Duration
+	DrainTime  time.Duration // How long Envoy drains entries that go away
+
+	// How long to wait for new backends to answer before publishing them.
+	// Zero publishes them straight away.
+	ReadinessTimeout       time.Duration
+	ReadinessInterval      time.Duration
+	ReadinessPublishAnyway bool // Or wait for as long as it takes
+	probe                  func(entry *Entry) error
+
+	UDPStats *UDPStatsCollector // Stats reported by in-process UDP proxies
+
+	IngressAddr *net.TCPAddr // Where the shared ingress listens, nil for nowhere
+	SNIAddr     *net.TCPAddr // Where the shared TLS passthrough listens, likewise
 }
 
 func NewRegistrar() *Registrar {
 	return &Registrar{
-		entries: make(map[string]*Entry),
+		entries:         make(map[string]*Entry),
+		services:        make(map[string]string),
+		leases:          make(map[string]time.Time),
+		drains:          make(map[string]time.Time),
+		containerHealth: make(map[string]string),
+		LeaseTTL:        DefaultLeaseTTL,
+		LeaseGrace:      DefaultLeaseGrace,
+		UDPStats:        NewUDPStatsCollector(),
+
+		ReadinessInterval:      DefaultReadinessInterval,
+		ReadinessPublishAnyway: true,
+		probe:                  ProbeBackend,
+	}
+}
+
+// NewPersistentRegistrar returns a Registrar that writes every change
+// through to the Store, after restoring any entries already in it.
+func NewPersistentRegistrar(store Store) (*Registrar, error) {
+	entries, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Restored %d entries from the store", len(entries))
+
+	registrar := NewRegistrar()
+	for name, entry := range entries {
+		// Journals from before entries had names are keyed by them
+		entry.Name = name
+		registrar.entries[ListenerKey(entry)] = entry
+		registrar.services[name] = ListenerKey(entry)
+	}
+	registrar.store = store
+
+	return registrar, nil
+}
+
+// Listen returns a channel that receives a notification each time the
+// set of entries changes. Notifications are coalesced: a slow reader will
+// see a single pending notification rather than one per change.
+func (r *Registrar) Listen() <-chan struct{} {
+	r.Lock()
+	defer r.Unlock()
+
+	listener := make(chan struct{}, 1)
+	r.listeners = append(r.listeners, listener)
+
+	return listener
+}
+
+// notifyListeners tells everyone listening that the entries have changed.
+// It never blocks. Must be called with the lock held.
+func (r *Registrar) notifyListeners() {
+	for _, listener := range r.listeners {
+		select {
+		case listener <- struct{}{}:
+		default: // Already has a notification pending
+		}
 	}
 }
 
 func (r *Registrar) PrintRequests() {
 	log.Debug("Requests:")
-	for n, entry := range r.entries {
-		log.Debugf("%s: %#v\n", n, *entry)
+	for key, entry := range r.entries {
+		log.Debugf("%s (%s): %#v\n", entry.Name, key, *entry)
 	}
 }
 
@@ -43,7 +208,21 @@ func (r *Registrar) GetEntry(svcName string) *Entry {
 	r.RLock()
 	defer r.RUnlock()
 
-	return r.entries[svcName]
+	key, ok := r.services[svcName]
+	if !ok {
+		return nil
+	}
+
+	return r.entries[key]
+}
+
+// GetListener returns the entry listening on the ListenerKey passed in, if
+// there is one.
+func (r *Registrar) GetListener(key string) *Entry {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.entries[key]
 }
 
 // EachEntry iterates the entries, calling the passed function on each.
@@ -51,8 +230,8 @@ func (r *Registrar) EachEntry(fn func(svcName string, entry *Entry) error) error
 	r.RLock()
 	defer r.RUnlock()
 
-	for svcName, entry := range r.entries {
-		err := fn(svcName, entry)
+	for _, entry := range r.entries {
+		err := fn(entry.Name, entry)
 		if err != nil {
 			return err
 		}
@@ -61,26 +240,125 @@ func (r *Registrar) EachEntry(fn func(svcName string, entry *Entry) error) error
 	return nil
 }
 
+// View returns a copy of the Registrar's entries, taken under one lock, so
+// that things built from several of its methods agree with each other.
+// Entries are never changed in place, so they are shared rather than copied.
+// Only the methods that read the entries make sense on the copy.
+func (r *Registrar) View() *Registrar {
+	r.RLock()
+	defer r.RUnlock()
+
+	view := &Registrar{
+		entries:     make(map[string]*Entry, len(r.entries)),
+		services:    make(map[string]string, len(r.services)),
+		IngressAddr: r.IngressAddr,
+		SNIAddr:     r.SNIAddr,
+	}
+
+	for key, entry := range r.entries {
+		view.entries[key] = entry
+	}
+	for name, key := range r.services {
+		view.services[name] = key
+	}
+
+	return view
+}
+
+// EntriesForContainer returns the entries learned from the container with
+// the ID passed in, keyed by name.
+func (r *Registrar) EntriesForContainer(containerID string) map[string]*Entry {
+	entries := make(map[string]*Entry)
+
+	r.EachEntry(func(name string, entry *Entry) error {
+		if entry.ContainerID == containerID {
+			entries[name] = entry
+		}
+		return nil
+	})
+
+	return entries
+}
+
+// findEntry returns the entry that the one passed in refers to, or nil if
+// there isn't one. Entries are found by their listener, so we still find one
+// if its labels changed. When we know both containers they must match, so we
+// never find an entry that another container has since taken over.
+func (r *Registrar) findEntry(entry *Entry) *Entry {
+	existing := r.GetListener(ListenerKey(entry))
+	if existing == nil {
+		return nil
+	}
+
+	if existing.HasContainer() && entry.HasContainer() && existing.ContainerID != entry.ContainerID {
+		return nil
+	}
+
+	return existing
+}
+
 // RequestToEntry turns a shimrpc Request into a permanent state entry for
-// storage in the registrar.
-func RequestToEntry(req *shimrpc.RegistrarRequest) *Entry {
+// storage in the registrar. Either address family is fine, but the IPs must
+// be valid, as must any tuning labels, virtual hosts, and server names.
+func RequestToEntry(req *shimrpc.RegistrarRequest) (*Entry, error) {
+	frontendIP := net.ParseIP(req.FrontendAddr)
+	if frontendIP == nil {
+		return nil, fmt.Errorf("Invalid frontend address '%s'", req.FrontendAddr)
+	}
+
+	backendIP := net.ParseIP(req.BackendAddr)
+	if backendIP == nil {
+		return nil, fmt.Errorf("Invalid backend address '%s'", req.BackendAddr)
+	}
+
+	tuning, err := ParseTuning(req.Tuning)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ServicePort < 0 || req.ServicePort > 65535 {
+		return nil, fmt.Errorf("Invalid service port %d", req.ServicePort)
+	}
+
+	_, err = ParseVirtualHosts(req.Labels[VirtualHostsLabel])
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ParseServerNames(req.Labels[ServerNamesLabel])
+	if err != nil {
+		return nil, err
+	}
+
 	return &Entry{
 		FrontendAddr: &net.TCPAddr{
-			IP:   net.ParseIP(req.FrontendAddr),
+			IP:   frontendIP,
 			Port: int(req.FrontendPort),
 		},
 		BackendAddr: &net.TCPAddr{
-			IP:   net.ParseIP(req.BackendAddr),
+			IP:   backendIP,
 			Port: int(req.BackendPort),
 		},
 		ServiceName:     req.ServiceName,
 		EnvironmentName: req.EnvironmentName,
 		ProxyMode:       req.ProxyMode,
-	}
+		Protocol:        req.Protocol,
+		ContainerID:     req.ContainerId,
+		ContainerName:   req.ContainerName,
+		Image:           req.Image,
+		Labels:          req.Labels,
+		Tuning:          tuning,
+		ServicePort:     int(req.ServicePort),
+	}, nil
 }
 
 // Format an Envoy service name from an endpoint
 func SvcName(entry *Entry) string {
+	return withProtocol(entry, fmt.Sprintf("%s-%d", serviceAndEnv(entry), entry.FrontendAddr.Port))
+}
+
+// serviceAndEnv returns the start of the names for an entry
+func serviceAndEnv(entry *Entry) string {
 	var svcName string
 
 	if len(entry.ServiceName) > 0 {
@@ -95,37 +373,184 @@ func SvcName(entry *Entry) string {
 		svcName = "unknown-"
 	}
 
-	return fmt.Sprintf("%s-%d", svcName, entry.FrontendAddr.Port)
+	return svcName
+}
+
+// withProtocol finishes off a name. The same port can be published for both
+// TCP and UDP, so UDP gets a suffix.
+func withProtocol(entry *Entry, name string) string {
+	if entry.IsUDP() {
+		return name + "-udp"
+	}
+
+	return name
+}
+
+// ListenerKey identifies where an entry listens: its protocol, IP, and port.
+// The wildcard addresses of both families share a key, because a DualStack
+// listener on :: covers both of them.
+func ListenerKey(entry *Entry) string {
+	protocol := ProtocolTCP
+	if entry.IsUDP() {
+		protocol = ProtocolUDP
+	}
+
+	host := "*"
+	if !entry.FrontendAddr.IP.IsUnspecified() {
+		host = entry.FrontendAddr.IP.String()
+	}
+
+	return protocol + "/" + net.JoinHostPort(host, strconv.Itoa(entry.FrontendAddr.Port))
+}
+
+// nameFor picks the name to serve an entry under. An entry keeps its name
+// unless its labels change. Otherwise it's the SvcName, unless another
+// listener already has that, e.g. because the same port is published on two
+// host IPs, in which case we add the host IP. Must be called with the lock
+// held.
+func (r *Registrar) nameFor(key string, entry *Entry) string {
+	name := SvcName(entry)
+
+	if existing, ok := r.entries[key]; ok && SvcName(existing) == name {
+		return existing.Name
+	}
+
+	if owner, ok := r.services[name]; !ok || owner == key {
+		return name
+	}
+
+	// Dots and colons in cluster names upset Envoy's stats
+	return name + "-" + strings.NewReplacer(".", "_", ":", "_").Replace(entry.FrontendAddr.IP.String())
+}
+
+// AddEntry stores an entry under its listener, replacing whatever was
+// listening there, and writes it through to the Store if there is one.
+// Returns the name the entry is served under.
+func (r *Registrar) AddEntry(entry *Entry) string {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.addEntryLocked(entry)
+}
+
+// addEntryLocked is AddEntry for callers that already hold the lock.
+func (r *Registrar) addEntryLocked(entry *Entry) string {
+	key := ListenerKey(entry)
+
+	named := *entry
+	named.Name = r.nameFor(key, entry)
+	named.DockerHealth = r.containerHealth[named.ContainerID]
+
+	log.Infof("Registering %s on %s for %s\n", named.Name, key, named.ContainerString())
+
+	// The old name goes away if the labels changed
+	existing, ok := r.entries[key]
+	if ok && existing.Name != named.Name {
+		r.forget(existing.Name)
+	}
+
+	named.Draining = false
+	delete(r.drains, named.Name)
+
+	// A new backend has to answer before we publish it
+	named.Probing = false
+	if ok && sameBackend(existing, &named) {
+		named.Probing = existing.Probing
+	} else if r.shouldProbe(&named) {
+		named.Probing = true
+		go r.waitForBackend(&named)
+	}
+
+	r.entries[key] = &named
+	r.services[named.Name] = key
+	if r.store != nil {
+		// The in-memory state is what we serve, so carry on regardless
+		if err := r.store.Put(named.Name, &named); err != nil {
+			log.Errorf("Unable to persist %s: %s", named.Name, err)
+		}
+	}
+
+	r.PrintRequests()
+	r.notifyListeners()
+
+	return named.Name
+}
+
+// RemoveEntry removes the named entry, writing the change through to the
+// Store if there is one.
+func (r *Registrar) RemoveEntry(name string) {
+	log.Infof("Deregistering %s\n", name)
+	r.Lock()
+	defer r.Unlock()
+
+	r.removeEntryLocked(name)
+}
+
+// RemoveEntryIf removes the named entry like RemoveEntry, but only if the
+// check passes. The check is made under the same lock as the removal, so a
+// shim can't re-register the entry in between. Returns true if the entry
+// was removed.
+func (r *Registrar) RemoveEntryIf(name string, check func(entry *Entry) bool) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	key, ok := r.services[name]
+	if !ok || !check(r.entries[key]) {
+		return false
+	}
+
+	log.Infof("Deregistering %s\n", name)
+	r.removeEntryLocked(name)
+
+	return true
+}
+
+// removeEntryLocked is RemoveEntry for callers that already hold the lock.
+func (r *Registrar) removeEntryLocked(name string) {
+	if key, ok := r.services[name]; ok {
+		delete(r.entries, key)
+	}
+	r.forget(name)
+
+	r.PrintRequests()
+	r.notifyListeners()
+}
+
+// forget drops the name from the index and the Store. Must be called with the
+// lock held.
+func (r *Registrar) forget(name string) {
+	delete(r.services, name)
+	delete(r.leases, name)
+	delete(r.drains, name)
+	if r.store != nil {
+		if err := r.store.Delete(name); err != nil {
+			log.Errorf("Unable to persist removal of %s: %s", name, err)
+		}
+	}
 }
 
 // Register is a GRPC callback function that handles our remote calls.
 func (r *Registrar) Register(ctx context.Context, req *shimrpc.RegistrarRequest) (*shimrpc.RegistrarReply, error) {
+	entry, err := RequestToEntry(req)
+	if err != nil {
+		return &shimrpc.RegistrarReply{StatusCode: 0}, err
+	}
+
 	// Register a new endpoint
 	if req.Action == shimrpc.RegistrarRequest_REGISTER {
-		entry := RequestToEntry(req)
-		name := SvcName(entry)
-
-		log.Infof("Registering %s\n", name)
-		r.Lock()
-		r.entries[name] = entry
-		r.PrintRequests()
-		r.Unlock()
-		return &shimrpc.RegistrarReply{1}, nil
+		_, err := r.RegisterEntry(entry)
+		if err != nil {
+			return &shimrpc.RegistrarReply{StatusCode: 0}, err
+		}
+		return &shimrpc.RegistrarReply{StatusCode: 1}, nil
 	}
 
 	// Deregister an endpoint
 	if req.Action == shimrpc.RegistrarRequest_DEREGISTER {
-		entry := RequestToEntry(req)
-		name := SvcName(entry)
-
-		log.Infof("Deregistering %s\n", name)
-		r.Lock()
-		delete(r.entries, name)
-		r.PrintRequests()
-		r.Unlock()
-		return &shimrpc.RegistrarReply{1}, nil
+		r.DeregisterEntry(entry)
+		return &shimrpc.RegistrarReply{StatusCode: 1}, nil
 	}
 
 	// Who knows what we were asked to do, but we're not doing it
-	return &shimrpc.RegistrarReply{0}, errors.New("Unknown request action. No idea what to do with it.")
+	return &shimrpc.RegistrarReply{StatusCode: 0}, errors.New("Unknown request action. No idea what to do with it.")
 }
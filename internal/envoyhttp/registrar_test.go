@@ -0,0 +1,243 @@
+package envoyhttp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Register(t *testing.T) {
+	Convey("Register()", t, func() {
+		registrar := NewRegistrar()
+
+		req := &shimrpc.RegistrarRequest{
+			FrontendAddr:    "0.0.0.0",
+			FrontendPort:    24681,
+			BackendAddr:     "172.16.10.5",
+			BackendPort:     80,
+			EnvironmentName: "dev",
+			ServiceName:     "purchas",
+			ProxyMode:       "http",
+			Action:          shimrpc.RegistrarRequest_REGISTER,
+			ContainerId:     "deadbeef0005",
+			ContainerName:   "purchas-1",
+			Image:           "nginx:1.19",
+			Labels:          map[string]string{"ServiceName": "purchas", "EnvironmentName": "dev"},
+		}
+
+		deregister := func(req shimrpc.RegistrarRequest) {
+			req.Action = shimrpc.RegistrarRequest_DEREGISTER
+			_, err := registrar.Register(context.Background(), &req)
+			So(err, ShouldBeNil)
+		}
+
+		_, err := registrar.Register(context.Background(), req)
+		So(err, ShouldBeNil)
+
+		Convey("stores the container's details", func() {
+			entry := registrar.GetEntry("purchas-dev-24681")
+
+			So(entry.ContainerID, ShouldEqual, "deadbeef0005")
+			So(entry.ContainerName, ShouldEqual, "purchas-1")
+			So(entry.Image, ShouldEqual, "nginx:1.19")
+			So(entry.Labels["ServiceName"], ShouldEqual, "purchas")
+			So(entry.ContainerString(), ShouldEqual, "container purchas-1 (deadbeef0005)")
+		})
+
+		Convey("deregisters by container even if the labels changed", func() {
+			changed := *req
+			changed.ServiceName = "hakluyt"
+			deregister(changed)
+
+			So(registrar.GetEntry("purchas-dev-24681"), ShouldBeNil)
+		})
+
+		Convey("does not deregister an entry another container has taken over", func() {
+			stale := *req
+			stale.ContainerId = "deadbeef0004"
+			deregister(stale)
+
+			So(registrar.GetEntry("purchas-dev-24681"), ShouldNotBeNil)
+		})
+
+		Convey("deregisters by name when the shim doesn't know the container", func() {
+			old := *req
+			old.ContainerId = ""
+			deregister(old)
+
+			So(registrar.GetEntry("purchas-dev-24681"), ShouldBeNil)
+		})
+
+		Convey("rejects a listener that another container already has", func() {
+			other := *req
+			other.ContainerId = "deadbeef0006"
+			other.ServiceName = "hakluyt"
+
+			_, err := registrar.Register(context.Background(), &other)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "tcp/*:24681 is already registered as purchas-dev-24681")
+			So(registrar.GetEntry("hakluyt-dev-24681"), ShouldBeNil)
+			So(registrar.GetEntry("purchas-dev-24681").ContainerID, ShouldEqual, "deadbeef0005")
+		})
+
+		Convey("gives a listener to only one of the containers racing for it", func() {
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			winners := make(chan string, 50)
+			for i := 0; i < 50; i++ {
+				racer := *req
+				racer.FrontendPort = 24690
+				racer.ContainerId = fmt.Sprintf("deadbeef01%02d", i)
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					if _, err := registrar.Register(context.Background(), &racer); err == nil {
+						winners <- racer.ContainerId
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+			close(winners)
+
+			So(len(winners), ShouldEqual, 1)
+			So(registrar.GetEntry("purchas-dev-24690").ContainerID, ShouldEqual, <-winners)
+		})
+
+		Convey("keeps the same port on two host IPs apart", func() {
+			first := *req
+			first.FrontendAddr = "192.168.168.99"
+			first.ContainerId = "deadbeef0006"
+			second := first
+			second.FrontendAddr = "192.168.168.98"
+			second.ContainerId = "deadbeef0007"
+
+			_, err := registrar.Register(context.Background(), &first)
+			So(err, ShouldBeNil)
+			_, err = registrar.Register(context.Background(), &second)
+			So(err, ShouldBeNil)
+
+			So(registrar.GetEntry("purchas-dev-24681").ContainerID, ShouldEqual, "deadbeef0005")
+			So(registrar.GetEntry("purchas-dev-24681-192_168_168_99").ContainerID, ShouldEqual, "deadbeef0006")
+			So(registrar.GetEntry("purchas-dev-24681-192_168_168_98").ContainerID, ShouldEqual, "deadbeef0007")
+
+			Convey("and removes only the one deregistered", func() {
+				deregister(first)
+
+				So(registrar.GetEntry("purchas-dev-24681-192_168_168_99"), ShouldBeNil)
+				So(registrar.GetEntry("purchas-dev-24681-192_168_168_98"), ShouldNotBeNil)
+				So(registrar.GetEntry("purchas-dev-24681"), ShouldNotBeNil)
+			})
+		})
+
+		Convey("renames the entry when its labels change", func() {
+			renamed := *req
+			renamed.ServiceName = "hakluyt"
+			registrar.Register(context.Background(), &renamed)
+
+			So(registrar.GetEntry("purchas-dev-24681"), ShouldBeNil)
+			So(registrar.GetEntry("hakluyt-dev-24681").Name, ShouldEqual, "hakluyt-dev-24681")
+			So(registrar.GetListener("tcp/*:24681").ServiceName, ShouldEqual, "hakluyt")
+		})
+
+		Convey("keeps the container when an older shim re-registers", func() {
+			old := *req
+			old.ContainerId = ""
+			old.ContainerName = ""
+			old.Image = ""
+			old.Labels = nil
+
+			changes := registrar.Listen()
+			registrar.Register(context.Background(), &old)
+
+			So(len(changes), ShouldEqual, 0)
+			So(registrar.GetEntry("purchas-dev-24681").ContainerID, ShouldEqual, "deadbeef0005")
+		})
+	})
+}
+
+func Test_RemoveEntryIf(t *testing.T) {
+	Convey("RemoveEntryIf()", t, func() {
+		registrar := NewRegistrar()
+		registrar.Register(context.Background(), req1)
+
+		Convey("removes the entry if the check passes", func() {
+			removed := registrar.RemoveEntryIf("bede-dev-12345", func(entry *Entry) bool {
+				return entry.ServiceName == "bede"
+			})
+
+			So(removed, ShouldBeTrue)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldBeNil)
+		})
+
+		Convey("keeps the entry if the check fails", func() {
+			removed := registrar.RemoveEntryIf("bede-dev-12345", func(entry *Entry) bool {
+				return entry.IsDraining()
+			})
+
+			So(removed, ShouldBeFalse)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+		})
+
+		Convey("doesn't check entries that aren't there", func() {
+			removed := registrar.RemoveEntryIf("chretien-dev-23451", func(entry *Entry) bool {
+				return true
+			})
+
+			So(removed, ShouldBeFalse)
+		})
+	})
+}
+
+func Test_View(t *testing.T) {
+	Convey("View()", t, func() {
+		registrar := NewRegistrar()
+		registrar.IngressAddr = &net.TCPAddr{IP: net.IPv4zero, Port: 80}
+		registrar.Register(context.Background(), req1)
+
+		view := registrar.View()
+
+		Convey("has the Registrar's entries", func() {
+			So(view.GetEntry("bede-dev-12345"), ShouldEqual, registrar.GetEntry("bede-dev-12345"))
+			So(view.GetListener("tcp/192.168.168.99:12345").Name, ShouldEqual, "bede-dev-12345")
+			So(view.IngressAddr, ShouldEqual, registrar.IngressAddr)
+		})
+
+		Convey("doesn't change with the Registrar", func() {
+			registrar.Register(context.Background(), req2)
+			registrar.RemoveEntry("bede-dev-12345")
+
+			So(view.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+			So(view.GetEntry("chretien-dev-23451"), ShouldBeNil)
+		})
+	})
+}
+
+func Test_ListenerKey(t *testing.T) {
+	Convey("ListenerKey()", t, func() {
+		entry := func(ip string, port int, protocol string) *Entry {
+			return &Entry{FrontendAddr: &net.TCPAddr{IP: net.ParseIP(ip), Port: port}, Protocol: protocol}
+		}
+
+		Convey("identifies the protocol, IP, and port", func() {
+			So(ListenerKey(entry("192.168.168.99", 53, "udp")), ShouldEqual, "udp/192.168.168.99:53")
+			So(ListenerKey(entry("2001:db8::1", 8443, "tcp")), ShouldEqual, "tcp/[2001:db8::1]:8443")
+		})
+
+		Convey("defaults to TCP", func() {
+			So(ListenerKey(entry("192.168.168.99", 80, "")), ShouldEqual, "tcp/192.168.168.99:80")
+		})
+
+		Convey("shares a key for both wildcard addresses", func() {
+			So(ListenerKey(entry("0.0.0.0", 80, "")), ShouldEqual, "tcp/*:80")
+			So(ListenerKey(entry("::", 80, "")), ShouldEqual, "tcp/*:80")
+		})
+	})
+}
@@ -0,0 +1,254 @@
+package envoyhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// JournalFileName is the name of the journal inside the state directory
+	JournalFileName = "registrar.journal"
+
+	// DefaultCompactEvery is how many changes we append to the journal
+	// between compactions
+	DefaultCompactEvery = 1000
+
+	journalPut    = "put"
+	journalDelete = "delete"
+)
+
+// A Store durably records the entries in a Registrar so that they survive a
+// restart of the server.
+type Store interface {
+	// Put records an entry under the name it is stored with
+	Put(name string, entry *Entry) error
+	// Delete records that the named entry was removed
+	Delete(name string) error
+	// Load returns all the entries in the store, at most one per listener
+	Load() (map[string]*Entry, error)
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// A journalRecord is a single line in the journal file
+type journalRecord struct {
+	Op    string `json:"op"`
+	Name  string `json:"name"`
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// A JournalStore is an append-only JSON journal of Registrar changes. Every
+// change is written and synced to disk before returning. On Load, and after
+// every CompactEvery changes, the journal is replayed and then compacted so
+// it doesn't grow without bound.
+type JournalStore struct {
+	sync.Mutex
+	path    string
+	file    *os.File
+	appends int // Since the last compaction
+
+	CompactEvery int
+}
+
+// NewJournalStore returns a JournalStore that keeps its journal in the
+// state directory passed in, creating the directory if needed.
+func NewJournalStore(stateDir string) (*JournalStore, error) {
+	err := os.MkdirAll(stateDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create state dir %s: %s", stateDir, err)
+	}
+
+	store := &JournalStore{
+		path:         filepath.Join(stateDir, JournalFileName),
+		CompactEvery: DefaultCompactEvery,
+	}
+
+	err = store.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// open opens the journal file for appending.
+func (s *JournalStore) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to open journal %s: %s", s.path, err)
+	}
+
+	s.file = file
+	return nil
+}
+
+// append writes a record to the end of the journal and syncs it to disk.
+func (s *JournalStore) append(record *journalRecord) error {
+	s.Lock()
+	defer s.Unlock()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.file.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("Unable to write journal %s: %s", s.path, err)
+	}
+
+	err = s.file.Sync()
+	if err != nil {
+		return err
+	}
+
+	s.appends++
+	if s.CompactEvery > 0 && s.appends >= s.CompactEvery {
+		// The change is safely written, so this isn't its problem
+		if _, err := s.load(); err != nil {
+			log.Errorf("Unable to compact journal %s: %s", s.path, err)
+		}
+	}
+
+	return nil
+}
+
+// Put records an entry under the name it is stored with.
+func (s *JournalStore) Put(name string, entry *Entry) error {
+	return s.append(&journalRecord{Op: journalPut, Name: name, Entry: entry})
+}
+
+// Delete records that the named entry was removed.
+func (s *JournalStore) Delete(name string) error {
+	return s.append(&journalRecord{Op: journalDelete, Name: name})
+}
+
+// Load replays the journal and returns the resulting entries. The journal is
+// then rewritten to contain only those entries.
+func (s *JournalStore) Load() (map[string]*Entry, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.load()
+}
+
+// load is Load for callers that already hold the lock.
+func (s *JournalStore) load() (map[string]*Entry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read journal %s: %s", s.path, err)
+	}
+	defer file.Close()
+
+	entries, err := replayJournal(file)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.compact(entries)
+	if err != nil {
+		return nil, err
+	}
+	s.appends = 0
+
+	return entries, nil
+}
+
+// replayJournal reads journal records and applies them in order. A torn
+// final line, as left by a crash mid-write, is skipped. Only one entry can
+// have a listener, so if two names have the same one the last put wins.
+func replayJournal(reader io.Reader) (map[string]*Entry, error) {
+	entries := make(map[string]*Entry)
+	names := make(map[string]string) // By ListenerKey
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var record journalRecord
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			log.Warnf("Skipping unreadable journal record: %s", err)
+			continue
+		}
+
+		switch record.Op {
+		case journalPut:
+			if record.Entry == nil {
+				continue
+			}
+
+			key := ListenerKey(record.Entry)
+			if name, ok := names[key]; ok && name != record.Name {
+				log.Warnf("Dropping %s from the journal, %s replaced it on %s", name, record.Name, key)
+				delete(entries, name)
+			}
+			if old, ok := entries[record.Name]; ok {
+				delete(names, ListenerKey(old))
+			}
+
+			entries[record.Name] = record.Entry
+			names[key] = record.Name
+		case journalDelete:
+			if old, ok := entries[record.Name]; ok {
+				delete(names, ListenerKey(old))
+			}
+			delete(entries, record.Name)
+		default:
+			log.Warnf("Skipping journal record with unknown op '%s'", record.Op)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Unable to read journal: %s", err)
+	}
+
+	return entries, nil
+}
+
+// compact atomically replaces the journal with one holding only the current
+// entries. Must be called with the lock held.
+func (s *JournalStore) compact(entries map[string]*Entry) error {
+	tmpPath := s.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to compact journal %s: %s", s.path, err)
+	}
+
+	encoder := json.NewEncoder(tmpFile)
+	for name, entry := range entries {
+		err = encoder.Encode(&journalRecord{Op: journalPut, Name: name, Entry: entry})
+		if err != nil {
+			tmpFile.Close()
+			return fmt.Errorf("Unable to compact journal %s: %s", s.path, err)
+		}
+	}
+
+	err = tmpFile.Sync()
+	tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("Unable to compact journal %s: %s", s.path, err)
+	}
+
+	err = os.Rename(tmpPath, s.path)
+	if err != nil {
+		return fmt.Errorf("Unable to compact journal %s: %s", s.path, err)
+	}
+
+	// Re-open so we're appending to the new file, not the unlinked one
+	s.file.Close()
+	return s.open()
+}
+
+// Close closes the journal file.
+func (s *JournalStore) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.file.Close()
+}
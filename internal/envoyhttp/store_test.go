@@ -0,0 +1,165 @@
+package envoyhttp
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_JournalStore(t *testing.T) {
+	Convey("JournalStore", t, func() {
+		stateDir, _ := ioutil.TempDir("", "envoy-docker-shim")
+		store, err := NewJournalStore(stateDir)
+		So(err, ShouldBeNil)
+
+		Reset(func() {
+			store.Close()
+			os.RemoveAll(stateDir)
+		})
+
+		entry, _ := RequestToEntry(req1)
+
+		Convey("loads entries that were put", func() {
+			So(store.Put("bede-dev-12345", entry), ShouldBeNil)
+
+			entries, err := store.Load()
+			So(err, ShouldBeNil)
+			So(entries, ShouldContainKey, "bede-dev-12345")
+			So(entries["bede-dev-12345"].FrontendAddr.String(), ShouldEqual, "192.168.168.99:12345")
+			So(entries["bede-dev-12345"].BackendAddr.String(), ShouldEqual, "172.16.10.1:80")
+			So(entries["bede-dev-12345"].ProxyMode, ShouldEqual, "tcp")
+		})
+
+		Convey("does not load entries that were deleted", func() {
+			So(store.Put("bede-dev-12345", entry), ShouldBeNil)
+			So(store.Delete("bede-dev-12345"), ShouldBeNil)
+
+			entries, err := store.Load()
+			So(err, ShouldBeNil)
+			So(entries, ShouldBeEmpty)
+		})
+
+		Convey("compacts the journal on load", func() {
+			for i := 0; i < 10; i++ {
+				store.Put("bede-dev-12345", entry)
+			}
+
+			_, err := store.Load()
+			So(err, ShouldBeNil)
+
+			data, _ := ioutil.ReadFile(filepath.Join(stateDir, JournalFileName))
+			So(strings.Count(string(data), "\n"), ShouldEqual, 1)
+
+			Convey("and keeps appending to the new journal", func() {
+				store.Delete("bede-dev-12345")
+
+				entries, err := store.Load()
+				So(err, ShouldBeNil)
+				So(entries, ShouldBeEmpty)
+			})
+		})
+
+		Convey("loads only the last entry put on a listener", func() {
+			store.Put("bede-dev-12345", entry)
+			store.Put("bede-prod-12345", entry)
+
+			entries, err := store.Load()
+			So(err, ShouldBeNil)
+			So(len(entries), ShouldEqual, 1)
+			So(entries, ShouldContainKey, "bede-prod-12345")
+		})
+
+		Convey("compacts the journal as it grows", func() {
+			store.CompactEvery = 5
+			for i := 0; i < 12; i++ {
+				store.Put("bede-dev-12345", entry)
+			}
+
+			data, _ := ioutil.ReadFile(filepath.Join(stateDir, JournalFileName))
+			So(strings.Count(string(data), "\n"), ShouldEqual, 3)
+
+			entries, err := store.Load()
+			So(err, ShouldBeNil)
+			So(entries, ShouldContainKey, "bede-dev-12345")
+		})
+
+		Convey("skips a torn final record", func() {
+			store.Put("bede-dev-12345", entry)
+			store.file.Write([]byte(`{"op":"put","name":"chretien`))
+
+			entries, err := store.Load()
+			So(err, ShouldBeNil)
+			So(len(entries), ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_NewPersistentRegistrar(t *testing.T) {
+	Convey("NewPersistentRegistrar()", t, func() {
+		stateDir, _ := ioutil.TempDir("", "envoy-docker-shim")
+		store, _ := NewJournalStore(stateDir)
+
+		Reset(func() {
+			os.RemoveAll(stateDir)
+		})
+
+		registrar, err := NewPersistentRegistrar(store)
+		So(err, ShouldBeNil)
+
+		registrar.Register(context.Background(), req1)
+		registrar.Register(context.Background(), req2)
+		req2.Action = shimrpc.RegistrarRequest_DEREGISTER
+		registrar.Register(context.Background(), req2)
+		req2.Action = shimrpc.RegistrarRequest_REGISTER
+		store.Close()
+
+		Convey("restores the entries from a previous run", func() {
+			store, _ := NewJournalStore(stateDir)
+			restored, err := NewPersistentRegistrar(store)
+			store.Close()
+
+			So(err, ShouldBeNil)
+			So(restored.GetEntry("bede-dev-12345"), ShouldNotBeNil)
+			So(restored.GetEntry("chretien-dev-23451"), ShouldBeNil)
+			So(restored.GetListener("tcp/192.168.168.99:12345").Name, ShouldEqual, "bede-dev-12345")
+		})
+
+		Convey("restores only one entry for each listener", func() {
+			store, _ := NewJournalStore(stateDir)
+			entry, _ := RequestToEntry(req1)
+			store.Put("bede-prod-12345", entry)
+			store.Close()
+
+			store, _ = NewJournalStore(stateDir)
+			restored, err := NewPersistentRegistrar(store)
+			store.Close()
+
+			So(err, ShouldBeNil)
+			So(restored.GetEntry("bede-dev-12345"), ShouldBeNil)
+			So(restored.GetListener("tcp/192.168.168.99:12345").Name, ShouldEqual, "bede-prod-12345")
+			So(restored.services, ShouldNotContainKey, "bede-dev-12345")
+		})
+
+		Convey("doesn't restore entries that were draining", func() {
+			store, _ := NewJournalStore(stateDir)
+			draining, _ := NewPersistentRegistrar(store)
+			draining.DrainTime = time.Minute
+			draining.DrainEntry("bede-dev-12345")
+			store.Close()
+
+			store, _ = NewJournalStore(stateDir)
+			restored, err := NewPersistentRegistrar(store)
+			store.Close()
+
+			So(err, ShouldBeNil)
+			So(restored.GetEntry("bede-dev-12345"), ShouldBeNil)
+		})
+	})
+}
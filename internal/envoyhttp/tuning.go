@@ -0,0 +1,229 @@
+package envoyhttp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// TuningLabelPrefix starts the name of every Docker label that tunes
+	// Envoy for a service. The shim passes all of them on to us.
+	TuningLabelPrefix = "Envoy"
+
+	// The tuning labels
+	ConnectTimeoutLabel = "EnvoyConnectTimeout"
+	RouteTimeoutLabel   = "EnvoyRouteTimeout"
+	IdleTimeoutLabel    = "EnvoyIdleTimeout"
+	RetryOnLabel        = "EnvoyRetryOn"
+	NumRetriesLabel     = "EnvoyNumRetries"
+	LBPolicyLabel       = "EnvoyLBPolicy"
+	MaxConnectionsLabel = "EnvoyMaxConnections"
+	MaxRequestsLabel    = "EnvoyMaxRequests"
+	TLSCertLabel        = "EnvoyTLSCert"
+	TLSSecretLabel      = "EnvoyTLSSecret"
+
+	// DefaultConnectTimeout is how long Envoy will wait to connect to a backend
+	DefaultConnectTimeout = 500 * time.Millisecond
+	// DefaultLBPolicy is how Envoy picks a backend
+	DefaultLBPolicy = "round_robin"
+)
+
+var (
+	errUnknownTuningLabel = errors.New("unknown tuning label")
+
+	// Certificate and secret names end up in file names and Envoy's stats
+	secretNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+	// The LB policies that all the API versions support
+	lbPolicies = []string{"round_robin", "least_request", "random", "ring_hash"}
+
+	// The retry conditions that all the API versions support
+	retryConditions = []string{
+		"5xx", "gateway-error", "connect-failure", "retriable-4xx", "refused-stream",
+	}
+)
+
+// Tuning holds the Envoy settings that can be changed for each service
+// with Docker labels. The zero value gets Envoy's behaviour from before
+// these could be tuned.
+type Tuning struct {
+	ConnectTimeout time.Duration `json:",omitempty"` // Zero is DefaultConnectTimeout
+	RouteTimeout   time.Duration `json:",omitempty"` // HTTP only, zero is no timeout
+	IdleTimeout    time.Duration `json:",omitempty"` // Zero is Envoy's default
+	RetryOn        string        `json:",omitempty"` // HTTP only, as for Envoy's x-envoy-retry-on
+	NumRetries     int           `json:",omitempty"` // Zero is Envoy's default
+	LBPolicy       string        `json:",omitempty"` // Empty is DefaultLBPolicy
+	MaxConnections int           `json:",omitempty"` // Circuit breaker, zero is Envoy's default
+	MaxRequests    int           `json:",omitempty"` // Circuit breaker, zero is Envoy's default
+	TLSCert        string        `json:",omitempty"` // Terminate TLS with this pair from the cert dir
+	TLSSecret      string        `json:",omitempty"` // Or with this secret from Envoy's bootstrap
+	HealthCheck    HealthCheck   // Active health checks, see HealthCheckLabel
+}
+
+// HasTLS tells us if Envoy should terminate TLS on the listener.
+func (t *Tuning) HasTLS() bool {
+	return len(t.TLSCert) > 0 || len(t.TLSSecret) > 0
+}
+
+// ConnectTimeoutOrDefault returns the connect timeout to configure.
+func (t *Tuning) ConnectTimeoutOrDefault() time.Duration {
+	if t.ConnectTimeout == 0 {
+		return DefaultConnectTimeout
+	}
+
+	return t.ConnectTimeout
+}
+
+// LBPolicyOrDefault returns the LB policy to configure.
+func (t *Tuning) LBPolicyOrDefault() string {
+	if len(t.LBPolicy) < 1 {
+		return DefaultLBPolicy
+	}
+
+	return t.LBPolicy
+}
+
+// HasCircuitBreakers tells us if any of the circuit breaker limits are set.
+func (t *Tuning) HasCircuitBreakers() bool {
+	return t.MaxConnections > 0 || t.MaxRequests > 0
+}
+
+// ParseTuning validates the tuning labels passed in and returns the Tuning
+// they describe. Labels that don't start with TuningLabelPrefix are ignored.
+// Unknown ones that do are logged, so typos don't go unnoticed, and ignored,
+// since other tools may use the prefix for their own labels.
+func ParseTuning(labels map[string]string) (Tuning, error) {
+	var tuning Tuning
+
+	// Sorted, so we always complain about the same label first
+	var names []string
+	for name := range labels {
+		if strings.HasPrefix(name, TuningLabelPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := labels[name]
+
+		err := tuning.set(name, value)
+		if err == errUnknownTuningLabel {
+			// Other tools label containers with Envoy* too, like EnvoyVersion
+			log.Warnf("Ignoring unknown tuning label %s", name)
+			continue
+		}
+		if err != nil {
+			return Tuning{}, fmt.Errorf("Invalid label %s '%s': %s", name, value, err)
+		}
+	}
+
+	if tuning.NumRetries > 0 && len(tuning.RetryOn) < 1 {
+		return Tuning{}, fmt.Errorf("Invalid label %s: %s must also be set", NumRetriesLabel, RetryOnLabel)
+	}
+
+	if len(tuning.TLSCert) > 0 && len(tuning.TLSSecret) > 0 {
+		return Tuning{}, fmt.Errorf("Invalid labels %s and %s: only one can be set", TLSCertLabel, TLSSecretLabel)
+	}
+
+	err := tuning.HealthCheck.validate()
+	if err != nil {
+		return Tuning{}, err
+	}
+
+	return tuning, nil
+}
+
+// set parses a single label into the tuning.
+func (t *Tuning) set(name, value string) error {
+	var err error
+
+	switch name {
+	case ConnectTimeoutLabel:
+		t.ConnectTimeout, err = parsePositiveDuration(value)
+	case RouteTimeoutLabel:
+		t.RouteTimeout, err = parsePositiveDuration(value)
+	case IdleTimeoutLabel:
+		t.IdleTimeout, err = parsePositiveDuration(value)
+	case RetryOnLabel:
+		t.RetryOn, err = parseRetryOn(value)
+	case NumRetriesLabel:
+		t.NumRetries, err = parsePositiveInt(value)
+	case LBPolicyLabel:
+		t.LBPolicy, err = parseOneOf(value, lbPolicies)
+	case MaxConnectionsLabel:
+		t.MaxConnections, err = parsePositiveInt(value)
+	case MaxRequestsLabel:
+		t.MaxRequests, err = parsePositiveInt(value)
+	case TLSCertLabel:
+		t.TLSCert, err = parseSecretName(value)
+	case TLSSecretLabel:
+		t.TLSSecret, err = parseSecretName(value)
+	default:
+		var ok bool
+		ok, err = t.HealthCheck.set(name, value)
+		if !ok {
+			err = errUnknownTuningLabel
+		}
+	}
+
+	return err
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("expected a positive duration like 500ms")
+	}
+
+	return duration, nil
+}
+
+func parsePositiveInt(value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil || i <= 0 {
+		return 0, fmt.Errorf("expected a positive whole number")
+	}
+
+	return i, nil
+}
+
+func parseOneOf(value string, allowed []string) (string, error) {
+	value = strings.ToLower(value)
+	for _, a := range allowed {
+		if value == a {
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+}
+
+func parseSecretName(value string) (string, error) {
+	if !secretNameRegexp.MatchString(value) {
+		return "", fmt.Errorf("expected a name made of letters, digits, '.', '_', and '-'")
+	}
+
+	return value, nil
+}
+
+// parseRetryOn checks each of the comma separated retry conditions.
+func parseRetryOn(value string) (string, error) {
+	var conditions []string
+	for _, condition := range strings.Split(value, ",") {
+		condition, err := parseOneOf(strings.TrimSpace(condition), retryConditions)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return strings.Join(conditions, ","), nil
+}
@@ -0,0 +1,175 @@
+package envoyhttp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ParseTuning(t *testing.T) {
+	Convey("ParseTuning()", t, func() {
+		Convey("parses all the tuning labels", func() {
+			tuning, err := ParseTuning(map[string]string{
+				"EnvoyConnectTimeout": "250ms",
+				"EnvoyRouteTimeout":   "15s",
+				"EnvoyIdleTimeout":    "5m",
+				"EnvoyRetryOn":        "5xx, Connect-Failure",
+				"EnvoyNumRetries":     "3",
+				"EnvoyLBPolicy":       "LEAST_REQUEST",
+				"EnvoyMaxConnections": "100",
+				"EnvoyMaxRequests":    "1000",
+				"ServiceName":         "bede", // Not ours
+			})
+
+			So(err, ShouldBeNil)
+			So(tuning, ShouldResemble, Tuning{
+				ConnectTimeout: 250 * time.Millisecond,
+				RouteTimeout:   15 * time.Second,
+				IdleTimeout:    5 * time.Minute,
+				RetryOn:        "5xx,connect-failure",
+				NumRetries:     3,
+				LBPolicy:       "least_request",
+				MaxConnections: 100,
+				MaxRequests:    1000,
+			})
+		})
+
+		Convey("defaults to the original settings", func() {
+			tuning, err := ParseTuning(nil)
+
+			So(err, ShouldBeNil)
+			So(tuning.ConnectTimeoutOrDefault(), ShouldEqual, 500*time.Millisecond)
+			So(tuning.LBPolicyOrDefault(), ShouldEqual, "round_robin")
+			So(tuning.RouteTimeout, ShouldEqual, 0)
+			So(tuning.HasCircuitBreakers(), ShouldBeFalse)
+		})
+
+		Convey("rejects invalid values", func() {
+			for name, value := range map[string]string{
+				"EnvoyConnectTimeout": "500",
+				"EnvoyRouteTimeout":   "-1s",
+				"EnvoyRetryOn":        "5xx,sometimes",
+				"EnvoyNumRetries":     "lots",
+				"EnvoyLBPolicy":       "maglev",
+				"EnvoyMaxConnections": "0",
+			} {
+				_, err := ParseTuning(map[string]string{name: value})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, name)
+			}
+		})
+
+		Convey("ignores unknown tuning labels", func() {
+			tuning, err := ParseTuning(map[string]string{
+				"EnvoyConectTimeout": "1s",
+				"EnvoyVersion":       "1.16",
+				"EnvoyRouteTimeout":  "5s",
+			})
+			So(err, ShouldBeNil)
+			So(tuning.ConnectTimeout, ShouldEqual, 0)
+			So(tuning.RouteTimeout, ShouldEqual, 5*time.Second)
+		})
+
+		Convey("rejects retries without retry conditions", func() {
+			_, err := ParseTuning(map[string]string{"EnvoyNumRetries": "3"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "EnvoyRetryOn must also be set")
+		})
+
+		Convey("parses the health check labels", func() {
+			tuning, err := ParseTuning(map[string]string{
+				"EnvoyHealthCheck":         "HTTP",
+				"EnvoyHealthCheckPath":     "/status",
+				"EnvoyHealthCheckInterval": "5s",
+				"EnvoyHealthCheckTimeout":  "1s",
+				"EnvoyHealthyThreshold":    "1",
+				"EnvoyUnhealthyThreshold":  "4",
+				"EnvoyHealthCheckStatuses": "200-299, 304",
+			})
+
+			So(err, ShouldBeNil)
+			So(tuning.HealthCheck, ShouldResemble, HealthCheck{
+				Type:               "http",
+				Path:               "/status",
+				Interval:           5 * time.Second,
+				Timeout:            1 * time.Second,
+				HealthyThreshold:   1,
+				UnhealthyThreshold: 4,
+				Statuses:           "200-299,304",
+			})
+			So(tuning.HealthCheck.StatusRanges(), ShouldResemble, [][2]int64{{200, 300}, {304, 305}})
+		})
+
+		Convey("defaults the health check settings", func() {
+			tuning, err := ParseTuning(map[string]string{"EnvoyHealthCheck": "tcp"})
+
+			So(err, ShouldBeNil)
+			So(tuning.HealthCheck.Enabled(), ShouldBeTrue)
+			So(tuning.HealthCheck.IntervalOrDefault(), ShouldEqual, 10*time.Second)
+			So(tuning.HealthCheck.TimeoutOrDefault(), ShouldEqual, 2*time.Second)
+			So(tuning.HealthCheck.HealthyThresholdOrDefault(), ShouldEqual, 2)
+			So(tuning.HealthCheck.UnhealthyThresholdOrDefault(), ShouldEqual, 3)
+			So(tuning.HealthCheck.StatusRanges(), ShouldResemble, [][2]int64{{200, 201}})
+		})
+
+		Convey("rejects invalid health check labels", func() {
+			for name, value := range map[string]string{
+				"EnvoyHealthCheck":         "grpc",
+				"EnvoyHealthCheckPath":     "status",
+				"EnvoyHealthCheckInterval": "0s",
+				"EnvoyUnhealthyThreshold":  "none",
+				"EnvoyHealthCheckStatuses": "299-200",
+			} {
+				_, err := ParseTuning(map[string]string{"EnvoyHealthCheck": "http", name: value})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, name)
+			}
+		})
+
+		Convey("rejects health check settings without a health check", func() {
+			_, err := ParseTuning(map[string]string{"EnvoyHealthCheckPath": "/status"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "EnvoyHealthCheck must also be set")
+
+			_, err = ParseTuning(map[string]string{"EnvoyHealthCheck": "tcp", "EnvoyHealthCheckPath": "/status"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("parses the TLS labels", func() {
+			tuning, err := ParseTuning(map[string]string{"EnvoyTLSCert": "bede.example.com"})
+			So(err, ShouldBeNil)
+			So(tuning.TLSCert, ShouldEqual, "bede.example.com")
+			So(tuning.HasTLS(), ShouldBeTrue)
+
+			tuning, err = ParseTuning(map[string]string{"EnvoyTLSSecret": "wildcard_cert"})
+			So(err, ShouldBeNil)
+			So(tuning.TLSSecret, ShouldEqual, "wildcard_cert")
+			So(tuning.HasTLS(), ShouldBeTrue)
+
+			tuning, _ = ParseTuning(nil)
+			So(tuning.HasTLS(), ShouldBeFalse)
+		})
+
+		Convey("rejects invalid TLS labels", func() {
+			_, err := ParseTuning(map[string]string{"EnvoyTLSCert": "../etc/passwd"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "EnvoyTLSCert")
+
+			_, err = ParseTuning(map[string]string{"EnvoyTLSCert": "bede", "EnvoyTLSSecret": "bede"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "only one can be set")
+		})
+
+		Convey("rejects registrations with invalid tuning", func() {
+			registrar := NewRegistrar()
+			req := *req1
+			req.Tuning = map[string]string{"EnvoyLBPolicy": "maglev"}
+
+			_, err := registrar.Register(context.Background(), &req)
+			So(err, ShouldNotBeNil)
+			So(registrar.GetEntry("bede-dev-12345"), ShouldBeNil)
+		})
+	})
+}
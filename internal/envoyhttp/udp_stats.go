@@ -0,0 +1,170 @@
+package envoyhttp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultUDPStatsExpiry is how long we keep reporting the stats for a
+	// UDP proxy that has stopped reporting without saying it closed.
+	DefaultUDPStatsExpiry = 2 * time.Minute
+)
+
+var (
+	udpLabels = []string{"service_name", "environment_name", "frontend", "backend"}
+
+	udpDatagramsDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_datagrams_total",
+		"Datagrams forwarded by in-process UDP proxies, by direction.",
+		append(udpLabels, "direction"), nil,
+	)
+	udpBytesDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_bytes_total",
+		"Bytes forwarded by in-process UDP proxies, by direction.",
+		append(udpLabels, "direction"), nil,
+	)
+	udpConnTrackDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_conntrack_entries",
+		"Flows currently tracked by in-process UDP proxies.",
+		udpLabels, nil,
+	)
+	udpExpiriesDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_conntrack_expiries_total",
+		"Flows dropped by in-process UDP proxies after going idle.",
+		udpLabels, nil,
+	)
+	udpEvictionsDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_conntrack_evictions_total",
+		"Flows dropped by in-process UDP proxies to stay within their flow limit.",
+		udpLabels, nil,
+	)
+	udpRateLimitedDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_rate_limited_total",
+		"Datagrams dropped by the per-client rate limit of in-process UDP proxies.",
+		udpLabels, nil,
+	)
+	udpDialFailuresDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_dial_failures_total",
+		"Failures to open a socket to the container for a new flow.",
+		udpLabels, nil,
+	)
+	udpWriteFailuresDesc = prometheus.NewDesc(
+		"envoy_docker_shim_udp_write_failures_total",
+		"Failures to write a datagram in either direction.",
+		udpLabels, nil,
+	)
+)
+
+type udpStatsEntry struct {
+	stats    *shimrpc.UDPStatsRequest
+	received time.Time
+}
+
+// UDPStatsCollector holds the latest counters reported by each of the shims
+// proxying UDP in-process and exports them to Prometheus.
+type UDPStatsCollector struct {
+	sync.Mutex
+	proxies map[string]*udpStatsEntry
+
+	Expiry time.Duration
+}
+
+func NewUDPStatsCollector() *UDPStatsCollector {
+	return &UDPStatsCollector{
+		proxies: make(map[string]*udpStatsEntry),
+		Expiry:  DefaultUDPStatsExpiry,
+	}
+}
+
+// udpProxyKey identifies a UDP proxy by the address it listens on.
+func udpProxyKey(proxy *shimrpc.RegistrarRequest) string {
+	return net.JoinHostPort(proxy.FrontendAddr, strconv.Itoa(int(proxy.FrontendPort)))
+}
+
+// Update stores the stats passed in, or forgets the proxy when it closed.
+func (c *UDPStatsCollector) Update(stats *shimrpc.UDPStatsRequest) {
+	c.Lock()
+	defer c.Unlock()
+
+	key := udpProxyKey(stats.Proxy)
+	if stats.Closed {
+		delete(c.proxies, key)
+		return
+	}
+
+	c.proxies[key] = &udpStatsEntry{stats: stats, received: time.Now()}
+}
+
+// Describe implements prometheus.Collector.
+func (c *UDPStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- udpDatagramsDesc
+	ch <- udpBytesDesc
+	ch <- udpConnTrackDesc
+	ch <- udpExpiriesDesc
+	ch <- udpEvictionsDesc
+	ch <- udpRateLimitedDesc
+	ch <- udpDialFailuresDesc
+	ch <- udpWriteFailuresDesc
+}
+
+// Collect implements prometheus.Collector. Proxies that stopped reporting
+// are expired here.
+func (c *UDPStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Lock()
+	defer c.Unlock()
+
+	for key, entry := range c.proxies {
+		if time.Since(entry.received) > c.Expiry {
+			log.Warnf("UDP proxy on %s stopped reporting stats", key)
+			delete(c.proxies, key)
+			continue
+		}
+
+		s := entry.stats
+		labels := []string{
+			s.Proxy.ServiceName,
+			s.Proxy.EnvironmentName,
+			key,
+			net.JoinHostPort(s.Proxy.BackendAddr, strconv.Itoa(int(s.Proxy.BackendPort))),
+		}
+
+		counter := func(desc *prometheus.Desc, value uint64, extra ...string) {
+			ch <- prometheus.MustNewConstMetric(
+				desc, prometheus.CounterValue, float64(value), append(labels, extra...)...,
+			)
+		}
+
+		counter(udpDatagramsDesc, s.DatagramsIn, "in")
+		counter(udpDatagramsDesc, s.DatagramsOut, "out")
+		counter(udpBytesDesc, s.BytesIn, "in")
+		counter(udpBytesDesc, s.BytesOut, "out")
+		counter(udpExpiriesDesc, s.ConntrackExpiries)
+		counter(udpEvictionsDesc, s.ConntrackEvictions)
+		counter(udpRateLimitedDesc, s.RateLimited)
+		counter(udpDialFailuresDesc, s.DialFailures)
+		counter(udpWriteFailuresDesc, s.WriteFailures)
+
+		ch <- prometheus.MustNewConstMetric(
+			udpConnTrackDesc, prometheus.GaugeValue, float64(s.ConntrackEntries), labels...,
+		)
+	}
+}
+
+// ReportUDPStats is a GRPC callback that takes the stats from a shim.
+func (r *Registrar) ReportUDPStats(ctx context.Context, req *shimrpc.UDPStatsRequest) (*shimrpc.RegistrarReply, error) {
+	if req.Proxy == nil {
+		return &shimrpc.RegistrarReply{StatusCode: 0}, fmt.Errorf("UDP stats reported without a proxy")
+	}
+
+	r.UDPStats.Update(req)
+	return &shimrpc.RegistrarReply{StatusCode: 1}, nil
+}
@@ -0,0 +1,79 @@
+package envoyhttp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_UDPStatsCollector(t *testing.T) {
+	Convey("UDPStatsCollector", t, func() {
+		registrar := NewRegistrar()
+		collector := registrar.UDPStats
+
+		stats := &shimrpc.UDPStatsRequest{
+			Proxy:              req1,
+			DatagramsIn:        10,
+			BytesIn:            1000,
+			DatagramsOut:       5,
+			BytesOut:           500,
+			ConntrackEntries:   2,
+			ConntrackEvictions: 3,
+			DialFailures:       1,
+			RateLimited:        4,
+		}
+
+		Convey("exports the reported stats", func() {
+			_, err := registrar.ReportUDPStats(context.Background(), stats)
+			So(err, ShouldBeNil)
+
+			expected := `
+# HELP envoy_docker_shim_udp_datagrams_total Datagrams forwarded by in-process UDP proxies, by direction.
+# TYPE envoy_docker_shim_udp_datagrams_total counter
+envoy_docker_shim_udp_datagrams_total{backend="172.16.10.1:80",direction="in",environment_name="dev",frontend="192.168.168.99:12345",service_name="bede"} 10
+envoy_docker_shim_udp_datagrams_total{backend="172.16.10.1:80",direction="out",environment_name="dev",frontend="192.168.168.99:12345",service_name="bede"} 5
+# HELP envoy_docker_shim_udp_conntrack_entries Flows currently tracked by in-process UDP proxies.
+# TYPE envoy_docker_shim_udp_conntrack_entries gauge
+envoy_docker_shim_udp_conntrack_entries{backend="172.16.10.1:80",environment_name="dev",frontend="192.168.168.99:12345",service_name="bede"} 2
+# HELP envoy_docker_shim_udp_conntrack_evictions_total Flows dropped by in-process UDP proxies to stay within their flow limit.
+# TYPE envoy_docker_shim_udp_conntrack_evictions_total counter
+envoy_docker_shim_udp_conntrack_evictions_total{backend="172.16.10.1:80",environment_name="dev",frontend="192.168.168.99:12345",service_name="bede"} 3
+# HELP envoy_docker_shim_udp_rate_limited_total Datagrams dropped by the per-client rate limit of in-process UDP proxies.
+# TYPE envoy_docker_shim_udp_rate_limited_total counter
+envoy_docker_shim_udp_rate_limited_total{backend="172.16.10.1:80",environment_name="dev",frontend="192.168.168.99:12345",service_name="bede"} 4
+`
+			err = testutil.CollectAndCompare(collector, strings.NewReader(expected),
+				"envoy_docker_shim_udp_datagrams_total", "envoy_docker_shim_udp_conntrack_entries",
+				"envoy_docker_shim_udp_conntrack_evictions_total", "envoy_docker_shim_udp_rate_limited_total",
+			)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("forgets a proxy that closed", func() {
+			collector.Update(stats)
+			So(testutil.CollectAndCount(collector), ShouldEqual, 10)
+
+			closed := *stats
+			closed.Closed = true
+			collector.Update(&closed)
+			So(testutil.CollectAndCount(collector), ShouldEqual, 0)
+		})
+
+		Convey("expires a proxy that stopped reporting", func() {
+			collector.Update(stats)
+			collector.Expiry = -1 * time.Second
+
+			So(testutil.CollectAndCount(collector), ShouldEqual, 0)
+		})
+
+		Convey("rejects stats without a proxy", func() {
+			_, err := registrar.ReportUDPStats(context.Background(), &shimrpc.UDPStatsRequest{})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
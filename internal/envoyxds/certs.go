@@ -0,0 +1,220 @@
+package envoyxds
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// A certificate named foo is the pair foo.crt and foo.key in the cert dir
+	CertSuffix = ".crt"
+	KeySuffix  = ".key"
+)
+
+// A Cert is a certificate chain and its private key, PEM encoded.
+type Cert struct {
+	Chain []byte
+	Key   []byte
+}
+
+// A CertDir holds the certificate and key pairs from a directory, which we
+// serve to Envoy as SDS secrets for the listeners that terminate TLS. It
+// reloads them when the files change, so certificates can be renewed
+// without restarting anything.
+type CertDir struct {
+	sync.RWMutex
+	Path      string
+	certs     map[string]*Cert
+	listeners []chan struct{}
+}
+
+// NewCertDir returns a CertDir with the pairs currently in the directory
+// loaded. Call Watch to keep them up to date.
+func NewCertDir(path string) (*CertDir, error) {
+	dir := &CertDir{Path: path}
+
+	_, err := dir.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return dir, nil
+}
+
+// Get returns the named pair, or nil if there isn't one.
+func (d *CertDir) Get(name string) *Cert {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.certs[name]
+}
+
+// Listen returns a channel that receives a notification each time the
+// certificates change. Like the Registrar's, notifications are coalesced.
+func (d *CertDir) Listen() <-chan struct{} {
+	d.Lock()
+	defer d.Unlock()
+
+	listener := make(chan struct{}, 1)
+	d.listeners = append(d.listeners, listener)
+
+	return listener
+}
+
+// Load reads all the pairs from the directory, and tells us if any of them
+// changed. Pairs that don't go together are logged and skipped, keeping the
+// last good version if we had one.
+func (d *CertDir) Load() (bool, error) {
+	files, err := ioutil.ReadDir(d.Path)
+	if err != nil {
+		return false, fmt.Errorf("Unable to read cert dir %s: %s", d.Path, err)
+	}
+
+	certs := make(map[string]*Cert)
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), CertSuffix) {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), CertSuffix)
+
+		cert, err := loadCert(filepath.Join(d.Path, name))
+		if err != nil {
+			log.Errorf("Skipping certificate %s: %s", name, err)
+			if old := d.Get(name); old != nil {
+				certs[name] = old
+			}
+			continue
+		}
+
+		certs[name] = cert
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	if sameCerts(d.certs, certs) {
+		return false, nil
+	}
+
+	log.Infof("Loaded %d certificates from %s", len(certs), d.Path)
+	d.certs = certs
+	for _, listener := range d.listeners {
+		select {
+		case listener <- struct{}{}:
+		default: // Already has a notification pending
+		}
+	}
+
+	return true, nil
+}
+
+// Watch reloads the pairs each time something in the directory changes,
+// until the context is cancelled.
+func (d *CertDir) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	err = watcher.Add(d.Path)
+	if err != nil {
+		return fmt.Errorf("Unable to watch cert dir %s: %s", d.Path, err)
+	}
+
+	for {
+		select {
+		case event := <-watcher.Events:
+			log.Debugf("Cert dir changed: %s", event)
+
+			_, err := d.Load()
+			if err != nil {
+				log.Error(err)
+			}
+		case err := <-watcher.Errors:
+			log.Errorf("Error watching cert dir %s: %s", d.Path, err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// certsFor returns the pairs from the CertDir that the entries in the
+// Registrar terminate TLS with, by name. Envoy holds a listener back until
+// its certificate turns up, so we just complain about missing ones.
+func certsFor(registrar *envoyhttp.Registrar, certs *CertDir) map[string]*Cert {
+	needed := make(map[string]bool)
+	registrar.EachEntry(func(name string, entry *envoyhttp.Entry) error {
+		if len(entry.Tuning.TLSCert) > 0 && !entry.IsUDP() {
+			needed[entry.Tuning.TLSCert] = true
+		}
+		return nil
+	})
+
+	result := make(map[string]*Cert)
+	for name := range needed {
+		var cert *Cert
+		if certs != nil {
+			cert = certs.Get(name)
+		}
+
+		if cert == nil {
+			log.Warnf("No certificate %s in the cert dir, Envoy will wait for it", name)
+			continue
+		}
+
+		result[name] = cert
+	}
+
+	return result
+}
+
+// loadCert reads the pair at the path passed in, less the suffixes, and makes
+// sure the key goes with the certificate.
+func loadCert(path string) (*Cert, error) {
+	chain, err := ioutil.ReadFile(path + CertSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ioutil.ReadFile(path + KeySuffix)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no %s file", KeySuffix)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tls.X509KeyPair(chain, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cert{Chain: chain, Key: key}, nil
+}
+
+func sameCerts(a, b map[string]*Cert) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, cert := range a {
+		other, ok := b[name]
+		if !ok || !bytes.Equal(cert.Chain, other.Chain) || !bytes.Equal(cert.Key, other.Key) {
+			return false
+		}
+	}
+
+	return true
+}
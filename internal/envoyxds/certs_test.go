@@ -0,0 +1,137 @@
+package envoyxds
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// writeCert generates a self-signed pair and writes it to the cert dir
+func writeCert(dir, name string) *Cert {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	cert := &Cert{
+		Chain: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:   pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+	ioutil.WriteFile(filepath.Join(dir, name+CertSuffix), cert.Chain, 0644)
+	ioutil.WriteFile(filepath.Join(dir, name+KeySuffix), cert.Key, 0600)
+
+	return cert
+}
+
+func Test_CertDir(t *testing.T) {
+	Convey("CertDir", t, func() {
+		path, _ := ioutil.TempDir("", "envoy-docker-shim-certs")
+		cert := writeCert(path, "bede")
+
+		Reset(func() {
+			os.RemoveAll(path)
+		})
+
+		certs, err := NewCertDir(path)
+		So(err, ShouldBeNil)
+
+		Convey("loads the pairs in the directory", func() {
+			So(certs.Get("bede"), ShouldResemble, cert)
+			So(certs.Get("chretien"), ShouldBeNil)
+		})
+
+		Convey("refuses a directory that isn't there", func() {
+			_, err := NewCertDir(filepath.Join(path, "missing"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("notifies listeners when the pairs change", func() {
+			changes := certs.Listen()
+
+			changed, err := certs.Load()
+			So(err, ShouldBeNil)
+			So(changed, ShouldBeFalse)
+			So(len(changes), ShouldEqual, 0)
+
+			renewed := writeCert(path, "bede")
+			changed, err = certs.Load()
+			So(err, ShouldBeNil)
+			So(changed, ShouldBeTrue)
+			So(len(changes), ShouldEqual, 1)
+			So(certs.Get("bede"), ShouldResemble, renewed)
+		})
+
+		Convey("skips pairs that don't go together", func() {
+			other := writeCert(path, "chretien")
+			ioutil.WriteFile(filepath.Join(path, "bede"+KeySuffix), other.Key, 0600)
+			ioutil.WriteFile(filepath.Join(path, "hakluyt"+CertSuffix), other.Chain, 0644)
+
+			_, err := certs.Load()
+			So(err, ShouldBeNil)
+			So(certs.Get("bede"), ShouldResemble, cert) // The last good one
+			So(certs.Get("chretien"), ShouldResemble, other)
+			So(certs.Get("hakluyt"), ShouldBeNil)
+		})
+
+		Convey("reloads the pairs when the files change", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go certs.Watch(ctx)
+
+			// Keep writing the files until the watcher is running and sees them
+			added := writeCert(path, "chretien")
+			reloaded := eventually(func() bool {
+				ioutil.WriteFile(filepath.Join(path, "chretien"+CertSuffix), added.Chain, 0644)
+				return certs.Get("chretien") != nil
+			})
+
+			So(reloaded, ShouldBeTrue)
+			So(certs.Get("chretien"), ShouldResemble, added)
+		})
+
+		Convey("returns the pairs the Registrar needs", func() {
+			registrar := envoyhttp.NewRegistrar()
+			writeCert(path, "chretien")
+			certs.Load()
+
+			for i, name := range []string{"bede", "hakluyt"} {
+				registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+					FrontendAddr:    "192.168.168.99",
+					FrontendPort:    int32(8443 + i),
+					BackendAddr:     "172.16.10.1",
+					BackendPort:     443,
+					EnvironmentName: "dev",
+					ServiceName:     name,
+					ProxyMode:       "http",
+					Action:          shimrpc.RegistrarRequest_REGISTER,
+					Tuning:          map[string]string{"EnvoyTLSCert": name},
+				})
+			}
+
+			needed := certsFor(registrar, certs)
+			So(needed, ShouldHaveLength, 1)
+			So(needed["bede"], ShouldResemble, cert)
+
+			So(certsFor(registrar, nil), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,609 @@
+package envoyxds
+
+import (
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	api "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	clusterv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2/cluster"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	tlsinspector "github.com/envoyproxy/go-control-plane/envoy/config/filter/listener/tls_inspector/v2"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	tcp "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
+	udp "github.com/envoyproxy/go-control-plane/envoy/config/filter/udp/udp_proxy/v2alpha"
+	envoytype "github.com/envoyproxy/go-control-plane/envoy/type"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev2 "github.com/envoyproxy/go-control-plane/pkg/cache/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
+)
+
+const (
+	// ConnectTimeout is how long Envoy will wait to connect to a backend,
+	// unless the container's labels say otherwise
+	ConnectTimeout = envoyhttp.DefaultConnectTimeout
+
+	// UDPProxy is the name of Envoy's UDP proxy listener filter, which
+	// go-control-plane doesn't have a well known name for.
+	UDPProxy = "envoy.filters.udp_listener.udp_proxy"
+
+	// MetadataNamespace is the filter metadata namespace that endpoints carry
+	// their container's details in.
+	MetadataNamespace = "envoy_docker_shim"
+)
+
+// SnapshotV2 generates a consistent snapshot of the v2 xDS resources for
+// all the entries in the Registrar, and the secrets they need from the
+// CertDir, if there is one.
+func SnapshotV2(registrar *envoyhttp.Registrar, certs *CertDir, version string) (cachev2.Snapshot, error) {
+	var listeners, clusters, endpoints, secrets []types.Resource
+
+	// Everything is built from the same entries, even if they change meanwhile
+	registrar = registrar.View()
+
+	err := registrar.EachEntry(func(name string, entry *envoyhttp.Entry) error {
+		l, err := ListenerV2FromEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		listeners = append(listeners, l)
+		clusters = append(clusters, ClusterV2FromEntry(entry))
+		endpoints = append(endpoints, EndpointsV2FromEntry(entry))
+
+		return nil
+	})
+
+	if err != nil {
+		return cachev2.Snapshot{}, err
+	}
+
+	for _, group := range registrar.ServiceGroups() {
+		l, err := ListenerV2FromEntry(group.Entry)
+		if err != nil {
+			return cachev2.Snapshot{}, err
+		}
+
+		listeners = append(listeners, l)
+		clusters = append(clusters, ClusterV2FromEntry(group.Entry))
+		endpoints = append(endpoints, EndpointsV2FromGroup(group))
+	}
+
+	if ingress := registrar.Ingress(); ingress != nil {
+		l, err := IngressListenerV2(ingress)
+		if err != nil {
+			return cachev2.Snapshot{}, err
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	// Envoy won't take a listener without any filter chains
+	if sni := registrar.SNIPassthrough(); sni != nil && len(sni.Hosts) > 0 {
+		l, err := SNIListenerV2(sni)
+		if err != nil {
+			return cachev2.Snapshot{}, err
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	for name, cert := range certsFor(registrar, certs) {
+		secrets = append(secrets, SecretV2FromCert(name, cert))
+	}
+
+	return cachev2.NewSnapshot(version, endpoints, clusters, nil, listeners, nil, secrets), nil
+}
+
+// socketAddressV2 returns an Envoy v2 socket address for an IP and port.
+func socketAddressV2(ip string, port int) *core.Address {
+	return &core.Address{
+		Address: &core.Address_SocketAddress{
+			SocketAddress: &core.SocketAddress{
+				Protocol: core.SocketAddress_TCP,
+				Address:  ip,
+				PortSpecifier: &core.SocketAddress_PortValue{
+					PortValue: uint32(port),
+				},
+			},
+		},
+	}
+}
+
+// listenerAddressV2 returns the address Envoy listens on for an entry,
+// which is a UDP address for UDP entries. Dual stack entries accept IPv4 on
+// the IPv6 wildcard.
+func listenerAddressV2(entry *envoyhttp.Entry) *core.Address {
+	address := socketAddressV2(entry.FrontendAddr.IP.String(), entry.FrontendAddr.Port)
+	if entry.IsUDP() {
+		address.GetSocketAddress().Protocol = core.SocketAddress_UDP
+	}
+
+	// Listening on :: for both families, see envoyhttp.MergeDualStack
+	address.GetSocketAddress().Ipv4Compat = entry.DualStack
+
+	return address
+}
+
+// lbPoliciesV2 maps the LB policies allowed by envoyhttp.ParseTuning to v2
+var lbPoliciesV2 = map[string]api.Cluster_LbPolicy{
+	"round_robin":   api.Cluster_ROUND_ROBIN,
+	"least_request": api.Cluster_LEAST_REQUEST,
+	"random":        api.Cluster_RANDOM,
+	"ring_hash":     api.Cluster_RING_HASH,
+}
+
+// circuitBreakersV2 returns the default priority circuit breakers for the
+// tuning, or nil to leave Envoy's defaults alone.
+func circuitBreakersV2(tuning *envoyhttp.Tuning) *clusterv2.CircuitBreakers {
+	if !tuning.HasCircuitBreakers() {
+		return nil
+	}
+
+	return &clusterv2.CircuitBreakers{
+		Thresholds: []*clusterv2.CircuitBreakers_Thresholds{
+			{
+				MaxConnections: uint32Value(tuning.MaxConnections),
+				MaxRequests:    uint32Value(tuning.MaxRequests),
+			},
+		},
+	}
+}
+
+// retryPolicyV2 returns the route's retry policy, or nil for no retries.
+func retryPolicyV2(tuning *envoyhttp.Tuning) *route.RetryPolicy {
+	if len(tuning.RetryOn) < 1 {
+		return nil
+	}
+
+	return &route.RetryPolicy{
+		RetryOn:    tuning.RetryOn,
+		NumRetries: uint32Value(tuning.NumRetries),
+	}
+}
+
+// healthStatusV2 returns the health of an entry's endpoint from Docker, or
+// that it is draining. Without a HEALTHCHECK we leave it to Envoy.
+func healthStatusV2(entry *envoyhttp.Entry) core.HealthStatus {
+	switch {
+	case entry.IsDraining():
+		return core.HealthStatus_DRAINING
+	case !entry.HasDockerHealth():
+		return core.HealthStatus_UNKNOWN
+	case entry.IsHealthy():
+		return core.HealthStatus_HEALTHY
+	default:
+		return core.HealthStatus_UNHEALTHY
+	}
+}
+
+// commonLbConfigV2 turns off Envoy's panic mode for clusters gated on
+// Docker's health checks. Otherwise Envoy would send traffic to unhealthy
+// endpoints once most of them were, e.g. to a single replica that is still
+// starting.
+func commonLbConfigV2(entry *envoyhttp.Entry) *api.Cluster_CommonLbConfig {
+	if !entry.HasDockerHealth() {
+		return nil
+	}
+
+	return &api.Cluster_CommonLbConfig{
+		HealthyPanicThreshold: &envoytype.Percent{Value: 0},
+	}
+}
+
+// healthChecksV2 returns the active health checks for an entry's cluster.
+// UDP backends can't be health checked.
+func healthChecksV2(entry *envoyhttp.Entry) []*core.HealthCheck {
+	hc := &entry.Tuning.HealthCheck
+	if !hc.Enabled() || entry.IsUDP() {
+		return nil
+	}
+
+	check := &core.HealthCheck{
+		Timeout:            ptypes.DurationProto(hc.TimeoutOrDefault()),
+		Interval:           ptypes.DurationProto(hc.IntervalOrDefault()),
+		UnhealthyThreshold: uint32Value(hc.UnhealthyThresholdOrDefault()),
+		HealthyThreshold:   uint32Value(hc.HealthyThresholdOrDefault()),
+	}
+
+	if hc.Type == envoyhttp.HealthCheckHTTP {
+		var statuses []*envoytype.Int64Range
+		for _, r := range hc.StatusRanges() {
+			statuses = append(statuses, &envoytype.Int64Range{Start: r[0], End: r[1]})
+		}
+
+		check.HealthChecker = &core.HealthCheck_HttpHealthCheck_{
+			HttpHealthCheck: &core.HealthCheck_HttpHealthCheck{
+				Path:             hc.PathOrDefault(),
+				ExpectedStatuses: statuses,
+			},
+		}
+	} else {
+		check.HealthChecker = &core.HealthCheck_TcpHealthCheck_{
+			TcpHealthCheck: &core.HealthCheck_TcpHealthCheck{},
+		}
+	}
+
+	return []*core.HealthCheck{check}
+}
+
+// uint32Value wraps a setting, leaving it unset when it is zero.
+func uint32Value(i int) *wrappers.UInt32Value {
+	if i == 0 {
+		return nil
+	}
+
+	return &wrappers.UInt32Value{Value: uint32(i)}
+}
+
+// idleTimeout returns the idle timeout for any of the proxies, leaving it
+// unset when it is zero.
+func idleTimeout(tuning *envoyhttp.Tuning) *duration.Duration {
+	if tuning.IdleTimeout == 0 {
+		return nil
+	}
+
+	return ptypes.DurationProto(tuning.IdleTimeout)
+}
+
+// containerMetadata describes the container behind an entry, for either API
+// version. It returns nil when we don't know the container.
+func containerMetadata(entry *envoyhttp.Entry) map[string]*structpb.Struct {
+	if !entry.HasContainer() {
+		return nil
+	}
+
+	return map[string]*structpb.Struct{
+		MetadataNamespace: {
+			Fields: map[string]*structpb.Value{
+				"container_id":   stringValue(entry.ContainerID),
+				"container_name": stringValue(entry.ContainerName),
+				"image":          stringValue(entry.Image),
+			},
+		},
+	}
+}
+
+func stringValue(s string) *structpb.Value {
+	return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: s}}
+}
+
+// metadataV2 wraps the container metadata for the v2 API.
+func metadataV2(entry *envoyhttp.Entry) *core.Metadata {
+	if !entry.HasContainer() {
+		return nil
+	}
+
+	return &core.Metadata{FilterMetadata: containerMetadata(entry)}
+}
+
+// ClusterV2FromEntry returns an EDS cluster, fed over ADS, for a Registrar
+// entry. It is the v2 equivalent of EnvoyClustersFromRegistrar.
+func ClusterV2FromEntry(entry *envoyhttp.Entry) *api.Cluster {
+	return &api.Cluster{
+		Name:                 entry.ClusterName(),
+		ConnectTimeout:       ptypes.DurationProto(entry.Tuning.ConnectTimeoutOrDefault()),
+		ClusterDiscoveryType: &api.Cluster_Type{Type: api.Cluster_EDS},
+		LbPolicy:             lbPoliciesV2[entry.Tuning.LBPolicyOrDefault()],
+		CircuitBreakers:      circuitBreakersV2(&entry.Tuning),
+		HealthChecks:         healthChecksV2(entry),
+		CommonLbConfig:       commonLbConfigV2(entry),
+		EdsClusterConfig: &api.Cluster_EdsClusterConfig{
+			EdsConfig: &core.ConfigSource{
+				ConfigSourceSpecifier: &core.ConfigSource_Ads{
+					Ads: &core.AggregatedConfigSource{},
+				},
+			},
+		},
+	}
+}
+
+// EndpointsV2FromEntry returns the load assignment for the cluster
+// generated from a Registrar entry. It is the v2 equivalent of the SDS
+// registration results.
+func EndpointsV2FromEntry(entry *envoyhttp.Entry) *api.ClusterLoadAssignment {
+	return endpointsV2(entry.ClusterName(), []*envoyhttp.Entry{entry})
+}
+
+// EndpointsV2FromGroup returns the load assignment for the shared cluster
+// of a ServiceGroup, with an endpoint for each replica.
+func EndpointsV2FromGroup(group *envoyhttp.ServiceGroup) *api.ClusterLoadAssignment {
+	return endpointsV2(group.Entry.ClusterName(), group.Members)
+}
+
+func endpointsV2(name string, entries []*envoyhttp.Entry) *api.ClusterLoadAssignment {
+	var lbEndpoints []*endpoint.LbEndpoint
+	for _, entry := range entries {
+		// Envoy doesn't hear about it until the backend answers
+		if !entry.IsReady() {
+			continue
+		}
+
+		lbEndpoints = append(lbEndpoints, &endpoint.LbEndpoint{
+			HostIdentifier: &endpoint.LbEndpoint_Endpoint{
+				Endpoint: &endpoint.Endpoint{
+					Address: socketAddressV2(
+						entry.BackendAddr.IP.String(), entry.BackendAddr.Port,
+					),
+				},
+			},
+			Metadata:     metadataV2(entry),
+			HealthStatus: healthStatusV2(entry),
+		})
+	}
+
+	return &api.ClusterLoadAssignment{
+		ClusterName: name,
+		Endpoints: []*endpoint.LocalityLbEndpoints{
+			{LbEndpoints: lbEndpoints},
+		},
+	}
+}
+
+// ListenerV2FromEntry takes a Registrar entry and formats it into an Envoy v2
+// listener. It is the v2 equivalent of EnvoyListenerFromEntry.
+func ListenerV2FromEntry(entry *envoyhttp.Entry) (*api.Listener, error) {
+	apiName := entry.ClusterName()
+
+	if entry.IsUDP() {
+		return udpListenerV2(entry)
+	}
+
+	var filter *listener.Filter
+	var err error
+	if entry.ProxyMode == "http" {
+		filter, err = httpFilterV2(entry)
+	} else { // == "tcp"
+		filter, err = tcpFilterV2(entry)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	transportSocket, err := transportSocketV2(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Listener{
+		Name:    apiName,
+		Address: listenerAddressV2(entry),
+		FilterChains: []*listener.FilterChain{
+			{
+				Filters:         []*listener.Filter{filter},
+				TransportSocket: transportSocket,
+			},
+		},
+	}, nil
+}
+
+// SNIListenerV2 returns the shared TLS passthrough listener, with a filter
+// chain for each of the services on it that matches their server names.
+func SNIListenerV2(sni *envoyhttp.Ingress) (*api.Listener, error) {
+	inspector, err := ptypes.MarshalAny(&tlsinspector.TlsInspector{})
+	if err != nil {
+		return nil, err
+	}
+
+	chains := make([]*listener.FilterChain, 0, len(sni.Hosts))
+	for _, host := range sni.Hosts {
+		filter, err := tcpFilterV2(host.Entry)
+		if err != nil {
+			return nil, err
+		}
+
+		chains = append(chains, &listener.FilterChain{
+			FilterChainMatch: &listener.FilterChainMatch{ServerNames: host.Domains},
+			Filters:          []*listener.Filter{filter},
+		})
+	}
+
+	return &api.Listener{
+		Name:    sni.Entry.Name,
+		Address: listenerAddressV2(sni.Entry),
+		ListenerFilters: []*listener.ListenerFilter{
+			{
+				Name:       wellknown.TlsInspector,
+				ConfigType: &listener.ListenerFilter_TypedConfig{TypedConfig: inspector},
+			},
+		},
+		FilterChains: chains,
+	}, nil
+}
+
+// transportSocketV2 returns the transport socket that terminates TLS on the
+// entry's listener, or nil if it's plaintext. Certificates from the cert dir
+// come from us over ADS, and secrets from Envoy's bootstrap.
+func transportSocketV2(entry *envoyhttp.Entry) (*core.TransportSocket, error) {
+	tuning := &entry.Tuning
+	if !tuning.HasTLS() {
+		return nil, nil
+	}
+
+	secret := &auth.SdsSecretConfig{Name: tuning.TLSSecret}
+	if len(tuning.TLSCert) > 0 {
+		secret = &auth.SdsSecretConfig{
+			Name: tuning.TLSCert,
+			SdsConfig: &core.ConfigSource{
+				ConfigSourceSpecifier: &core.ConfigSource_Ads{
+					Ads: &core.AggregatedConfigSource{},
+				},
+			},
+		}
+	}
+
+	config, err := ptypes.MarshalAny(&auth.DownstreamTlsContext{
+		CommonTlsContext: &auth.CommonTlsContext{
+			TlsCertificateSdsSecretConfigs: []*auth.SdsSecretConfig{secret},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.TransportSocket{
+		Name:       wellknown.TransportSocketTls,
+		ConfigType: &core.TransportSocket_TypedConfig{TypedConfig: config},
+	}, nil
+}
+
+// SecretV2FromCert returns the SDS secret for a pair from the cert dir.
+func SecretV2FromCert(name string, cert *Cert) *auth.Secret {
+	return &auth.Secret{
+		Name: name,
+		Type: &auth.Secret_TlsCertificate{
+			TlsCertificate: &auth.TlsCertificate{
+				CertificateChain: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{InlineBytes: cert.Chain},
+				},
+				PrivateKey: &core.DataSource{
+					Specifier: &core.DataSource_InlineBytes{InlineBytes: cert.Key},
+				},
+			},
+		},
+	}
+}
+
+// httpFilterV2 returns an HTTP connection manager that routes everything
+// to the cluster for this entry.
+func httpFilterV2(entry *envoyhttp.Entry) (*listener.Filter, error) {
+	return httpManagerV2(entry.ClusterName(), &entry.Tuning, []*route.VirtualHost{
+		virtualHostV2(entry, []string{"*"}),
+	})
+}
+
+// httpManagerV2 returns an HTTP connection manager serving the virtual hosts
+// passed in.
+func httpManagerV2(name string, tuning *envoyhttp.Tuning, hosts []*route.VirtualHost) (*listener.Filter, error) {
+	manager := &hcm.HttpConnectionManager{
+		CodecType:  hcm.HttpConnectionManager_AUTO,
+		StatPrefix: "ingress_http",
+		RouteSpecifier: &hcm.HttpConnectionManager_RouteConfig{
+			RouteConfig: &api.RouteConfiguration{
+				Name:         name,
+				VirtualHosts: hosts,
+			},
+		},
+		HttpFilters: []*hcm.HttpFilter{
+			{Name: wellknown.Router},
+		},
+		Tracing: &hcm.HttpConnectionManager_Tracing{
+			OperationName: hcm.HttpConnectionManager_Tracing_EGRESS,
+		},
+	}
+
+	if timeout := idleTimeout(tuning); timeout != nil {
+		manager.CommonHttpProtocolOptions = &core.HttpProtocolOptions{IdleTimeout: timeout}
+	}
+
+	config, err := ptypes.MarshalAny(manager)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listener.Filter{
+		Name:       wellknown.HTTPConnectionManager,
+		ConfigType: &listener.Filter_TypedConfig{TypedConfig: config},
+	}, nil
+}
+
+// virtualHostV2 returns a virtual host that routes the domains to the
+// entry's cluster.
+func virtualHostV2(entry *envoyhttp.Entry, domains []string) *route.VirtualHost {
+	apiName := entry.ClusterName()
+
+	return &route.VirtualHost{
+		Name:    apiName,
+		Domains: domains,
+		Routes: []*route.Route{
+			{
+				Match: &route.RouteMatch{
+					PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"},
+				},
+				Action: &route.Route_Route{
+					Route: &route.RouteAction{
+						ClusterSpecifier: &route.RouteAction_Cluster{Cluster: apiName},
+						Timeout:          ptypes.DurationProto(entry.Tuning.RouteTimeout), // 0 is no timeout!
+						RetryPolicy:      retryPolicyV2(&entry.Tuning),
+					},
+				},
+				Decorator: &route.Decorator{Operation: entry.ServiceName},
+			},
+		},
+	}
+}
+
+// IngressListenerV2 returns the shared ingress listener, with a virtual host
+// for each of the services on it.
+func IngressListenerV2(ingress *envoyhttp.Ingress) (*api.Listener, error) {
+	hosts := make([]*route.VirtualHost, 0, len(ingress.Hosts))
+	for _, host := range ingress.Hosts {
+		hosts = append(hosts, virtualHostV2(host.Entry, host.Domains))
+	}
+
+	filter, err := httpManagerV2(ingress.Entry.Name, &ingress.Entry.Tuning, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Listener{
+		Name:    ingress.Entry.Name,
+		Address: listenerAddressV2(ingress.Entry),
+		FilterChains: []*listener.FilterChain{
+			{Filters: []*listener.Filter{filter}},
+		},
+	}, nil
+}
+
+// tcpFilterV2 returns a TCP proxy filter that sends everything to the
+// cluster for this entry.
+func tcpFilterV2(entry *envoyhttp.Entry) (*listener.Filter, error) {
+	proxy := &tcp.TcpProxy{
+		StatPrefix:       "ingress_tcp",
+		ClusterSpecifier: &tcp.TcpProxy_Cluster{Cluster: entry.ClusterName()},
+		IdleTimeout:      idleTimeout(&entry.Tuning),
+	}
+
+	config, err := ptypes.MarshalAny(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listener.Filter{
+		Name:       wellknown.TCPProxy,
+		ConfigType: &listener.Filter_TypedConfig{TypedConfig: config},
+	}, nil
+}
+
+// udpListenerV2 returns a UDP listener that proxies every datagram to the
+// cluster for this entry. UDP listeners have no filter chains, the proxy
+// is a listener filter.
+func udpListenerV2(entry *envoyhttp.Entry) (*api.Listener, error) {
+	proxy := &udp.UdpProxyConfig{
+		StatPrefix:     "ingress_udp",
+		RouteSpecifier: &udp.UdpProxyConfig_Cluster{Cluster: entry.ClusterName()},
+		IdleTimeout:    idleTimeout(&entry.Tuning),
+	}
+
+	config, err := ptypes.MarshalAny(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Listener{
+		Name:    entry.ClusterName(),
+		Address: listenerAddressV2(entry),
+		ListenerFilters: []*listener.ListenerFilter{
+			{
+				Name:       UDPProxy,
+				ConfigType: &listener.ListenerFilter_TypedConfig{TypedConfig: config},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,561 @@
+package envoyxds
+
+import (
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	router "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	tlsinspectorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/tls_inspector/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tcpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	udpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// SnapshotV3 generates a consistent snapshot of the v3 xDS resources for
+// all the entries in the Registrar, and the secrets they need from the
+// CertDir, if there is one.
+func SnapshotV3(registrar *envoyhttp.Registrar, certs *CertDir, version string) (cachev3.Snapshot, error) {
+	var listeners, clusters, endpoints, secrets []types.Resource
+
+	// Everything is built from the same entries, even if they change meanwhile
+	registrar = registrar.View()
+
+	err := registrar.EachEntry(func(name string, entry *envoyhttp.Entry) error {
+		l, err := ListenerV3FromEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		listeners = append(listeners, l)
+		clusters = append(clusters, ClusterV3FromEntry(entry))
+		endpoints = append(endpoints, EndpointsV3FromEntry(entry))
+
+		return nil
+	})
+
+	if err != nil {
+		return cachev3.Snapshot{}, err
+	}
+
+	for _, group := range registrar.ServiceGroups() {
+		l, err := ListenerV3FromEntry(group.Entry)
+		if err != nil {
+			return cachev3.Snapshot{}, err
+		}
+
+		listeners = append(listeners, l)
+		clusters = append(clusters, ClusterV3FromEntry(group.Entry))
+		endpoints = append(endpoints, EndpointsV3FromGroup(group))
+	}
+
+	if ingress := registrar.Ingress(); ingress != nil {
+		l, err := IngressListenerV3(ingress)
+		if err != nil {
+			return cachev3.Snapshot{}, err
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	// Envoy won't take a listener without any filter chains
+	if sni := registrar.SNIPassthrough(); sni != nil && len(sni.Hosts) > 0 {
+		l, err := SNIListenerV3(sni)
+		if err != nil {
+			return cachev3.Snapshot{}, err
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	for name, cert := range certsFor(registrar, certs) {
+		secrets = append(secrets, SecretV3FromCert(name, cert))
+	}
+
+	return cachev3.NewSnapshot(version, endpoints, clusters, nil, listeners, nil, secrets), nil
+}
+
+// socketAddressV3 returns an Envoy v3 socket address for an IP and port.
+func socketAddressV3(ip string, port int) *corev3.Address {
+	return &corev3.Address{
+		Address: &corev3.Address_SocketAddress{
+			SocketAddress: &corev3.SocketAddress{
+				Protocol: corev3.SocketAddress_TCP,
+				Address:  ip,
+				PortSpecifier: &corev3.SocketAddress_PortValue{
+					PortValue: uint32(port),
+				},
+			},
+		},
+	}
+}
+
+// listenerAddressV3 returns the address Envoy listens on for an entry,
+// which is a UDP address for UDP entries. Dual stack entries accept IPv4 on
+// the IPv6 wildcard.
+func listenerAddressV3(entry *envoyhttp.Entry) *corev3.Address {
+	address := socketAddressV3(entry.FrontendAddr.IP.String(), entry.FrontendAddr.Port)
+	if entry.IsUDP() {
+		address.GetSocketAddress().Protocol = corev3.SocketAddress_UDP
+	}
+
+	// Listening on :: for both families, see envoyhttp.MergeDualStack
+	address.GetSocketAddress().Ipv4Compat = entry.DualStack
+
+	return address
+}
+
+// lbPoliciesV3 maps the LB policies allowed by envoyhttp.ParseTuning to v3
+var lbPoliciesV3 = map[string]cluster.Cluster_LbPolicy{
+	"round_robin":   cluster.Cluster_ROUND_ROBIN,
+	"least_request": cluster.Cluster_LEAST_REQUEST,
+	"random":        cluster.Cluster_RANDOM,
+	"ring_hash":     cluster.Cluster_RING_HASH,
+}
+
+// circuitBreakersV3 is the v3 equivalent of circuitBreakersV2.
+func circuitBreakersV3(tuning *envoyhttp.Tuning) *cluster.CircuitBreakers {
+	if !tuning.HasCircuitBreakers() {
+		return nil
+	}
+
+	return &cluster.CircuitBreakers{
+		Thresholds: []*cluster.CircuitBreakers_Thresholds{
+			{
+				MaxConnections: uint32Value(tuning.MaxConnections),
+				MaxRequests:    uint32Value(tuning.MaxRequests),
+			},
+		},
+	}
+}
+
+// retryPolicyV3 is the v3 equivalent of retryPolicyV2.
+func retryPolicyV3(tuning *envoyhttp.Tuning) *routev3.RetryPolicy {
+	if len(tuning.RetryOn) < 1 {
+		return nil
+	}
+
+	return &routev3.RetryPolicy{
+		RetryOn:    tuning.RetryOn,
+		NumRetries: uint32Value(tuning.NumRetries),
+	}
+}
+
+// healthStatusV3 returns the health of an entry's endpoint from Docker, or
+// that it is draining. Without a HEALTHCHECK we leave it to Envoy.
+func healthStatusV3(entry *envoyhttp.Entry) corev3.HealthStatus {
+	switch {
+	case entry.IsDraining():
+		return corev3.HealthStatus_DRAINING
+	case !entry.HasDockerHealth():
+		return corev3.HealthStatus_UNKNOWN
+	case entry.IsHealthy():
+		return corev3.HealthStatus_HEALTHY
+	default:
+		return corev3.HealthStatus_UNHEALTHY
+	}
+}
+
+// commonLbConfigV3 turns off Envoy's panic mode for clusters gated on
+// Docker's health checks. Otherwise Envoy would send traffic to unhealthy
+// endpoints once most of them were, e.g. to a single replica that is still
+// starting.
+func commonLbConfigV3(entry *envoyhttp.Entry) *cluster.Cluster_CommonLbConfig {
+	if !entry.HasDockerHealth() {
+		return nil
+	}
+
+	return &cluster.Cluster_CommonLbConfig{
+		HealthyPanicThreshold: &typev3.Percent{Value: 0},
+	}
+}
+
+// healthChecksV3 returns the active health checks for an entry's cluster.
+// UDP backends can't be health checked.
+func healthChecksV3(entry *envoyhttp.Entry) []*corev3.HealthCheck {
+	hc := &entry.Tuning.HealthCheck
+	if !hc.Enabled() || entry.IsUDP() {
+		return nil
+	}
+
+	check := &corev3.HealthCheck{
+		Timeout:            ptypes.DurationProto(hc.TimeoutOrDefault()),
+		Interval:           ptypes.DurationProto(hc.IntervalOrDefault()),
+		UnhealthyThreshold: uint32Value(hc.UnhealthyThresholdOrDefault()),
+		HealthyThreshold:   uint32Value(hc.HealthyThresholdOrDefault()),
+	}
+
+	if hc.Type == envoyhttp.HealthCheckHTTP {
+		var statuses []*typev3.Int64Range
+		for _, r := range hc.StatusRanges() {
+			statuses = append(statuses, &typev3.Int64Range{Start: r[0], End: r[1]})
+		}
+
+		check.HealthChecker = &corev3.HealthCheck_HttpHealthCheck_{
+			HttpHealthCheck: &corev3.HealthCheck_HttpHealthCheck{
+				Path:             hc.PathOrDefault(),
+				ExpectedStatuses: statuses,
+			},
+		}
+	} else {
+		check.HealthChecker = &corev3.HealthCheck_TcpHealthCheck_{
+			TcpHealthCheck: &corev3.HealthCheck_TcpHealthCheck{},
+		}
+	}
+
+	return []*corev3.HealthCheck{check}
+}
+
+// metadataV3 wraps the container metadata for the v3 API.
+func metadataV3(entry *envoyhttp.Entry) *corev3.Metadata {
+	if !entry.HasContainer() {
+		return nil
+	}
+
+	return &corev3.Metadata{FilterMetadata: containerMetadata(entry)}
+}
+
+// ClusterV3FromEntry returns an EDS cluster, fed over ADS, for a Registrar
+// entry. It is the v3 equivalent of EnvoyClustersFromRegistrar.
+func ClusterV3FromEntry(entry *envoyhttp.Entry) *cluster.Cluster {
+	return &cluster.Cluster{
+		Name:                 entry.ClusterName(),
+		ConnectTimeout:       ptypes.DurationProto(entry.Tuning.ConnectTimeoutOrDefault()),
+		ClusterDiscoveryType: &cluster.Cluster_Type{Type: cluster.Cluster_EDS},
+		LbPolicy:             lbPoliciesV3[entry.Tuning.LBPolicyOrDefault()],
+		CircuitBreakers:      circuitBreakersV3(&entry.Tuning),
+		HealthChecks:         healthChecksV3(entry),
+		CommonLbConfig:       commonLbConfigV3(entry),
+		EdsClusterConfig: &cluster.Cluster_EdsClusterConfig{
+			EdsConfig: &corev3.ConfigSource{
+				ResourceApiVersion: corev3.ApiVersion_V3,
+				ConfigSourceSpecifier: &corev3.ConfigSource_Ads{
+					Ads: &corev3.AggregatedConfigSource{},
+				},
+			},
+		},
+	}
+}
+
+// EndpointsV3FromEntry returns the load assignment for the cluster
+// generated from a Registrar entry.
+func EndpointsV3FromEntry(entry *envoyhttp.Entry) *endpointv3.ClusterLoadAssignment {
+	return endpointsV3(entry.ClusterName(), []*envoyhttp.Entry{entry})
+}
+
+// EndpointsV3FromGroup returns the load assignment for the shared cluster
+// of a ServiceGroup, with an endpoint for each replica.
+func EndpointsV3FromGroup(group *envoyhttp.ServiceGroup) *endpointv3.ClusterLoadAssignment {
+	return endpointsV3(group.Entry.ClusterName(), group.Members)
+}
+
+func endpointsV3(name string, entries []*envoyhttp.Entry) *endpointv3.ClusterLoadAssignment {
+	var lbEndpoints []*endpointv3.LbEndpoint
+	for _, entry := range entries {
+		// Envoy doesn't hear about it until the backend answers
+		if !entry.IsReady() {
+			continue
+		}
+
+		lbEndpoints = append(lbEndpoints, &endpointv3.LbEndpoint{
+			HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+				Endpoint: &endpointv3.Endpoint{
+					Address: socketAddressV3(
+						entry.BackendAddr.IP.String(), entry.BackendAddr.Port,
+					),
+				},
+			},
+			Metadata:     metadataV3(entry),
+			HealthStatus: healthStatusV3(entry),
+		})
+	}
+
+	return &endpointv3.ClusterLoadAssignment{
+		ClusterName: name,
+		Endpoints: []*endpointv3.LocalityLbEndpoints{
+			{LbEndpoints: lbEndpoints},
+		},
+	}
+}
+
+// ListenerV3FromEntry takes a Registrar entry and formats it into an Envoy v3
+// listener. It is the v3 equivalent of EnvoyListenerFromEntry.
+func ListenerV3FromEntry(entry *envoyhttp.Entry) (*listenerv3.Listener, error) {
+	apiName := entry.ClusterName()
+
+	if entry.IsUDP() {
+		return udpListenerV3(entry)
+	}
+
+	var filter *listenerv3.Filter
+	var err error
+	if entry.ProxyMode == "http" {
+		filter, err = httpFilterV3(entry)
+	} else { // == "tcp"
+		filter, err = tcpFilterV3(entry)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	transportSocket, err := transportSocketV3(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listenerv3.Listener{
+		Name:             apiName,
+		Address:          listenerAddressV3(entry),
+		TrafficDirection: corev3.TrafficDirection_OUTBOUND,
+		FilterChains: []*listenerv3.FilterChain{
+			{
+				Filters:         []*listenerv3.Filter{filter},
+				TransportSocket: transportSocket,
+			},
+		},
+	}, nil
+}
+
+// SNIListenerV3 returns the shared TLS passthrough listener, with a filter
+// chain for each of the services on it that matches their server names.
+func SNIListenerV3(sni *envoyhttp.Ingress) (*listenerv3.Listener, error) {
+	inspector, err := ptypes.MarshalAny(&tlsinspectorv3.TlsInspector{})
+	if err != nil {
+		return nil, err
+	}
+
+	chains := make([]*listenerv3.FilterChain, 0, len(sni.Hosts))
+	for _, host := range sni.Hosts {
+		filter, err := tcpFilterV3(host.Entry)
+		if err != nil {
+			return nil, err
+		}
+
+		chains = append(chains, &listenerv3.FilterChain{
+			FilterChainMatch: &listenerv3.FilterChainMatch{ServerNames: host.Domains},
+			Filters:          []*listenerv3.Filter{filter},
+		})
+	}
+
+	return &listenerv3.Listener{
+		Name:             sni.Entry.Name,
+		Address:          listenerAddressV3(sni.Entry),
+		TrafficDirection: corev3.TrafficDirection_OUTBOUND,
+		ListenerFilters: []*listenerv3.ListenerFilter{
+			{
+				Name:       wellknown.TlsInspector,
+				ConfigType: &listenerv3.ListenerFilter_TypedConfig{TypedConfig: inspector},
+			},
+		},
+		FilterChains: chains,
+	}, nil
+}
+
+// transportSocketV3 returns the transport socket that terminates TLS on the
+// entry's listener, or nil if it's plaintext. Certificates from the cert dir
+// come from us over ADS, and secrets from Envoy's bootstrap.
+func transportSocketV3(entry *envoyhttp.Entry) (*corev3.TransportSocket, error) {
+	tuning := &entry.Tuning
+	if !tuning.HasTLS() {
+		return nil, nil
+	}
+
+	secret := &tlsv3.SdsSecretConfig{Name: tuning.TLSSecret}
+	if len(tuning.TLSCert) > 0 {
+		secret = &tlsv3.SdsSecretConfig{
+			Name: tuning.TLSCert,
+			SdsConfig: &corev3.ConfigSource{
+				ResourceApiVersion: corev3.ApiVersion_V3,
+				ConfigSourceSpecifier: &corev3.ConfigSource_Ads{
+					Ads: &corev3.AggregatedConfigSource{},
+				},
+			},
+		}
+	}
+
+	config, err := ptypes.MarshalAny(&tlsv3.DownstreamTlsContext{
+		CommonTlsContext: &tlsv3.CommonTlsContext{
+			TlsCertificateSdsSecretConfigs: []*tlsv3.SdsSecretConfig{secret},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev3.TransportSocket{
+		Name:       wellknown.TransportSocketTls,
+		ConfigType: &corev3.TransportSocket_TypedConfig{TypedConfig: config},
+	}, nil
+}
+
+// SecretV3FromCert returns the SDS secret for a pair from the cert dir.
+func SecretV3FromCert(name string, cert *Cert) *tlsv3.Secret {
+	return &tlsv3.Secret{
+		Name: name,
+		Type: &tlsv3.Secret_TlsCertificate{
+			TlsCertificate: &tlsv3.TlsCertificate{
+				CertificateChain: &corev3.DataSource{
+					Specifier: &corev3.DataSource_InlineBytes{InlineBytes: cert.Chain},
+				},
+				PrivateKey: &corev3.DataSource{
+					Specifier: &corev3.DataSource_InlineBytes{InlineBytes: cert.Key},
+				},
+			},
+		},
+	}
+}
+
+// httpFilterV3 returns an HTTP connection manager that routes everything
+// to the cluster for this entry.
+func httpFilterV3(entry *envoyhttp.Entry) (*listenerv3.Filter, error) {
+	return httpManagerV3(entry.ClusterName(), &entry.Tuning, []*routev3.VirtualHost{
+		virtualHostV3(entry, []string{"*"}),
+	})
+}
+
+// httpManagerV3 returns an HTTP connection manager serving the virtual hosts
+// passed in.
+func httpManagerV3(name string, tuning *envoyhttp.Tuning, hosts []*routev3.VirtualHost) (*listenerv3.Filter, error) {
+	routerConfig, err := ptypes.MarshalAny(&router.Router{})
+	if err != nil {
+		return nil, err
+	}
+
+	manager := &hcmv3.HttpConnectionManager{
+		CodecType:  hcmv3.HttpConnectionManager_AUTO,
+		StatPrefix: "ingress_http",
+		RouteSpecifier: &hcmv3.HttpConnectionManager_RouteConfig{
+			RouteConfig: &routev3.RouteConfiguration{
+				Name:         name,
+				VirtualHosts: hosts,
+			},
+		},
+		HttpFilters: []*hcmv3.HttpFilter{
+			{
+				Name:       wellknown.Router,
+				ConfigType: &hcmv3.HttpFilter_TypedConfig{TypedConfig: routerConfig},
+			},
+		},
+		// The operation name comes from the listener's traffic direction in v3
+		Tracing: &hcmv3.HttpConnectionManager_Tracing{},
+	}
+
+	if timeout := idleTimeout(tuning); timeout != nil {
+		manager.CommonHttpProtocolOptions = &corev3.HttpProtocolOptions{IdleTimeout: timeout}
+	}
+
+	config, err := ptypes.MarshalAny(manager)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listenerv3.Filter{
+		Name:       wellknown.HTTPConnectionManager,
+		ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: config},
+	}, nil
+}
+
+// virtualHostV3 returns a virtual host that routes the domains to the
+// entry's cluster.
+func virtualHostV3(entry *envoyhttp.Entry, domains []string) *routev3.VirtualHost {
+	apiName := entry.ClusterName()
+
+	return &routev3.VirtualHost{
+		Name:    apiName,
+		Domains: domains,
+		Routes: []*routev3.Route{
+			{
+				Match: &routev3.RouteMatch{
+					PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"},
+				},
+				Action: &routev3.Route_Route{
+					Route: &routev3.RouteAction{
+						ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: apiName},
+						Timeout:          ptypes.DurationProto(entry.Tuning.RouteTimeout), // 0 is no timeout!
+						RetryPolicy:      retryPolicyV3(&entry.Tuning),
+					},
+				},
+				Decorator: &routev3.Decorator{Operation: entry.ServiceName},
+			},
+		},
+	}
+}
+
+// IngressListenerV3 returns the shared ingress listener, with a virtual host
+// for each of the services on it.
+func IngressListenerV3(ingress *envoyhttp.Ingress) (*listenerv3.Listener, error) {
+	hosts := make([]*routev3.VirtualHost, 0, len(ingress.Hosts))
+	for _, host := range ingress.Hosts {
+		hosts = append(hosts, virtualHostV3(host.Entry, host.Domains))
+	}
+
+	filter, err := httpManagerV3(ingress.Entry.Name, &ingress.Entry.Tuning, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listenerv3.Listener{
+		Name:             ingress.Entry.Name,
+		Address:          listenerAddressV3(ingress.Entry),
+		TrafficDirection: corev3.TrafficDirection_OUTBOUND,
+		FilterChains: []*listenerv3.FilterChain{
+			{Filters: []*listenerv3.Filter{filter}},
+		},
+	}, nil
+}
+
+// tcpFilterV3 returns a TCP proxy filter that sends everything to the
+// cluster for this entry.
+func tcpFilterV3(entry *envoyhttp.Entry) (*listenerv3.Filter, error) {
+	proxy := &tcpv3.TcpProxy{
+		StatPrefix:       "ingress_tcp",
+		ClusterSpecifier: &tcpv3.TcpProxy_Cluster{Cluster: entry.ClusterName()},
+		IdleTimeout:      idleTimeout(&entry.Tuning),
+	}
+
+	config, err := ptypes.MarshalAny(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listenerv3.Filter{
+		Name:       wellknown.TCPProxy,
+		ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: config},
+	}, nil
+}
+
+// udpListenerV3 returns a UDP listener that proxies every datagram to the
+// cluster for this entry.
+func udpListenerV3(entry *envoyhttp.Entry) (*listenerv3.Listener, error) {
+	proxy := &udpv3.UdpProxyConfig{
+		StatPrefix:     "ingress_udp",
+		RouteSpecifier: &udpv3.UdpProxyConfig_Cluster{Cluster: entry.ClusterName()},
+		IdleTimeout:    idleTimeout(&entry.Tuning),
+	}
+
+	config, err := ptypes.MarshalAny(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listenerv3.Listener{
+		Name:             entry.ClusterName(),
+		Address:          listenerAddressV3(entry),
+		TrafficDirection: corev3.TrafficDirection_OUTBOUND,
+		ListenerFilters: []*listenerv3.ListenerFilter{
+			{
+				Name:       UDPProxy,
+				ConfigType: &listenerv3.ListenerFilter_TypedConfig{TypedConfig: config},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,139 @@
+package envoyxds
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	api "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	clusterservice "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	listenerservice "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
+	cachev2 "github.com/envoyproxy/go-control-plane/pkg/cache/v2"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	serverv2 "github.com/envoyproxy/go-control-plane/pkg/server/v2"
+	serverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const (
+	// AllNodes is the cache key under which we store the one snapshot that
+	// is served to every Envoy. All of the nodes on a host see the same state.
+	AllNodes = "all-nodes"
+)
+
+// allNodesHash maps every Envoy node to the same snapshot
+type allNodesHash struct{}
+
+func (allNodesHash) ID(node *core.Node) string { return AllNodes }
+
+// allNodesHashV3 maps every Envoy v3 node to the same snapshot
+type allNodesHashV3 struct{}
+
+func (allNodesHashV3) ID(node *corev3.Node) string { return AllNodes }
+
+// An XdsServer serves the Envoy v2 and v3 xDS APIs over gRPC from the state
+// in the Registrar. It runs as an Aggregated Discovery Service and pushes a
+// new snapshot to connected Envoys each time the Registrar changes. Each node
+// is served the resources for the API version it connected with, so that
+// Envoys of different versions can share a host during upgrades.
+type XdsServer struct {
+	registrar *envoyhttp.Registrar
+	Certs     *CertDir // Where TLS certificates come from, nil if there isn't one
+	cacheV2   cachev2.SnapshotCache
+	cacheV3   cachev3.SnapshotCache
+	version   int64
+}
+
+// NewXdsServer returns a correctly configured XdsServer.
+func NewXdsServer(registrar *envoyhttp.Registrar) *XdsServer {
+	return &XdsServer{
+		registrar: registrar,
+		cacheV2:   cachev2.NewSnapshotCache(true, allNodesHash{}, log.StandardLogger()),
+		cacheV3:   cachev3.NewSnapshotCache(true, allNodesHashV3{}, log.StandardLogger()),
+	}
+}
+
+// UpdateSnapshot generates new resources from the Registrar and hands them
+// to the snapshot caches, which will push them to all connected nodes.
+func (s *XdsServer) UpdateSnapshot() error {
+	version := strconv.FormatInt(atomic.AddInt64(&s.version, 1), 10)
+
+	snapshotV2, err := SnapshotV2(s.registrar, s.Certs, version)
+	if err != nil {
+		return err
+	}
+
+	err = snapshotV2.Consistent()
+	if err != nil {
+		return err
+	}
+
+	snapshotV3, err := SnapshotV3(s.registrar, s.Certs, version)
+	if err != nil {
+		return err
+	}
+
+	err = snapshotV3.Consistent()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Updating xDS snapshots to version %s", version)
+
+	err = s.cacheV2.SetSnapshot(AllNodes, snapshotV2)
+	if err != nil {
+		return err
+	}
+
+	return s.cacheV3.SetSnapshot(AllNodes, snapshotV3)
+}
+
+// Run watches the Registrar, and the CertDir, for changes and updates the
+// snapshot each time the state changes. It blocks until the context is
+// cancelled.
+func (s *XdsServer) Run(ctx context.Context) {
+	changes := s.registrar.Listen()
+
+	var certChanges <-chan struct{} // Never fires without a CertDir
+	if s.Certs != nil {
+		certChanges = s.Certs.Listen()
+	}
+
+	for {
+		err := s.UpdateSnapshot()
+		if err != nil {
+			log.Errorf("Unable to update xDS snapshot: %s", err)
+		}
+
+		select {
+		case <-changes:
+		case <-certChanges:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Register attaches all of the v2 and v3 discovery services to a gRPC server.
+func (s *XdsServer) Register(grpcServer *grpc.Server) {
+	server := serverv2.NewServer(context.Background(), s.cacheV2, nil)
+
+	discovery.RegisterAggregatedDiscoveryServiceServer(grpcServer, server)
+	api.RegisterListenerDiscoveryServiceServer(grpcServer, server)
+	api.RegisterClusterDiscoveryServiceServer(grpcServer, server)
+	api.RegisterEndpointDiscoveryServiceServer(grpcServer, server)
+
+	serverV3 := serverv3.NewServer(context.Background(), s.cacheV3, nil)
+
+	discoveryv3.RegisterAggregatedDiscoveryServiceServer(grpcServer, serverV3)
+	listenerservice.RegisterListenerDiscoveryServiceServer(grpcServer, serverV3)
+	clusterservice.RegisterClusterDiscoveryServiceServer(grpcServer, serverV3)
+	endpointservice.RegisterEndpointDiscoveryServiceServer(grpcServer, serverV3)
+}
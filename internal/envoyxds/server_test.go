@@ -0,0 +1,739 @@
+package envoyxds
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Nitro/envoy-docker-shim/internal/envoyhttp"
+	"github.com/Nitro/envoy-docker-shim/internal/shimrpc"
+	api "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	tcp "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tcpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev2 "github.com/envoyproxy/go-control-plane/pkg/resource/v2"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	req1 = &shimrpc.RegistrarRequest{
+		FrontendAddr:    "192.168.168.99",
+		FrontendPort:    12345,
+		BackendAddr:     "172.16.10.1",
+		BackendPort:     80,
+		EnvironmentName: "dev",
+		ServiceName:     "bede",
+		ProxyMode:       "tcp",
+		Action:          shimrpc.RegistrarRequest_REGISTER,
+	}
+
+	req2 = &shimrpc.RegistrarRequest{
+		FrontendAddr:    "192.168.168.98",
+		FrontendPort:    23451,
+		BackendAddr:     "172.16.10.2",
+		BackendPort:     8080,
+		EnvironmentName: "dev",
+		ServiceName:     "chretien",
+		ProxyMode:       "http",
+		Action:          shimrpc.RegistrarRequest_REGISTER,
+		ContainerId:     "deadbeef0002",
+		ContainerName:   "chretien-1",
+		Image:           "nginx:1.19",
+	}
+
+	udpReq = &shimrpc.RegistrarRequest{
+		FrontendAddr:    "192.168.168.99",
+		FrontendPort:    53,
+		BackendAddr:     "172.16.10.3",
+		BackendPort:     53,
+		EnvironmentName: "dev",
+		ServiceName:     "dampier",
+		ProxyMode:       "udp-envoy",
+		Protocol:        "udp",
+		Action:          shimrpc.RegistrarRequest_REGISTER,
+	}
+
+	v6Req = &shimrpc.RegistrarRequest{
+		FrontendAddr:    "2001:db8::1",
+		FrontendPort:    8443,
+		BackendAddr:     "fd00::4",
+		BackendPort:     443,
+		EnvironmentName: "dev",
+		ServiceName:     "hakluyt",
+		ProxyMode:       "tcp",
+		Action:          shimrpc.RegistrarRequest_REGISTER,
+	}
+)
+
+var tunedReq = &shimrpc.RegistrarRequest{
+	FrontendAddr:    "192.168.168.96",
+	FrontendPort:    24680,
+	BackendAddr:     "172.16.10.6",
+	BackendPort:     8000,
+	EnvironmentName: "dev",
+	ServiceName:     "purchas",
+	ProxyMode:       "http",
+	Action:          shimrpc.RegistrarRequest_REGISTER,
+	Tuning: map[string]string{
+		"EnvoyConnectTimeout": "2s",
+		"EnvoyRouteTimeout":   "15s",
+		"EnvoyIdleTimeout":    "90s",
+		"EnvoyRetryOn":        "5xx",
+		"EnvoyNumRetries":     "3",
+		"EnvoyLBPolicy":       "ring_hash",
+		"EnvoyMaxConnections": "100",
+		"EnvoyMaxRequests":    "1000",
+
+		"EnvoyHealthCheck":         "http",
+		"EnvoyHealthCheckPath":     "/status",
+		"EnvoyHealthCheckStatuses": "200-299",
+	},
+}
+
+// registerDualStack registers a port published on both 0.0.0.0 and ::,
+// the way Docker does with IPv6 enabled.
+func registerDualStack(registrar *envoyhttp.Registrar) {
+	for _, ip := range []string{"0.0.0.0", "::"} {
+		registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+			FrontendAddr:    ip,
+			FrontendPort:    8080,
+			BackendAddr:     "172.16.10.5",
+			BackendPort:     80,
+			EnvironmentName: "dev",
+			ServiceName:     "mandeville",
+			ProxyMode:       "http",
+			Action:          shimrpc.RegistrarRequest_REGISTER,
+		})
+	}
+}
+
+// registerReplicas registers two replicas that share a ServicePort
+func registerReplicas(registrar *envoyhttp.Registrar) {
+	for i, backend := range []string{"172.16.10.10", "172.16.10.11"} {
+		registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+			FrontendAddr:    "0.0.0.0",
+			FrontendPort:    int32(32000 + i),
+			BackendAddr:     backend,
+			BackendPort:     80,
+			EnvironmentName: "dev",
+			ServiceName:     "polo",
+			ProxyMode:       "http",
+			Action:          shimrpc.RegistrarRequest_REGISTER,
+			ServicePort:     9090,
+		})
+	}
+}
+
+// registerTLS registers one entry terminating TLS with a pair from the
+// cert dir, and one with a secret from Envoy's bootstrap
+func registerTLS(registrar *envoyhttp.Registrar) {
+	for i, tuning := range []map[string]string{
+		{"EnvoyTLSCert": "magellan"},
+		{"EnvoyTLSSecret": "wildcard"},
+	} {
+		registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+			FrontendAddr:    "0.0.0.0",
+			FrontendPort:    int32(8443 + i),
+			BackendAddr:     "172.16.10.12",
+			BackendPort:     int32(8080 + i),
+			EnvironmentName: "dev",
+			ServiceName:     "magellan",
+			ProxyMode:       "http",
+			Action:          shimrpc.RegistrarRequest_REGISTER,
+			Tuning:          tuning,
+		})
+	}
+}
+
+func Test_SnapshotV2(t *testing.T) {
+	Convey("SnapshotV2()", t, func() {
+		registrar := envoyhttp.NewRegistrar()
+		registrar.Register(context.Background(), req1)
+		registrar.Register(context.Background(), req2)
+		registrar.Register(context.Background(), udpReq)
+		registrar.Register(context.Background(), v6Req)
+		registerDualStack(registrar)
+		registrar.Register(context.Background(), tunedReq)
+		registerReplicas(registrar)
+
+		snapshot, err := SnapshotV2(registrar, nil, "1")
+		So(err, ShouldBeNil)
+
+		Convey("generates a consistent snapshot", func() {
+			So(snapshot.Consistent(), ShouldBeNil)
+			So(snapshot.GetVersion(resourcev2.ListenerType), ShouldEqual, "1")
+		})
+
+		Convey("generates a listener, cluster, and endpoint for each entry", func() {
+			So(snapshot.Resources[types.Listener].Items, ShouldContainKey, "bede-dev-12345")
+			So(snapshot.Resources[types.Listener].Items, ShouldContainKey, "chretien-dev-23451")
+			So(snapshot.Resources[types.Cluster].Items, ShouldContainKey, "bede-dev-12345")
+			So(snapshot.Resources[types.Endpoint].Items, ShouldContainKey, "chretien-dev-23451")
+		})
+
+		Convey("respects the ProxyMode setting", func() {
+			tcpListener := snapshot.Resources[types.Listener].Items["bede-dev-12345"].(*api.Listener)
+			httpListener := snapshot.Resources[types.Listener].Items["chretien-dev-23451"].(*api.Listener)
+
+			So(tcpListener.FilterChains[0].Filters[0].Name, ShouldEqual, wellknown.TCPProxy)
+			So(httpListener.FilterChains[0].Filters[0].Name, ShouldEqual, wellknown.HTTPConnectionManager)
+		})
+
+		Convey("generates a UDP proxy listener for UDP entries", func() {
+			udpListener := snapshot.Resources[types.Listener].Items["dampier-dev-53-udp"].(*api.Listener)
+
+			So(udpListener.FilterChains, ShouldBeEmpty)
+			So(udpListener.ListenerFilters[0].Name, ShouldEqual, UDPProxy)
+			So(udpListener.Address.GetSocketAddress().Protocol, ShouldEqual, core.SocketAddress_UDP)
+			So(snapshot.Resources[types.Cluster].Items, ShouldContainKey, "dampier-dev-53-udp")
+		})
+
+		Convey("points the endpoints at the backend", func() {
+			assignment := snapshot.Resources[types.Endpoint].Items["bede-dev-12345"].(*api.ClusterLoadAssignment)
+			addr := assignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+
+			So(addr.Address, ShouldEqual, "172.16.10.1")
+			So(addr.GetPortValue(), ShouldEqual, 80)
+		})
+
+		Convey("load balances a ServicePort across the replicas", func() {
+			listener := snapshot.Resources[types.Listener].Items["polo-dev-service-9090"].(*api.Listener)
+			So(listener.Address.GetSocketAddress().GetPortValue(), ShouldEqual, 9090)
+			So(snapshot.Resources[types.Cluster].Items, ShouldContainKey, "polo-dev-service-9090")
+
+			assignment := snapshot.Resources[types.Endpoint].Items["polo-dev-service-9090"].(*api.ClusterLoadAssignment)
+			endpoints := assignment.Endpoints[0].LbEndpoints
+			So(len(endpoints), ShouldEqual, 2)
+			So(endpoints[0].GetEndpoint().Address.GetSocketAddress().Address, ShouldEqual, "172.16.10.10")
+			So(endpoints[1].GetEndpoint().Address.GetSocketAddress().Address, ShouldEqual, "172.16.10.11")
+		})
+
+		Convey("describes the container in the endpoint metadata", func() {
+			assignment := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*api.ClusterLoadAssignment)
+			metadata := assignment.Endpoints[0].LbEndpoints[0].Metadata.FilterMetadata[MetadataNamespace]
+
+			So(metadata.Fields["container_id"].GetStringValue(), ShouldEqual, "deadbeef0002")
+			So(metadata.Fields["container_name"].GetStringValue(), ShouldEqual, "chretien-1")
+			So(metadata.Fields["image"].GetStringValue(), ShouldEqual, "nginx:1.19")
+
+			unknown := snapshot.Resources[types.Endpoint].Items["bede-dev-12345"].(*api.ClusterLoadAssignment)
+			So(unknown.Endpoints[0].LbEndpoints[0].Metadata, ShouldBeNil)
+		})
+
+		Convey("leaves out endpoints that aren't ready", func() {
+			entry := *registrar.GetEntry("chretien-dev-23451")
+			entry.Probing = true
+
+			assignment := EndpointsV2FromEntry(&entry)
+			So(assignment.ClusterName, ShouldEqual, "chretien-dev-23451")
+			So(assignment.Endpoints[0].LbEndpoints, ShouldBeEmpty)
+		})
+
+		Convey("marks draining endpoints", func() {
+			registrar.DrainTime = 1 * time.Minute
+			registrar.DrainEntry("chretien-dev-23451")
+			snapshot, err := SnapshotV2(registrar, nil, "2")
+			So(err, ShouldBeNil)
+
+			So(snapshot.Resources[types.Listener].Items, ShouldContainKey, "chretien-dev-23451")
+			assignment := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*api.ClusterLoadAssignment)
+			So(assignment.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, core.HealthStatus_DRAINING)
+		})
+
+		Convey("gates the endpoints on Docker's health", func() {
+			unknown := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*api.ClusterLoadAssignment)
+			So(unknown.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, core.HealthStatus_UNKNOWN)
+
+			registrar.SetContainerHealth("deadbeef0002", envoyhttp.DockerHealthStarting)
+			snapshot, err := SnapshotV2(registrar, nil, "2")
+			So(err, ShouldBeNil)
+
+			assignment := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*api.ClusterLoadAssignment)
+			So(assignment.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, core.HealthStatus_UNHEALTHY)
+
+			cluster := snapshot.Resources[types.Cluster].Items["chretien-dev-23451"].(*api.Cluster)
+			So(cluster.CommonLbConfig.HealthyPanicThreshold.Value, ShouldEqual, 0)
+
+			registrar.SetContainerHealth("deadbeef0002", envoyhttp.DockerHealthHealthy)
+			snapshot, _ = SnapshotV2(registrar, nil, "3")
+
+			assignment = snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*api.ClusterLoadAssignment)
+			So(assignment.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, core.HealthStatus_HEALTHY)
+		})
+
+		Convey("applies the tuning", func() {
+			defaults := snapshot.Resources[types.Cluster].Items["bede-dev-12345"].(*api.Cluster)
+			So(defaults.ConnectTimeout.AsDuration(), ShouldEqual, 500*time.Millisecond)
+			So(defaults.LbPolicy, ShouldEqual, api.Cluster_ROUND_ROBIN)
+			So(defaults.CircuitBreakers, ShouldBeNil)
+
+			cluster := snapshot.Resources[types.Cluster].Items["purchas-dev-24680"].(*api.Cluster)
+			So(cluster.ConnectTimeout.AsDuration(), ShouldEqual, 2*time.Second)
+			So(cluster.LbPolicy, ShouldEqual, api.Cluster_RING_HASH)
+			So(cluster.CircuitBreakers.Thresholds[0].MaxConnections.Value, ShouldEqual, 100)
+			So(cluster.CircuitBreakers.Thresholds[0].MaxRequests.Value, ShouldEqual, 1000)
+
+			So(defaults.HealthChecks, ShouldBeEmpty)
+			So(cluster.HealthChecks, ShouldHaveLength, 1)
+			So(cluster.HealthChecks[0].Interval.AsDuration(), ShouldEqual, 10*time.Second)
+			So(cluster.HealthChecks[0].UnhealthyThreshold.Value, ShouldEqual, 3)
+			httpCheck := cluster.HealthChecks[0].GetHttpHealthCheck()
+			So(httpCheck.Path, ShouldEqual, "/status")
+			So(httpCheck.ExpectedStatuses[0].Start, ShouldEqual, 200)
+			So(httpCheck.ExpectedStatuses[0].End, ShouldEqual, 300)
+
+			listener := snapshot.Resources[types.Listener].Items["purchas-dev-24680"].(*api.Listener)
+			manager := &hcm.HttpConnectionManager{}
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), manager), ShouldBeNil)
+			So(manager.CommonHttpProtocolOptions.IdleTimeout.AsDuration(), ShouldEqual, 90*time.Second)
+
+			action := manager.GetRouteConfig().VirtualHosts[0].Routes[0].GetRoute()
+			So(action.Timeout.AsDuration(), ShouldEqual, 15*time.Second)
+			So(action.RetryPolicy.RetryOn, ShouldEqual, "5xx")
+			So(action.RetryPolicy.NumRetries.Value, ShouldEqual, 3)
+		})
+
+		Convey("terminates TLS when asked to", func() {
+			path, _ := ioutil.TempDir("", "envoy-docker-shim-certs")
+			defer os.RemoveAll(path)
+			cert := writeCert(path, "magellan")
+			certs, _ := NewCertDir(path)
+
+			registerTLS(registrar)
+			snapshot, err := SnapshotV2(registrar, certs, "2")
+			So(err, ShouldBeNil)
+			So(snapshot.Consistent(), ShouldBeNil)
+
+			plain := snapshot.Resources[types.Listener].Items["bede-dev-12345"].(*api.Listener)
+			So(plain.FilterChains[0].TransportSocket, ShouldBeNil)
+
+			listener := snapshot.Resources[types.Listener].Items["magellan-dev-8443"].(*api.Listener)
+			socket := listener.FilterChains[0].TransportSocket
+			So(socket.Name, ShouldEqual, wellknown.TransportSocketTls)
+			tlsContext := &auth.DownstreamTlsContext{}
+			So(ptypes.UnmarshalAny(socket.GetTypedConfig(), tlsContext), ShouldBeNil)
+			sds := tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs[0]
+			So(sds.Name, ShouldEqual, "magellan")
+			So(sds.SdsConfig.GetAds(), ShouldNotBeNil)
+
+			listener = snapshot.Resources[types.Listener].Items["magellan-dev-8444"].(*api.Listener)
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].TransportSocket.GetTypedConfig(), tlsContext), ShouldBeNil)
+			sds = tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs[0]
+			So(sds.Name, ShouldEqual, "wildcard")
+			So(sds.SdsConfig, ShouldBeNil)
+
+			So(snapshot.Resources[types.Secret].Items, ShouldHaveLength, 1)
+			secret := snapshot.Resources[types.Secret].Items["magellan"].(*auth.Secret)
+			So(secret.GetTlsCertificate().CertificateChain.GetInlineBytes(), ShouldResemble, cert.Chain)
+			So(secret.GetTlsCertificate().PrivateKey.GetInlineBytes(), ShouldResemble, cert.Key)
+		})
+
+		Convey("serves the shared ingress listener", func() {
+			registrar.IngressAddr = &net.TCPAddr{IP: net.IPv6unspecified, Port: 80}
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    32100,
+				BackendAddr:     "172.16.10.13",
+				BackendPort:     80,
+				EnvironmentName: "dev",
+				ServiceName:     "cabot",
+				ProxyMode:       "http",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				Labels:          map[string]string{"VirtualHosts": "cabot.example.com"},
+			})
+			snapshot, err := SnapshotV2(registrar, nil, "2")
+			So(err, ShouldBeNil)
+			So(snapshot.Consistent(), ShouldBeNil)
+
+			listener := snapshot.Resources[types.Listener].Items["ingress"].(*api.Listener)
+			So(listener.Address.GetSocketAddress().Address, ShouldEqual, "::")
+			So(listener.Address.GetSocketAddress().PortSpecifier, ShouldResemble, &core.SocketAddress_PortValue{PortValue: 80})
+			So(listener.Address.GetSocketAddress().Ipv4Compat, ShouldBeTrue)
+
+			manager := &hcm.HttpConnectionManager{}
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), manager), ShouldBeNil)
+			hosts := manager.GetRouteConfig().VirtualHosts
+			So(hosts, ShouldHaveLength, 1)
+			So(hosts[0].Domains, ShouldResemble, []string{"cabot.example.com"})
+			So(hosts[0].Routes[0].GetRoute().GetCluster(), ShouldEqual, "cabot-dev-32100")
+		})
+
+		Convey("serves the shared TLS passthrough listener", func() {
+			snapshot, _ := SnapshotV2(registrar, nil, "2")
+			So(snapshot.Resources[types.Listener].Items, ShouldNotContainKey, "sni-passthrough")
+
+			registrar.SNIAddr = &net.TCPAddr{IP: net.IPv4zero, Port: 443}
+			snapshot, _ = SnapshotV2(registrar, nil, "3")
+			So(snapshot.Resources[types.Listener].Items, ShouldNotContainKey, "sni-passthrough") // Nothing on it
+
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    32443,
+				BackendAddr:     "172.16.10.14",
+				BackendPort:     443,
+				EnvironmentName: "dev",
+				ServiceName:     "frobisher",
+				ProxyMode:       "tcp",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				Labels:          map[string]string{"ServerNames": "frobisher.example.com"},
+			})
+			snapshot, err := SnapshotV2(registrar, nil, "4")
+			So(err, ShouldBeNil)
+			So(snapshot.Consistent(), ShouldBeNil)
+
+			listener := snapshot.Resources[types.Listener].Items["sni-passthrough"].(*api.Listener)
+			So(listener.Address.GetSocketAddress().Address, ShouldEqual, "0.0.0.0")
+			So(listener.ListenerFilters[0].Name, ShouldEqual, wellknown.TlsInspector)
+			So(listener.FilterChains, ShouldHaveLength, 1)
+			So(listener.FilterChains[0].FilterChainMatch.ServerNames, ShouldResemble, []string{"frobisher.example.com"})
+			So(listener.FilterChains[0].TransportSocket, ShouldBeNil)
+
+			proxy := &tcp.TcpProxy{}
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), proxy), ShouldBeNil)
+			So(proxy.GetCluster(), ShouldEqual, "frobisher-dev-32443")
+		})
+
+		Convey("handles IPv6 addresses", func() {
+			v6Listener := snapshot.Resources[types.Listener].Items["hakluyt-dev-8443"].(*api.Listener)
+			So(v6Listener.Address.GetSocketAddress().Address, ShouldEqual, "2001:db8::1")
+			So(v6Listener.Address.GetSocketAddress().Ipv4Compat, ShouldBeFalse)
+
+			assignment := snapshot.Resources[types.Endpoint].Items["hakluyt-dev-8443"].(*api.ClusterLoadAssignment)
+			addr := assignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+			So(addr.Address, ShouldEqual, "fd00::4")
+		})
+
+		Convey("generates one listener for both families on dual stack entries", func() {
+			listener := snapshot.Resources[types.Listener].Items["mandeville-dev-8080"].(*api.Listener)
+			So(listener.Address.GetSocketAddress().Address, ShouldEqual, "::")
+			So(listener.Address.GetSocketAddress().Ipv4Compat, ShouldBeTrue)
+			So(listener.Address.GetSocketAddress().Protocol, ShouldEqual, core.SocketAddress_TCP)
+		})
+	})
+}
+
+func Test_SnapshotV3(t *testing.T) {
+	Convey("SnapshotV3()", t, func() {
+		registrar := envoyhttp.NewRegistrar()
+		registrar.Register(context.Background(), req1)
+		registrar.Register(context.Background(), req2)
+		registrar.Register(context.Background(), udpReq)
+		registrar.Register(context.Background(), v6Req)
+		registerDualStack(registrar)
+		registrar.Register(context.Background(), tunedReq)
+		registerReplicas(registrar)
+
+		snapshot, err := SnapshotV3(registrar, nil, "1")
+		So(err, ShouldBeNil)
+
+		Convey("generates a consistent snapshot", func() {
+			So(snapshot.Consistent(), ShouldBeNil)
+			So(snapshot.GetVersion(resourcev3.ListenerType), ShouldEqual, "1")
+		})
+
+		Convey("generates a listener, cluster, and endpoint for each entry", func() {
+			So(snapshot.Resources[types.Listener].Items, ShouldContainKey, "bede-dev-12345")
+			So(snapshot.Resources[types.Listener].Items, ShouldContainKey, "chretien-dev-23451")
+			So(snapshot.Resources[types.Cluster].Items, ShouldContainKey, "bede-dev-12345")
+			So(snapshot.Resources[types.Endpoint].Items, ShouldContainKey, "chretien-dev-23451")
+		})
+
+		Convey("respects the ProxyMode setting", func() {
+			tcpListener := snapshot.Resources[types.Listener].Items["bede-dev-12345"].(*listenerv3.Listener)
+			httpListener := snapshot.Resources[types.Listener].Items["chretien-dev-23451"].(*listenerv3.Listener)
+
+			So(tcpListener.FilterChains[0].Filters[0].Name, ShouldEqual, wellknown.TCPProxy)
+			So(httpListener.FilterChains[0].Filters[0].Name, ShouldEqual, wellknown.HTTPConnectionManager)
+			So(httpListener.TrafficDirection, ShouldEqual, corev3.TrafficDirection_OUTBOUND)
+		})
+
+		Convey("generates a UDP proxy listener for UDP entries", func() {
+			udpListener := snapshot.Resources[types.Listener].Items["dampier-dev-53-udp"].(*listenerv3.Listener)
+
+			So(udpListener.FilterChains, ShouldBeEmpty)
+			So(udpListener.ListenerFilters[0].Name, ShouldEqual, UDPProxy)
+			So(udpListener.Address.GetSocketAddress().Protocol, ShouldEqual, corev3.SocketAddress_UDP)
+			So(snapshot.Resources[types.Cluster].Items, ShouldContainKey, "dampier-dev-53-udp")
+		})
+
+		Convey("points the endpoints at the backend", func() {
+			assignment := snapshot.Resources[types.Endpoint].Items["bede-dev-12345"].(*endpointv3.ClusterLoadAssignment)
+			addr := assignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+
+			So(addr.Address, ShouldEqual, "172.16.10.1")
+			So(addr.GetPortValue(), ShouldEqual, 80)
+		})
+
+		Convey("load balances a ServicePort across the replicas", func() {
+			listener := snapshot.Resources[types.Listener].Items["polo-dev-service-9090"].(*listenerv3.Listener)
+			So(listener.Address.GetSocketAddress().GetPortValue(), ShouldEqual, 9090)
+			So(snapshot.Resources[types.Cluster].Items, ShouldContainKey, "polo-dev-service-9090")
+
+			assignment := snapshot.Resources[types.Endpoint].Items["polo-dev-service-9090"].(*endpointv3.ClusterLoadAssignment)
+			endpoints := assignment.Endpoints[0].LbEndpoints
+			So(len(endpoints), ShouldEqual, 2)
+			So(endpoints[0].GetEndpoint().Address.GetSocketAddress().Address, ShouldEqual, "172.16.10.10")
+			So(endpoints[1].GetEndpoint().Address.GetSocketAddress().Address, ShouldEqual, "172.16.10.11")
+		})
+
+		Convey("describes the container in the endpoint metadata", func() {
+			assignment := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*endpointv3.ClusterLoadAssignment)
+			metadata := assignment.Endpoints[0].LbEndpoints[0].Metadata.FilterMetadata[MetadataNamespace]
+
+			So(metadata.Fields["container_id"].GetStringValue(), ShouldEqual, "deadbeef0002")
+			So(metadata.Fields["container_name"].GetStringValue(), ShouldEqual, "chretien-1")
+			So(metadata.Fields["image"].GetStringValue(), ShouldEqual, "nginx:1.19")
+
+			unknown := snapshot.Resources[types.Endpoint].Items["bede-dev-12345"].(*endpointv3.ClusterLoadAssignment)
+			So(unknown.Endpoints[0].LbEndpoints[0].Metadata, ShouldBeNil)
+		})
+
+		Convey("leaves out endpoints that aren't ready", func() {
+			entry := *registrar.GetEntry("chretien-dev-23451")
+			entry.Probing = true
+
+			assignment := EndpointsV3FromEntry(&entry)
+			So(assignment.ClusterName, ShouldEqual, "chretien-dev-23451")
+			So(assignment.Endpoints[0].LbEndpoints, ShouldBeEmpty)
+		})
+
+		Convey("marks draining endpoints", func() {
+			registrar.DrainTime = 1 * time.Minute
+			registrar.DrainEntry("chretien-dev-23451")
+			snapshot, err := SnapshotV3(registrar, nil, "2")
+			So(err, ShouldBeNil)
+
+			So(snapshot.Resources[types.Listener].Items, ShouldContainKey, "chretien-dev-23451")
+			assignment := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*endpointv3.ClusterLoadAssignment)
+			So(assignment.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, corev3.HealthStatus_DRAINING)
+		})
+
+		Convey("gates the endpoints on Docker's health", func() {
+			unknown := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*endpointv3.ClusterLoadAssignment)
+			So(unknown.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, corev3.HealthStatus_UNKNOWN)
+
+			registrar.SetContainerHealth("deadbeef0002", envoyhttp.DockerHealthStarting)
+			snapshot, err := SnapshotV3(registrar, nil, "2")
+			So(err, ShouldBeNil)
+
+			assignment := snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*endpointv3.ClusterLoadAssignment)
+			So(assignment.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, corev3.HealthStatus_UNHEALTHY)
+
+			cluster := snapshot.Resources[types.Cluster].Items["chretien-dev-23451"].(*clusterv3.Cluster)
+			So(cluster.CommonLbConfig.HealthyPanicThreshold.Value, ShouldEqual, 0)
+
+			registrar.SetContainerHealth("deadbeef0002", envoyhttp.DockerHealthHealthy)
+			snapshot, _ = SnapshotV3(registrar, nil, "3")
+
+			assignment = snapshot.Resources[types.Endpoint].Items["chretien-dev-23451"].(*endpointv3.ClusterLoadAssignment)
+			So(assignment.Endpoints[0].LbEndpoints[0].HealthStatus, ShouldEqual, corev3.HealthStatus_HEALTHY)
+		})
+
+		Convey("applies the tuning", func() {
+			defaults := snapshot.Resources[types.Cluster].Items["bede-dev-12345"].(*clusterv3.Cluster)
+			So(defaults.ConnectTimeout.AsDuration(), ShouldEqual, 500*time.Millisecond)
+			So(defaults.LbPolicy, ShouldEqual, clusterv3.Cluster_ROUND_ROBIN)
+			So(defaults.CircuitBreakers, ShouldBeNil)
+
+			cluster := snapshot.Resources[types.Cluster].Items["purchas-dev-24680"].(*clusterv3.Cluster)
+			So(cluster.ConnectTimeout.AsDuration(), ShouldEqual, 2*time.Second)
+			So(cluster.LbPolicy, ShouldEqual, clusterv3.Cluster_RING_HASH)
+			So(cluster.CircuitBreakers.Thresholds[0].MaxConnections.Value, ShouldEqual, 100)
+			So(cluster.CircuitBreakers.Thresholds[0].MaxRequests.Value, ShouldEqual, 1000)
+
+			So(defaults.HealthChecks, ShouldBeEmpty)
+			So(cluster.HealthChecks, ShouldHaveLength, 1)
+			So(cluster.HealthChecks[0].Interval.AsDuration(), ShouldEqual, 10*time.Second)
+			So(cluster.HealthChecks[0].UnhealthyThreshold.Value, ShouldEqual, 3)
+			httpCheck := cluster.HealthChecks[0].GetHttpHealthCheck()
+			So(httpCheck.Path, ShouldEqual, "/status")
+			So(httpCheck.ExpectedStatuses[0].Start, ShouldEqual, 200)
+			So(httpCheck.ExpectedStatuses[0].End, ShouldEqual, 300)
+
+			listener := snapshot.Resources[types.Listener].Items["purchas-dev-24680"].(*listenerv3.Listener)
+			manager := &hcmv3.HttpConnectionManager{}
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), manager), ShouldBeNil)
+			So(manager.CommonHttpProtocolOptions.IdleTimeout.AsDuration(), ShouldEqual, 90*time.Second)
+
+			action := manager.GetRouteConfig().VirtualHosts[0].Routes[0].GetRoute()
+			So(action.Timeout.AsDuration(), ShouldEqual, 15*time.Second)
+			So(action.RetryPolicy.RetryOn, ShouldEqual, "5xx")
+			So(action.RetryPolicy.NumRetries.Value, ShouldEqual, 3)
+		})
+
+		Convey("terminates TLS when asked to", func() {
+			path, _ := ioutil.TempDir("", "envoy-docker-shim-certs")
+			defer os.RemoveAll(path)
+			cert := writeCert(path, "magellan")
+			certs, _ := NewCertDir(path)
+
+			registerTLS(registrar)
+			snapshot, err := SnapshotV3(registrar, certs, "2")
+			So(err, ShouldBeNil)
+			So(snapshot.Consistent(), ShouldBeNil)
+
+			plain := snapshot.Resources[types.Listener].Items["bede-dev-12345"].(*listenerv3.Listener)
+			So(plain.FilterChains[0].TransportSocket, ShouldBeNil)
+
+			listener := snapshot.Resources[types.Listener].Items["magellan-dev-8443"].(*listenerv3.Listener)
+			socket := listener.FilterChains[0].TransportSocket
+			So(socket.Name, ShouldEqual, wellknown.TransportSocketTls)
+			tlsContext := &tlsv3.DownstreamTlsContext{}
+			So(ptypes.UnmarshalAny(socket.GetTypedConfig(), tlsContext), ShouldBeNil)
+			sds := tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs[0]
+			So(sds.Name, ShouldEqual, "magellan")
+			So(sds.SdsConfig.GetAds(), ShouldNotBeNil)
+
+			listener = snapshot.Resources[types.Listener].Items["magellan-dev-8444"].(*listenerv3.Listener)
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].TransportSocket.GetTypedConfig(), tlsContext), ShouldBeNil)
+			sds = tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs[0]
+			So(sds.Name, ShouldEqual, "wildcard")
+			So(sds.SdsConfig, ShouldBeNil)
+
+			So(snapshot.Resources[types.Secret].Items, ShouldHaveLength, 1)
+			secret := snapshot.Resources[types.Secret].Items["magellan"].(*tlsv3.Secret)
+			So(secret.GetTlsCertificate().CertificateChain.GetInlineBytes(), ShouldResemble, cert.Chain)
+			So(secret.GetTlsCertificate().PrivateKey.GetInlineBytes(), ShouldResemble, cert.Key)
+		})
+
+		Convey("serves the shared ingress listener", func() {
+			registrar.IngressAddr = &net.TCPAddr{IP: net.IPv6unspecified, Port: 80}
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    32100,
+				BackendAddr:     "172.16.10.13",
+				BackendPort:     80,
+				EnvironmentName: "dev",
+				ServiceName:     "cabot",
+				ProxyMode:       "http",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				Labels:          map[string]string{"VirtualHosts": "cabot.example.com"},
+			})
+			snapshot, err := SnapshotV3(registrar, nil, "2")
+			So(err, ShouldBeNil)
+			So(snapshot.Consistent(), ShouldBeNil)
+
+			listener := snapshot.Resources[types.Listener].Items["ingress"].(*listenerv3.Listener)
+			So(listener.Address.GetSocketAddress().Address, ShouldEqual, "::")
+			So(listener.Address.GetSocketAddress().PortSpecifier, ShouldResemble, &corev3.SocketAddress_PortValue{PortValue: 80})
+			So(listener.Address.GetSocketAddress().Ipv4Compat, ShouldBeTrue)
+
+			manager := &hcmv3.HttpConnectionManager{}
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), manager), ShouldBeNil)
+			hosts := manager.GetRouteConfig().VirtualHosts
+			So(hosts, ShouldHaveLength, 1)
+			So(hosts[0].Domains, ShouldResemble, []string{"cabot.example.com"})
+			So(hosts[0].Routes[0].GetRoute().GetCluster(), ShouldEqual, "cabot-dev-32100")
+		})
+
+		Convey("serves the shared TLS passthrough listener", func() {
+			snapshot, _ := SnapshotV3(registrar, nil, "2")
+			So(snapshot.Resources[types.Listener].Items, ShouldNotContainKey, "sni-passthrough")
+
+			registrar.SNIAddr = &net.TCPAddr{IP: net.IPv4zero, Port: 443}
+			snapshot, _ = SnapshotV3(registrar, nil, "3")
+			So(snapshot.Resources[types.Listener].Items, ShouldNotContainKey, "sni-passthrough") // Nothing on it
+
+			registrar.Register(context.Background(), &shimrpc.RegistrarRequest{
+				FrontendAddr:    "0.0.0.0",
+				FrontendPort:    32443,
+				BackendAddr:     "172.16.10.14",
+				BackendPort:     443,
+				EnvironmentName: "dev",
+				ServiceName:     "frobisher",
+				ProxyMode:       "tcp",
+				Action:          shimrpc.RegistrarRequest_REGISTER,
+				Labels:          map[string]string{"ServerNames": "frobisher.example.com"},
+			})
+			snapshot, err := SnapshotV3(registrar, nil, "4")
+			So(err, ShouldBeNil)
+			So(snapshot.Consistent(), ShouldBeNil)
+
+			listener := snapshot.Resources[types.Listener].Items["sni-passthrough"].(*listenerv3.Listener)
+			So(listener.Address.GetSocketAddress().Address, ShouldEqual, "0.0.0.0")
+			So(listener.ListenerFilters[0].Name, ShouldEqual, wellknown.TlsInspector)
+			So(listener.FilterChains, ShouldHaveLength, 1)
+			So(listener.FilterChains[0].FilterChainMatch.ServerNames, ShouldResemble, []string{"frobisher.example.com"})
+			So(listener.FilterChains[0].TransportSocket, ShouldBeNil)
+
+			proxy := &tcpv3.TcpProxy{}
+			So(ptypes.UnmarshalAny(listener.FilterChains[0].Filters[0].GetTypedConfig(), proxy), ShouldBeNil)
+			So(proxy.GetCluster(), ShouldEqual, "frobisher-dev-32443")
+		})
+
+		Convey("handles IPv6 addresses", func() {
+			v6Listener := snapshot.Resources[types.Listener].Items["hakluyt-dev-8443"].(*listenerv3.Listener)
+			So(v6Listener.Address.GetSocketAddress().Address, ShouldEqual, "2001:db8::1")
+			So(v6Listener.Address.GetSocketAddress().Ipv4Compat, ShouldBeFalse)
+
+			assignment := snapshot.Resources[types.Endpoint].Items["hakluyt-dev-8443"].(*endpointv3.ClusterLoadAssignment)
+			addr := assignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+			So(addr.Address, ShouldEqual, "fd00::4")
+		})
+
+		Convey("generates one listener for both families on dual stack entries", func() {
+			listener := snapshot.Resources[types.Listener].Items["mandeville-dev-8080"].(*listenerv3.Listener)
+			So(listener.Address.GetSocketAddress().Address, ShouldEqual, "::")
+			So(listener.Address.GetSocketAddress().Ipv4Compat, ShouldBeTrue)
+			So(listener.Address.GetSocketAddress().Protocol, ShouldEqual, corev3.SocketAddress_TCP)
+		})
+	})
+}
+
+func Test_Run(t *testing.T) {
+	Convey("Run()", t, func() {
+		registrar := envoyhttp.NewRegistrar()
+		server := NewXdsServer(registrar)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go server.Run(ctx)
+
+		Reset(func() {
+			cancel()
+		})
+
+		Convey("pushes a new snapshot when the Registrar changes", func() {
+			registrar.Register(context.Background(), req1)
+
+			updated := eventually(func() bool {
+				snapshot, err := server.cacheV2.GetSnapshot(AllNodes)
+				return err == nil && len(snapshot.Resources[types.Listener].Items) == 1
+			})
+
+			updatedV3 := eventually(func() bool {
+				snapshot, err := server.cacheV3.GetSnapshot(AllNodes)
+				return err == nil && len(snapshot.Resources[types.Listener].Items) == 1
+			})
+
+			So(updated, ShouldBeTrue)
+			So(updatedV3, ShouldBeTrue)
+		})
+	})
+}
+
+// eventually polls a condition for a short while, returning whether it became true
+func eventually(fn func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if fn() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
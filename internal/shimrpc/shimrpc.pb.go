@@ -1,16 +1,19 @@
-// Code generated by protoc-gen-go.
+// Code generated by protoc-gen-go. DO NOT EDIT.
 // source: shimrpc.proto
-// DO NOT EDIT!
 
 /*
 Package shimrpc is a generated protocol buffer package.
 
 It is generated from these files:
+
 	shimrpc.proto
 
 It has these top-level messages:
+
 	RegistrarRequest
 	RegistrarReply
+	LeaseReply
+	UDPStatsRequest
 */
 package shimrpc
 
@@ -65,6 +68,17 @@ type RegistrarRequest struct {
 	EnvironmentName string                  `protobuf:"bytes,6,opt,name=environment_name,json=environmentName" json:"environment_name,omitempty"`
 	ServiceName     string                  `protobuf:"bytes,7,opt,name=service_name,json=serviceName" json:"service_name,omitempty"`
 	ProxyMode       string                  `protobuf:"bytes,8,opt,name=proxy_mode,json=proxyMode" json:"proxy_mode,omitempty"`
+	Protocol        string                  `protobuf:"bytes,9,opt,name=protocol" json:"protocol,omitempty"`
+	// The container's Envoy* labels, validated by the server
+	Tuning map[string]string `protobuf:"bytes,10,rep,name=tuning" json:"tuning,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The container that owns the backend address, as Docker reports it
+	ContainerId   string            `protobuf:"bytes,11,opt,name=container_id,json=containerId" json:"container_id,omitempty"`
+	ContainerName string            `protobuf:"bytes,12,opt,name=container_name,json=containerName" json:"container_name,omitempty"`
+	Image         string            `protobuf:"bytes,13,opt,name=image" json:"image,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,14,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Share a listener on this port, and a cluster, with the other replicas
+	// of the service. Zero means don't.
+	ServicePort int32 `protobuf:"varint,15,opt,name=service_port,json=servicePort" json:"service_port,omitempty"`
 }
 
 func (m *RegistrarRequest) Reset()                    { *m = RegistrarRequest{} }
@@ -128,6 +142,55 @@ func (m *RegistrarRequest) GetProxyMode() string {
 	return ""
 }
 
+func (m *RegistrarRequest) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *RegistrarRequest) GetTuning() map[string]string {
+	if m != nil {
+		return m.Tuning
+	}
+	return nil
+}
+
+func (m *RegistrarRequest) GetContainerId() string {
+	if m != nil {
+		return m.ContainerId
+	}
+	return ""
+}
+
+func (m *RegistrarRequest) GetContainerName() string {
+	if m != nil {
+		return m.ContainerName
+	}
+	return ""
+}
+
+func (m *RegistrarRequest) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *RegistrarRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *RegistrarRequest) GetServicePort() int32 {
+	if m != nil {
+		return m.ServicePort
+	}
+	return 0
+}
+
 // The response message containing the status
 type RegistrarReply struct {
 	StatusCode int32 `protobuf:"varint,1,opt,name=status_code,json=statusCode" json:"status_code,omitempty"`
@@ -145,9 +208,141 @@ func (m *RegistrarReply) GetStatusCode() int32 {
 	return 0
 }
 
+// The response to each request on a lease stream
+type LeaseReply struct {
+	StatusCode int32 `protobuf:"varint,1,opt,name=status_code,json=statusCode" json:"status_code,omitempty"`
+	TtlSeconds int32 `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds" json:"ttl_seconds,omitempty"`
+}
+
+func (m *LeaseReply) Reset()                    { *m = LeaseReply{} }
+func (m *LeaseReply) String() string            { return proto.CompactTextString(m) }
+func (*LeaseReply) ProtoMessage()               {}
+func (*LeaseReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *LeaseReply) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *LeaseReply) GetTtlSeconds() int32 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+// The counters from an in-process UDP proxy, since it started
+type UDPStatsRequest struct {
+	Proxy              *RegistrarRequest `protobuf:"bytes,1,opt,name=proxy" json:"proxy,omitempty"`
+	DatagramsIn        uint64            `protobuf:"varint,2,opt,name=datagrams_in,json=datagramsIn" json:"datagrams_in,omitempty"`
+	BytesIn            uint64            `protobuf:"varint,3,opt,name=bytes_in,json=bytesIn" json:"bytes_in,omitempty"`
+	DatagramsOut       uint64            `protobuf:"varint,4,opt,name=datagrams_out,json=datagramsOut" json:"datagrams_out,omitempty"`
+	BytesOut           uint64            `protobuf:"varint,5,opt,name=bytes_out,json=bytesOut" json:"bytes_out,omitempty"`
+	ConntrackEntries   int64             `protobuf:"varint,6,opt,name=conntrack_entries,json=conntrackEntries" json:"conntrack_entries,omitempty"`
+	ConntrackExpiries  uint64            `protobuf:"varint,7,opt,name=conntrack_expiries,json=conntrackExpiries" json:"conntrack_expiries,omitempty"`
+	DialFailures       uint64            `protobuf:"varint,8,opt,name=dial_failures,json=dialFailures" json:"dial_failures,omitempty"`
+	WriteFailures      uint64            `protobuf:"varint,9,opt,name=write_failures,json=writeFailures" json:"write_failures,omitempty"`
+	Closed             bool              `protobuf:"varint,10,opt,name=closed" json:"closed,omitempty"`
+	ConntrackEvictions uint64            `protobuf:"varint,11,opt,name=conntrack_evictions,json=conntrackEvictions" json:"conntrack_evictions,omitempty"`
+	RateLimited        uint64            `protobuf:"varint,12,opt,name=rate_limited,json=rateLimited" json:"rate_limited,omitempty"`
+}
+
+func (m *UDPStatsRequest) Reset()                    { *m = UDPStatsRequest{} }
+func (m *UDPStatsRequest) String() string            { return proto.CompactTextString(m) }
+func (*UDPStatsRequest) ProtoMessage()               {}
+func (*UDPStatsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *UDPStatsRequest) GetProxy() *RegistrarRequest {
+	if m != nil {
+		return m.Proxy
+	}
+	return nil
+}
+
+func (m *UDPStatsRequest) GetDatagramsIn() uint64 {
+	if m != nil {
+		return m.DatagramsIn
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetBytesIn() uint64 {
+	if m != nil {
+		return m.BytesIn
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetDatagramsOut() uint64 {
+	if m != nil {
+		return m.DatagramsOut
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetBytesOut() uint64 {
+	if m != nil {
+		return m.BytesOut
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetConntrackEntries() int64 {
+	if m != nil {
+		return m.ConntrackEntries
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetConntrackExpiries() uint64 {
+	if m != nil {
+		return m.ConntrackExpiries
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetDialFailures() uint64 {
+	if m != nil {
+		return m.DialFailures
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetWriteFailures() uint64 {
+	if m != nil {
+		return m.WriteFailures
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetClosed() bool {
+	if m != nil {
+		return m.Closed
+	}
+	return false
+}
+
+func (m *UDPStatsRequest) GetConntrackEvictions() uint64 {
+	if m != nil {
+		return m.ConntrackEvictions
+	}
+	return 0
+}
+
+func (m *UDPStatsRequest) GetRateLimited() uint64 {
+	if m != nil {
+		return m.RateLimited
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*RegistrarRequest)(nil), "shimrpc.RegistrarRequest")
 	proto.RegisterType((*RegistrarReply)(nil), "shimrpc.RegistrarReply")
+	proto.RegisterType((*LeaseReply)(nil), "shimrpc.LeaseReply")
+	proto.RegisterType((*UDPStatsRequest)(nil), "shimrpc.UDPStatsRequest")
 	proto.RegisterEnum("shimrpc.RegistrarRequest_Action", RegistrarRequest_Action_name, RegistrarRequest_Action_value)
 }
 
@@ -163,6 +358,13 @@ const _ = grpc.SupportPackageIsVersion4
 
 type RegistrarClient interface {
 	Register(ctx context.Context, in *RegistrarRequest, opts ...grpc.CallOption) (*RegistrarReply, error)
+	// Lease registers the entry described by the first request and holds it
+	// for as long as the stream stays up. Each further request on the stream
+	// is a heartbeat that renews the lease.
+	Lease(ctx context.Context, opts ...grpc.CallOption) (Registrar_LeaseClient, error)
+	// ReportUDPStats takes the counters from a shim that is proxying UDP
+	// in-process, so the server can export them.
+	ReportUDPStats(ctx context.Context, in *UDPStatsRequest, opts ...grpc.CallOption) (*RegistrarReply, error)
 }
 
 type registrarClient struct {
@@ -182,10 +384,57 @@ func (c *registrarClient) Register(ctx context.Context, in *RegistrarRequest, op
 	return out, nil
 }
 
+func (c *registrarClient) Lease(ctx context.Context, opts ...grpc.CallOption) (Registrar_LeaseClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Registrar_serviceDesc.Streams[0], c.cc, "/shimrpc.Registrar/Lease", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &registrarLeaseClient{stream}
+	return x, nil
+}
+
+type Registrar_LeaseClient interface {
+	Send(*RegistrarRequest) error
+	Recv() (*LeaseReply, error)
+	grpc.ClientStream
+}
+
+type registrarLeaseClient struct {
+	grpc.ClientStream
+}
+
+func (x *registrarLeaseClient) Send(m *RegistrarRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *registrarLeaseClient) Recv() (*LeaseReply, error) {
+	m := new(LeaseReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *registrarClient) ReportUDPStats(ctx context.Context, in *UDPStatsRequest, opts ...grpc.CallOption) (*RegistrarReply, error) {
+	out := new(RegistrarReply)
+	err := grpc.Invoke(ctx, "/shimrpc.Registrar/ReportUDPStats", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Registrar service
 
 type RegistrarServer interface {
 	Register(context.Context, *RegistrarRequest) (*RegistrarReply, error)
+	// Lease registers the entry described by the first request and holds it
+	// for as long as the stream stays up. Each further request on the stream
+	// is a heartbeat that renews the lease.
+	Lease(Registrar_LeaseServer) error
+	// ReportUDPStats takes the counters from a shim that is proxying UDP
+	// in-process, so the server can export them.
+	ReportUDPStats(context.Context, *UDPStatsRequest) (*RegistrarReply, error)
 }
 
 func RegisterRegistrarServer(s *grpc.Server, srv RegistrarServer) {
@@ -210,6 +459,50 @@ func _Registrar_Register_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Registrar_Lease_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RegistrarServer).Lease(&registrarLeaseServer{stream})
+}
+
+type Registrar_LeaseServer interface {
+	Send(*LeaseReply) error
+	Recv() (*RegistrarRequest, error)
+	grpc.ServerStream
+}
+
+type registrarLeaseServer struct {
+	grpc.ServerStream
+}
+
+func (x *registrarLeaseServer) Send(m *LeaseReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *registrarLeaseServer) Recv() (*RegistrarRequest, error) {
+	m := new(RegistrarRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Registrar_ReportUDPStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UDPStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistrarServer).ReportUDPStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/shimrpc.Registrar/ReportUDPStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistrarServer).ReportUDPStats(ctx, req.(*UDPStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Registrar_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "shimrpc.Registrar",
 	HandlerType: (*RegistrarServer)(nil),
@@ -218,36 +511,73 @@ var _Registrar_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Register",
 			Handler:    _Registrar_Register_Handler,
 		},
+		{
+			MethodName: "ReportUDPStats",
+			Handler:    _Registrar_ReportUDPStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Lease",
+			Handler:       _Registrar_Lease_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "shimrpc.proto",
 }
 
 func init() { proto.RegisterFile("shimrpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 355 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x92, 0x41, 0x4f, 0xe2, 0x40,
-	0x14, 0xc7, 0x29, 0xbb, 0x14, 0x78, 0x14, 0x96, 0xcc, 0x65, 0xcb, 0x26, 0x9b, 0xed, 0x76, 0x93,
-	0x0d, 0xc6, 0xa4, 0x89, 0x78, 0xf1, 0x28, 0x28, 0x31, 0x1e, 0x30, 0xa4, 0x78, 0x6f, 0x4a, 0xe7,
-	0x09, 0x8d, 0x74, 0x5e, 0x9d, 0x0e, 0xc4, 0x7e, 0x6c, 0xbf, 0x81, 0x61, 0xda, 0x22, 0x1a, 0x3d,
-	0xbe, 0x7f, 0x7f, 0xfd, 0xf5, 0xdf, 0x97, 0x07, 0xdd, 0x6c, 0x1d, 0x27, 0x32, 0x8d, 0xbc, 0x54,
-	0x92, 0x22, 0xd6, 0x2c, 0x47, 0xf7, 0xa5, 0x0e, 0x7d, 0x1f, 0x57, 0x71, 0xa6, 0x64, 0x28, 0x7d,
-	0x7c, 0xda, 0x62, 0xa6, 0xd8, 0x3f, 0xe8, 0x3e, 0x48, 0x12, 0x0a, 0x05, 0x0f, 0x42, 0xce, 0xa5,
-	0x6d, 0x38, 0xc6, 0xb0, 0xed, 0x5b, 0x55, 0x38, 0xe6, 0x5c, 0xbe, 0x83, 0x52, 0x92, 0xca, 0xae,
-	0x3b, 0xc6, 0xb0, 0xf1, 0x06, 0xcd, 0x49, 0x2a, 0xf6, 0x17, 0xac, 0x65, 0x18, 0x3d, 0x1e, 0x44,
-	0xdf, 0xb4, 0xa8, 0x53, 0x66, 0xda, 0x73, 0x84, 0x68, 0xcd, 0x77, 0xad, 0xa9, 0x10, 0x6d, 0xb9,
-	0x00, 0x33, 0x8c, 0x54, 0x4c, 0xc2, 0x6e, 0x38, 0xc6, 0xb0, 0x37, 0x72, 0xbc, 0xea, 0x6f, 0x3e,
-	0x56, 0xf7, 0xc6, 0x9a, 0xf3, 0x4b, 0x9e, 0x9d, 0x40, 0x1f, 0xc5, 0x2e, 0x96, 0x24, 0x12, 0x14,
-	0x2a, 0x10, 0x61, 0x82, 0xb6, 0xa9, 0x3b, 0xfc, 0x38, 0xca, 0xef, 0xc2, 0x04, 0xf7, 0x3d, 0x32,
-	0x94, 0xbb, 0x38, 0xc2, 0x02, 0x6b, 0x16, 0x55, 0xcb, 0x4c, 0x23, 0xbf, 0x01, 0x52, 0x49, 0xcf,
-	0x79, 0x90, 0x10, 0x47, 0xbb, 0xa5, 0x81, 0xb6, 0x4e, 0x66, 0xc4, 0xd1, 0xfd, 0x0f, 0x66, 0xf1,
-	0x79, 0x66, 0x41, 0xcb, 0x9f, 0xde, 0xdc, 0x2e, 0xee, 0xa7, 0x7e, 0xbf, 0xc6, 0x7a, 0x00, 0xd7,
-	0xd3, 0xc3, 0x6c, 0xb8, 0x67, 0xd0, 0x3b, 0xea, 0x9d, 0x6e, 0x72, 0xf6, 0x07, 0x3a, 0x99, 0x0a,
-	0xd5, 0x36, 0x0b, 0xa2, 0xbd, 0xd9, 0xd0, 0x2b, 0x80, 0x22, 0xba, 0x22, 0x8e, 0xa3, 0x19, 0xb4,
-	0x0f, 0xaf, 0xb0, 0x4b, 0x68, 0x15, 0x03, 0x4a, 0x36, 0xf8, 0x72, 0x15, 0xbf, 0x7e, 0x7e, 0xf6,
-	0x28, 0xdd, 0xe4, 0x6e, 0x6d, 0x72, 0x0a, 0x83, 0x88, 0x12, 0x6f, 0x45, 0x22, 0x56, 0x92, 0x3c,
-	0x14, 0x3b, 0xca, 0x2b, 0x7a, 0x62, 0x2d, 0xd6, 0x71, 0xe2, 0xa7, 0xd1, 0x7c, 0x7f, 0x29, 0x73,
-	0x63, 0x69, 0xea, 0x93, 0x39, 0x7f, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x60, 0xe9, 0x2c, 0x5c, 0x43,
-	0x02, 0x00, 0x00,
+	// 773 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x94, 0xdf, 0x8f, 0xdb, 0x44,
+	0x10, 0xc7, 0xcf, 0xcd, 0x8f, 0x4b, 0xc6, 0x49, 0x2e, 0x6c, 0x11, 0xf8, 0x0e, 0x21, 0x42, 0x50,
+	0x51, 0x50, 0x45, 0x0a, 0xc7, 0x4b, 0x41, 0xaa, 0x44, 0x4b, 0x0d, 0x3a, 0xe9, 0x28, 0xd1, 0xa6,
+	0x3c, 0x5b, 0x1b, 0x7b, 0x9b, 0xae, 0xce, 0xde, 0x35, 0xbb, 0x9b, 0xd0, 0xbc, 0xf3, 0x1f, 0x22,
+	0xf1, 0xf7, 0xa0, 0x9d, 0x75, 0x6c, 0x53, 0x71, 0x07, 0x7d, 0xcb, 0x7c, 0xe7, 0xb3, 0x93, 0xef,
+	0xee, 0xcc, 0x18, 0xc6, 0xe6, 0xb5, 0x28, 0x74, 0x99, 0x2e, 0x4b, 0xad, 0xac, 0x22, 0xa7, 0x55,
+	0x38, 0xff, 0xa3, 0x0f, 0x53, 0xca, 0xb7, 0xc2, 0x58, 0xcd, 0x34, 0xe5, 0xbf, 0xed, 0xb8, 0xb1,
+	0xe4, 0x33, 0x18, 0xbf, 0xd2, 0x4a, 0x5a, 0x2e, 0xb3, 0x84, 0x65, 0x99, 0x8e, 0x82, 0x59, 0xb0,
+	0x18, 0xd2, 0xd1, 0x51, 0x7c, 0x9a, 0x65, 0xfa, 0x1f, 0x50, 0xa9, 0xb4, 0x8d, 0xee, 0xcd, 0x82,
+	0x45, 0xaf, 0x81, 0x56, 0x4a, 0x5b, 0xf2, 0x29, 0x8c, 0x36, 0x2c, 0xbd, 0xa9, 0x0b, 0x75, 0xb0,
+	0x50, 0x58, 0x69, 0x58, 0xa7, 0x85, 0x60, 0x99, 0x2e, 0x96, 0x39, 0x22, 0x58, 0xe5, 0x31, 0xf4,
+	0x59, 0x6a, 0x85, 0x92, 0x51, 0x6f, 0x16, 0x2c, 0x26, 0x97, 0xb3, 0xe5, 0xf1, 0x36, 0x6f, 0x5b,
+	0x5f, 0x3e, 0x45, 0x8e, 0x56, 0x3c, 0xf9, 0x02, 0xa6, 0x5c, 0xee, 0x85, 0x56, 0xb2, 0xe0, 0xd2,
+	0x26, 0x92, 0x15, 0x3c, 0xea, 0xa3, 0x87, 0xb3, 0x96, 0xfe, 0x82, 0x15, 0xdc, 0xf9, 0x30, 0x5c,
+	0xef, 0x45, 0xca, 0x3d, 0x76, 0xea, 0xad, 0x56, 0x1a, 0x22, 0x1f, 0x03, 0x94, 0x5a, 0xbd, 0x39,
+	0x24, 0x85, 0xca, 0x78, 0x34, 0x40, 0x60, 0x88, 0xca, 0xcf, 0x2a, 0xe3, 0xe4, 0x02, 0x06, 0xf8,
+	0xba, 0xa9, 0xca, 0xa3, 0x21, 0x26, 0xeb, 0x98, 0x3c, 0x81, 0xbe, 0xdd, 0x49, 0x21, 0xb7, 0x11,
+	0xcc, 0x3a, 0x8b, 0xf0, 0xf2, 0xc1, 0xed, 0x57, 0x78, 0x89, 0x5c, 0x2c, 0xad, 0x3e, 0xd0, 0xea,
+	0x90, 0x33, 0x97, 0x2a, 0x69, 0x99, 0x90, 0x5c, 0x27, 0x22, 0x8b, 0x42, 0x6f, 0xae, 0xd6, 0xae,
+	0x32, 0xf2, 0x00, 0x26, 0x0d, 0x82, 0x37, 0x18, 0x21, 0x34, 0xae, 0x55, 0xbc, 0xc3, 0xfb, 0xd0,
+	0x13, 0x05, 0xdb, 0xf2, 0x68, 0x8c, 0x59, 0x1f, 0x38, 0x7b, 0x39, 0xdb, 0xf0, 0xdc, 0x44, 0x93,
+	0xff, 0xb2, 0x77, 0x8d, 0x5c, 0x65, 0xcf, 0x1f, 0x6a, 0xbf, 0x1d, 0xf6, 0xf0, 0xcc, 0xf7, 0xb0,
+	0xd2, 0x5c, 0x0f, 0x2f, 0xbe, 0x85, 0xb0, 0x75, 0x31, 0x32, 0x85, 0xce, 0x0d, 0x3f, 0x54, 0x83,
+	0xe5, 0x7e, 0x3a, 0x63, 0x7b, 0x96, 0xef, 0x38, 0xce, 0xd1, 0x90, 0xfa, 0xe0, 0xbb, 0x7b, 0x8f,
+	0x03, 0x77, 0xb4, 0xf5, 0xa7, 0xef, 0x72, 0x74, 0xfe, 0x39, 0xf4, 0xfd, 0x44, 0x90, 0x11, 0x0c,
+	0x68, 0xfc, 0xd3, 0xd5, 0xfa, 0x65, 0x4c, 0xa7, 0x27, 0x64, 0x02, 0xf0, 0x3c, 0xae, 0xe3, 0x60,
+	0xfe, 0x35, 0x4c, 0x5a, 0x17, 0x2d, 0xf3, 0x03, 0xf9, 0x04, 0x42, 0x63, 0x99, 0xdd, 0x99, 0x24,
+	0x75, 0xcd, 0x0e, 0xf0, 0x46, 0xe0, 0xa5, 0x1f, 0x54, 0xc6, 0xe7, 0x2f, 0x00, 0xae, 0x39, 0x33,
+	0xfc, 0xff, 0xe1, 0x0e, 0xb0, 0x36, 0x4f, 0x0c, 0x4f, 0x95, 0xcc, 0x4c, 0xb5, 0x2c, 0x60, 0x6d,
+	0xbe, 0xf6, 0xca, 0xfc, 0xaf, 0x0e, 0x9c, 0xfd, 0xfa, 0x7c, 0xb5, 0xb6, 0xcc, 0x9a, 0xe3, 0x22,
+	0x3e, 0x82, 0x1e, 0x8e, 0x17, 0xd6, 0x0b, 0x2f, 0xcf, 0x6f, 0xed, 0x0a, 0xf5, 0x9c, 0x6b, 0x44,
+	0xc6, 0x2c, 0xdb, 0x6a, 0x56, 0x98, 0x44, 0x48, 0xfc, 0x9b, 0x2e, 0x0d, 0x6b, 0xed, 0x4a, 0x92,
+	0x73, 0x18, 0x6c, 0x0e, 0x96, 0x63, 0xba, 0x83, 0xe9, 0x53, 0x8c, 0xaf, 0xa4, 0x5b, 0xe9, 0xe6,
+	0xb4, 0xda, 0xf9, 0x5d, 0xec, 0xd2, 0xa6, 0xe4, 0x2f, 0x3b, 0x4b, 0x3e, 0x82, 0xa1, 0x3f, 0xef,
+	0x80, 0x1e, 0x02, 0xbe, 0xa0, 0x4b, 0x3e, 0x84, 0xf7, 0x52, 0x25, 0xa5, 0xd5, 0x2c, 0xbd, 0x49,
+	0xb8, 0xb4, 0x5a, 0x70, 0x83, 0x0b, 0xd7, 0xa1, 0xd3, 0x3a, 0x11, 0x7b, 0x9d, 0x7c, 0x09, 0xa4,
+	0x05, 0xbf, 0x29, 0x05, 0xd2, 0xa7, 0x58, 0xb2, 0x29, 0x13, 0x57, 0x09, 0x74, 0x27, 0x58, 0x9e,
+	0xbc, 0x62, 0x22, 0xdf, 0x69, 0x6e, 0xa2, 0x41, 0xe5, 0x4e, 0xb0, 0xfc, 0xc7, 0x4a, 0x73, 0x5b,
+	0xf0, 0xbb, 0x16, 0x96, 0x37, 0xd4, 0x10, 0xa9, 0x31, 0xaa, 0x35, 0xf6, 0x01, 0xf4, 0xd3, 0x5c,
+	0x19, 0x9e, 0x45, 0x30, 0x0b, 0x16, 0x03, 0x5a, 0x45, 0xe4, 0x11, 0xdc, 0x6f, 0x59, 0xda, 0x0b,
+	0x9c, 0x1d, 0x83, 0xeb, 0xd6, 0xa5, 0x8d, 0xdb, 0xf8, 0x98, 0x71, 0x0f, 0xae, 0x99, 0xe5, 0x49,
+	0x2e, 0x0a, 0x61, 0x79, 0x86, 0x3b, 0xd7, 0xa5, 0xa1, 0xd3, 0xae, 0xbd, 0x74, 0xf9, 0x67, 0x00,
+	0xc3, 0xba, 0x5f, 0xe4, 0x7b, 0x18, 0xf8, 0x80, 0x6b, 0x72, 0x7b, 0x3f, 0x2f, 0x3e, 0xfc, 0xb7,
+	0x54, 0x99, 0x1f, 0xe6, 0x27, 0xe4, 0x09, 0xf4, 0x70, 0xf0, 0xee, 0x3a, 0x7e, 0xbf, 0x4e, 0x35,
+	0x33, 0x3a, 0x3f, 0x59, 0x04, 0x5f, 0x05, 0x24, 0x76, 0xa3, 0xee, 0xb6, 0xf4, 0x38, 0x6c, 0x24,
+	0xaa, 0xe1, 0xb7, 0xe6, 0xef, 0x0e, 0x17, 0xcf, 0x1e, 0xc2, 0x79, 0xaa, 0x8a, 0xe5, 0x56, 0x49,
+	0x61, 0xb5, 0x5a, 0x72, 0xb9, 0x57, 0x87, 0x23, 0xfd, 0x6c, 0xb4, 0x7e, 0x2d, 0x0a, 0x5a, 0xa6,
+	0x2b, 0xf7, 0xf9, 0x5b, 0x05, 0x9b, 0x3e, 0x7e, 0x07, 0xbf, 0xf9, 0x7b, 0x00, 0xa4, 0x6a, 0x99,
+	0x33, 0x86, 0x06, 0x00, 0x00,
 }